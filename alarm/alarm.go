@@ -0,0 +1,358 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+// Package alarm watches the channels of a stream of analog input frames
+// against high, low, and rate-of-change limits, raising an alarm when a
+// channel crosses a limit and clearing it once the channel is back inside the
+// limit by its deadband. Alarms may latch until acknowledged.
+package alarm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// Kind identifies which limit an alarm is for.
+type Kind int
+
+// Available kinds of alarm.
+const (
+	High Kind = iota
+	Low
+	RateOfChange
+)
+
+var kinds = map[Kind]string{
+	High:         "high",
+	Low:          "low",
+	RateOfChange: "rate-of-change",
+}
+
+// String implements the Stringer interface for Kind.
+func (k Kind) String() string {
+	return kinds[k]
+}
+
+// Limits are the alarm limits of one channel, in the units of the frames'
+// values, which are volts unless an earlier stage has converted them. A nil
+// High or Low and a zero Rate disable that limit. Limits can be set in the
+// JSON configuration of a channel, as in
+//
+//	"limits": {"high": 4.5, "deadband": 0.1, "latch": true}
+type Limits struct {
+	High *float64 `json:"high,omitempty"`
+	Low  *float64 `json:"low,omitempty"`
+	// Deadband is how far back inside the high or low limit a channel must
+	// return before its alarm clears, so that noise on a channel near a
+	// limit doesn't raise the alarm repeatedly.
+	Deadband float64 `json:"deadband,omitempty"`
+	// Rate is the largest change per second, either up or down, allowed
+	// between consecutive scans, and RateDeadband is how far the rate must
+	// fall below Rate before its alarm clears.
+	Rate         float64 `json:"rate,omitempty"`
+	RateDeadband float64 `json:"rate_deadband,omitempty"`
+	// Latch keeps each alarm raised after the channel returns inside the
+	// limit until the alarm has been acknowledged.
+	Latch bool `json:"latch,omitempty"`
+}
+
+// validate checks the limits of the given channel.
+func (l Limits) validate(channel int) error {
+	if l.Deadband < 0 || l.RateDeadband < 0 || l.Rate < 0 {
+		return fmt.Errorf("channel %d limits have a negative rate or deadband", channel)
+	}
+	if l.High != nil && l.Low != nil && *l.Low >= *l.High {
+		return fmt.Errorf("channel %d low limit %g isn't below high limit %g", channel, *l.Low, *l.High)
+	}
+	return nil
+}
+
+// Event reports that an alarm was raised, if Raised is true, or cleared. Value
+// is the channel's value at the scan that raised or cleared the alarm, or its
+// rate of change per second for a RateOfChange alarm, and Limit is the limit
+// it was compared with.
+type Event struct {
+	Channel int
+	Kind    Kind
+	Raised  bool
+	Scan    uint64    // Index of the scan since the scan started
+	Time    time.Time // Pacer time of the scan
+	Value   float64
+	Limit   float64
+}
+
+// Handler is called with each alarm event.
+type Handler func(Event)
+
+// Option configures an Engine.
+type Option func(*Engine)
+
+// WithHandler calls the handler with each event, in order, as the frames are
+// processed. The handler runs on the goroutine processing the frames, so it
+// should act quickly, such as by cutting power to a test stand, and may call
+// Acknowledge.
+func WithHandler(h Handler) Option {
+	return func(e *Engine) {
+		e.handlers = append(e.handlers, h)
+	}
+}
+
+// DefaultEventBuffer is the number of events Watch holds for a slow reader.
+const DefaultEventBuffer = 64
+
+// WithEventBuffer sets the number of events Watch holds on its channel for a
+// reader that falls behind. Zero sends an event only to a reader already
+// waiting, which suits callers that rely solely on the handlers.
+func WithEventBuffer(n int) Option {
+	return func(e *Engine) {
+		e.buffer = n
+	}
+}
+
+// key identifies an alarm.
+type key struct {
+	channel int
+	kind    Kind
+}
+
+// state is the state of an alarm. The alarm is raised until it clears, and
+// beyond is set while the channel is beyond the limit, which for a latched
+// alarm may end before the alarm clears.
+type state struct {
+	raised       bool
+	beyond       bool
+	acknowledged bool
+	event        Event
+}
+
+// Engine evaluates the limits of each channel on each scan of a stream of
+// frames.
+type Engine struct {
+	limits   map[int]Limits
+	handlers []Handler
+	buffer   int
+
+	mu     sync.Mutex
+	states map[key]*state
+	// previous holds the last value of each channel, for the rate of change,
+	// until there's a gap in the scans.
+	previous map[int]float64
+	channels []int
+	next     uint64
+	started  bool
+	dropped  uint64
+	watcher  mccdaq.Watcher
+}
+
+// New creates an Engine that applies the given limits, keyed by channel
+// number, such as those returned by an analog input's Limits method.
+func New(limits map[int]Limits, opts ...Option) (*Engine, error) {
+	if len(limits) == 0 {
+		return nil, fmt.Errorf("alarm engine needs limits for at least one channel")
+	}
+	e := Engine{
+		limits:   make(map[int]Limits),
+		states:   make(map[key]*state),
+		previous: make(map[int]float64),
+		buffer:   DefaultEventBuffer,
+	}
+	for ch, l := range limits {
+		if err := l.validate(ch); err != nil {
+			return nil, err
+		}
+		e.limits[ch] = l
+	}
+	for _, opt := range opts {
+		opt(&e)
+	}
+	if e.buffer < 0 {
+		return nil, fmt.Errorf("alarm event buffer %d is negative", e.buffer)
+	}
+	return &e, nil
+}
+
+// Process evaluates the limits on each scan of the frame, calls the handlers
+// with any events, and returns them. Levels are compared on every scan, but
+// the rate of change isn't evaluated across the first scan of a frame that
+// doesn't follow on from the previous frame. Scans whose value is NaN are
+// skipped. Process fails with mccdaq.ErrInvalidChannel if a channel with
+// limits isn't in the frame.
+func (e *Engine) Process(frame mccdaq.Frame) ([]Event, error) {
+	e.mu.Lock()
+	events, err := e.process(frame)
+	e.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		for _, h := range e.handlers {
+			h(event)
+		}
+	}
+	return events, nil
+}
+
+func (e *Engine) process(frame mccdaq.Frame) ([]Event, error) {
+	positions := make(map[int]int)
+	for i, ch := range frame.Channels {
+		positions[ch] = i
+	}
+	channels := make([]int, 0, len(e.limits))
+	for ch := range e.limits {
+		if _, ok := positions[ch]; !ok {
+			return nil, fmt.Errorf("alarm channel %d not in frame: %w", ch, mccdaq.ErrInvalidChannel)
+		}
+		channels = append(channels, ch)
+	}
+	sort.Ints(channels)
+	if !e.started || frame.Index != e.next || frame.LostScans > 0 || !frame.SameChannels(e.channels) {
+		e.previous = make(map[int]float64)
+		e.channels = append(e.channels[:0], frame.Channels...)
+		e.started = true
+	}
+	e.next = frame.Index + uint64(frame.NumScans())
+	seconds := frame.Timebase.Period.Seconds()
+	var events []Event
+	for scan := 0; scan < frame.NumScans(); scan++ {
+		index := frame.Index + uint64(scan)
+		for _, ch := range channels {
+			l := e.limits[ch]
+			v := frame.Volts[positions[ch]][scan]
+			if math.IsNaN(v) {
+				delete(e.previous, ch)
+				continue
+			}
+			event := Event{Channel: ch, Scan: index, Time: frame.Timebase.Time(index), Value: v}
+			if l.High != nil {
+				event.Kind, event.Limit = High, *l.High
+				events = e.evaluate(events, event, l.Latch, v > *l.High, v <= *l.High-l.Deadband)
+			}
+			if l.Low != nil {
+				event.Kind, event.Limit = Low, *l.Low
+				events = e.evaluate(events, event, l.Latch, v < *l.Low, v >= *l.Low+l.Deadband)
+			}
+			if prev, ok := e.previous[ch]; ok && l.Rate > 0 && seconds > 0 {
+				rate := math.Abs(v-prev) / seconds
+				event.Kind, event.Limit, event.Value = RateOfChange, l.Rate, rate
+				events = e.evaluate(events, event, l.Latch, rate > l.Rate, rate <= l.Rate-l.RateDeadband)
+			}
+			e.previous[ch] = v
+		}
+	}
+	return events, nil
+}
+
+// evaluate updates the alarm for the event's channel and kind, given whether
+// the channel is beyond the limit or back inside it by the deadband, and
+// appends an event if the alarm is raised or cleared.
+func (e *Engine) evaluate(events []Event, event Event, latch, beyond, inside bool) []Event {
+	k := key{event.Channel, event.Kind}
+	s, ok := e.states[k]
+	if !ok {
+		s = &state{}
+		e.states[k] = s
+	}
+	switch {
+	case beyond:
+		s.beyond = true
+		if !s.raised {
+			s.raised, s.acknowledged = true, false
+			event.Raised = true
+			s.event = event
+			events = append(events, event)
+		}
+	case inside:
+		s.beyond = false
+	}
+	if s.raised && !s.beyond && (!latch || s.acknowledged) {
+		s.raised = false
+		events = append(events, event)
+	}
+	return events
+}
+
+// Acknowledge acknowledges the raised alarm of the given kind on the channel,
+// which lets a latched alarm clear once the channel is back inside the limit.
+// If it already is, the alarm clears on the next scan processed. Acknowledge
+// fails if the alarm isn't raised, and may be called from any goroutine.
+func (e *Engine) Acknowledge(channel int, kind Kind) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s, ok := e.states[key{channel, kind}]
+	if !ok || !s.raised {
+		return fmt.Errorf("no %s alarm raised on channel %d", kind, channel)
+	}
+	s.acknowledged = true
+	return nil
+}
+
+// Raised returns the events that raised the alarms still raised, ordered by
+// channel and kind.
+func (e *Engine) Raised() []Event {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var raised []Event
+	for _, s := range e.states {
+		if s.raised {
+			raised = append(raised, s.event)
+		}
+	}
+	sort.Slice(raised, func(i, j int) bool {
+		if raised[i].Channel != raised[j].Channel {
+			return raised[i].Channel < raised[j].Channel
+		}
+		return raised[i].Kind < raised[j].Kind
+	})
+	return raised
+}
+
+// Watch processes each frame received from frames, such as a Stream's
+// Frames, and sends the events on the returned channel, as well as calling
+// the handlers. The channel is closed once frames is closed, processing
+// fails, or the context is done, after which Err reports why.
+//
+// Watch never waits on the reader, so the handlers keep being called even if
+// the channel is never received from. The channel holds up to the engine's
+// event buffer, and an event that doesn't fit is dropped and counted by
+// Dropped.
+func (e *Engine) Watch(ctx context.Context, frames <-chan mccdaq.Frame) <-chan Event {
+	events := make(chan Event, e.buffer)
+	e.watcher.Watch(ctx, frames, func(frame mccdaq.Frame) error {
+		occurred, err := e.Process(frame)
+		if err != nil {
+			return err
+		}
+		for _, event := range occurred {
+			select {
+			case events <- event:
+			default:
+				e.mu.Lock()
+				e.dropped++
+				e.mu.Unlock()
+			}
+		}
+		return nil
+	}, func() { close(events) })
+	return events
+}
+
+// Dropped returns the number of events Watch has dropped because its channel
+// was full.
+func (e *Engine) Dropped() uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.dropped
+}
+
+// Err returns the error that stopped Watch, if any.
+func (e *Engine) Err() error {
+	return e.watcher.Err()
+}
@@ -0,0 +1,298 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package alarm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+)
+
+var epoch = time.Unix(1000, 0)
+
+// newFrame returns a frame starting at the given scan index with channel 0
+// reading the given values, scanned every millisecond.
+func newFrame(index uint64, values ...float64) mccdaq.Frame {
+	return mccdaq.Frame{
+		Index:    index,
+		Timebase: mccdaq.Timebase{Anchor: epoch, Period: time.Millisecond},
+		Channels: []int{0},
+		Raw:      [][]uint16{make([]uint16, len(values))},
+		Volts:    [][]float64{values},
+	}
+}
+
+func float(f float64) *float64 {
+	return &f
+}
+
+// summary returns the kind, raised flag, and scan of each event.
+type summary struct {
+	Kind   Kind
+	Raised bool
+	Scan   uint64
+}
+
+func summarize(events []Event) []summary {
+	var s []summary
+	for _, e := range events {
+		s = append(s, summary{e.Kind, e.Raised, e.Scan})
+	}
+	return s
+}
+
+func TestKindString(t *testing.T) {
+	testCases := []struct {
+		kind Kind
+		want string
+	}{
+		{High, "high"},
+		{Low, "low"},
+		{RateOfChange, "rate-of-change"},
+	}
+	for _, tc := range testCases {
+		if got := tc.kind.String(); got != tc.want {
+			t.Errorf("Expected %s, got %s", tc.want, got)
+		}
+	}
+}
+
+func TestLevels(t *testing.T) {
+	testCases := []struct {
+		name   string
+		limits Limits
+		values []float64
+		want   []summary
+	}{
+		{
+			name:   "high with deadband",
+			limits: Limits{High: float(5), Deadband: 0.5},
+			values: []float64{4, 5.1, 4.8, 5.2, 4.4, 5.3},
+			want:   []summary{{High, true, 1}, {High, false, 4}, {High, true, 5}},
+		},
+		{
+			name:   "low without deadband",
+			limits: Limits{Low: float(0)},
+			values: []float64{1, -0.1, 0, 0.1, -1},
+			want:   []summary{{Low, true, 1}, {Low, false, 2}, {Low, true, 4}},
+		},
+		{
+			name:   "high and low",
+			limits: Limits{High: float(1), Low: float(-1)},
+			values: []float64{0, 2, 0, -2},
+			want:   []summary{{High, true, 1}, {High, false, 2}, {Low, true, 3}},
+		},
+		{
+			name:   "rate of change",
+			limits: Limits{Rate: 1000, RateDeadband: 100},
+			values: []float64{0, 0.5, 2, 2.95, 3.8, 4},
+			want:   []summary{{RateOfChange, true, 2}, {RateOfChange, false, 4}},
+		},
+	}
+	for _, tc := range testCases {
+		e, err := New(map[int]Limits{0: tc.limits})
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		events, err := e.Process(newFrame(0, tc.values...))
+		if err != nil {
+			t.Fatalf("Process: %v", err)
+		}
+		if got := summarize(events); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: Expected events %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestEventDetails(t *testing.T) {
+	var handled []Event
+	e, err := New(map[int]Limits{0: {High: float(5)}}, WithHandler(func(event Event) {
+		handled = append(handled, event)
+	}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	events, err := e.Process(newFrame(100, 4, 6))
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	want := Event{
+		Channel: 0,
+		Kind:    High,
+		Raised:  true,
+		Scan:    101,
+		Time:    epoch.Add(101 * time.Millisecond),
+		Value:   6,
+		Limit:   5,
+	}
+	if len(events) != 1 || events[0] != want {
+		t.Fatalf("Expected event %+v, got %+v", want, events)
+	}
+	if !reflect.DeepEqual(handled, events) {
+		t.Errorf("Expected handler called with %+v, got %+v", events, handled)
+	}
+	if got := e.Raised(); !reflect.DeepEqual(got, events) {
+		t.Errorf("Expected raised alarms %+v, got %+v", events, got)
+	}
+}
+
+func TestLatch(t *testing.T) {
+	e, err := New(map[int]Limits{0: {High: float(5), Latch: true}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := e.Acknowledge(0, High); err == nil {
+		t.Error("Expected error acknowledging an alarm that isn't raised")
+	}
+	events, _ := e.Process(newFrame(0, 6, 4, 4))
+	if got, want := summarize(events), []summary{{High, true, 0}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected events %v, got %v", want, got)
+	}
+	if err := e.Acknowledge(0, High); err != nil {
+		t.Fatalf("Acknowledge: %v", err)
+	}
+	events, _ = e.Process(newFrame(3, 4))
+	if got, want := summarize(events), []summary{{High, false, 3}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected events %v, got %v", want, got)
+	}
+	// Acknowledged while still beyond the limit, the alarm clears on return.
+	events, _ = e.Process(newFrame(4, 6))
+	if err := e.Acknowledge(0, High); err != nil {
+		t.Fatalf("Acknowledge: %v", err)
+	}
+	more, _ := e.Process(newFrame(5, 7, 4))
+	events = append(events, more...)
+	want := []summary{{High, true, 4}, {High, false, 6}}
+	if got := summarize(events); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected events %v, got %v", want, got)
+	}
+	if got := e.Raised(); len(got) != 0 {
+		t.Errorf("Expected no raised alarms, got %+v", got)
+	}
+}
+
+func TestRateGap(t *testing.T) {
+	e, err := New(map[int]Limits{0: {Rate: 1000}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := e.Process(newFrame(0, 0, 0)); err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	// A jump across a gap in the scans isn't a rate of change.
+	events, err := e.Process(newFrame(10, 5, 5))
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Expected no events across a gap, got %+v", events)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	e, err := New(map[int]Limits{0: {Low: float(1)}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	frames := make(chan mccdaq.Frame)
+	go func() {
+		defer close(frames)
+		frames <- newFrame(0, 2, 0)
+		frames <- newFrame(2, 2)
+	}()
+	var events []Event
+	for event := range e.Watch(context.Background(), frames) {
+		events = append(events, event)
+	}
+	want := []summary{{Low, true, 1}, {Low, false, 2}}
+	if got := summarize(events); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected events %v, got %v", want, got)
+	}
+	if err := e.Err(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestWatchUnread(t *testing.T) {
+	const frameCount = 10
+	handled := make(chan Event, 2*frameCount)
+	e, err := New(map[int]Limits{0: {Low: float(1)}},
+		WithHandler(func(event Event) { handled <- event }),
+		WithEventBuffer(1),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	frames := make(chan mccdaq.Frame)
+	events := e.Watch(context.Background(), frames)
+	// Nothing receives from events while the frames are processed, so each
+	// frame, raising and clearing the alarm, must not stall the handlers.
+	for i := 0; i < frameCount; i++ {
+		select {
+		case frames <- newFrame(uint64(2*i), 0, 2):
+		case <-time.After(time.Second):
+			t.Fatalf("Watch stalled at frame %d", i)
+		}
+	}
+	close(frames)
+	var received int
+	for range events {
+		received++
+	}
+	if got := len(handled); got != 2*frameCount {
+		t.Errorf("Expected %d handled events, got %d", 2*frameCount, got)
+	}
+	// Every event is either received or dropped, and the last frame may be
+	// processed after the reader starts.
+	dropped := e.Dropped()
+	if dropped == 0 {
+		t.Errorf("Expected dropped events")
+	}
+	if got := uint64(received) + dropped; got != 2*frameCount {
+		t.Errorf("Expected %d received and dropped events, got %d", 2*frameCount, got)
+	}
+}
+
+func TestLimitsJSON(t *testing.T) {
+	var l Limits
+	data := []byte(`{"high": 0, "rate": 50, "rate_deadband": 5, "latch": true}`)
+	if err := json.Unmarshal(data, &l); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	want := Limits{High: float(0), Rate: 50, RateDeadband: 5, Latch: true}
+	if !reflect.DeepEqual(l, want) {
+		t.Errorf("Expected %+v, got %+v", want, l)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	testCases := []map[int]Limits{
+		nil,
+		{0: {High: float(1), Low: float(1)}},
+		{0: {High: float(1), Deadband: -1}},
+		{0: {Rate: -1}},
+	}
+	for _, limits := range testCases {
+		if _, err := New(limits); err == nil {
+			t.Errorf("Expected error for limits %+v", limits)
+		}
+	}
+	if _, err := New(map[int]Limits{0: {Rate: 1}}, WithEventBuffer(-1)); err == nil {
+		t.Errorf("Expected error for a negative event buffer")
+	}
+	e, err := New(map[int]Limits{3: {High: float(1)}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := e.Process(newFrame(0, 1)); !errors.Is(err, mccdaq.ErrInvalidChannel) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrInvalidChannel, err)
+	}
+}
@@ -0,0 +1,528 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+// Package align aligns analog input frames from several DAQs that each run on
+// their own pacer clock, resampling them onto a common timebase. The frames
+// can come from any DAQ that stamps them with a mccdaq.Timebase with a scan
+// period, such as a usb1608fsplus Stream or a usb20x Framer.
+package align
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// ChannelStride spaces the channel numbers of each source in an aligned
+// frame, in which channel ch of source k is numbered k*ChannelStride+ch.
+const ChannelStride = 8
+
+// usbFrameTime limits how well host timestamps can place scan data, since a
+// full-speed USB device only transfers data once per 1 ms frame.
+const usbFrameTime = time.Millisecond
+
+// maxGapPeriods is how many scan periods can separate two samples of a source
+// before the samples between them are treated as missing.
+const maxGapPeriods = 1.5
+
+// minCorrelation is the smallest normalized cross-correlation of the
+// reference signals accepted as a match.
+const minCorrelation = 0.5
+
+// Estimate is the estimated alignment of a source with the first source.
+type Estimate struct {
+	// Offset is how much later the source's host timestamps are than the
+	// first source's, as measured from the reference signal, and is
+	// subtracted from the source's timestamps. Offset is zero without a
+	// reference signal.
+	Offset time.Duration
+	// PPM is the rate at which the source's pacer clock gains on the first
+	// source's pacer clock, estimated from their drift against the host
+	// clock.
+	PPM float64
+	// Uncertainty is the estimated uncertainty of the alignment of the
+	// source's scans with the first source's scans.
+	Uncertainty time.Duration
+}
+
+// Option configures an Aligner.
+type Option func(*Aligner)
+
+// WithReference aligns the sources by cross-correlating a reference signal
+// shared by all of them, such as a common test signal wired to a channel of
+// each DAQ, in addition to their host timestamps. The reference is read from
+// the given channel number of each source and correlated over windows of
+// the given number of aligned scans, at lags up to maxLag, which must be
+// less than the time over which the reference repeats itself.
+func WithReference(channel, window int, maxLag time.Duration) Option {
+	return func(a *Aligner) {
+		a.useReference = true
+		a.refChannel = channel
+		a.refWindow = window
+		a.maxLag = maxLag
+	}
+}
+
+// Aligner resamples frames from several sources onto a common timebase. Each
+// source's scans are placed on the host clock by its Timebase's HostTime, and
+// corrected by the reference signal if there is one, and the sources are
+// linearly interpolated at the common scan times, starting from the latest of
+// their first scans. Where a source is missing scans, its aligned values are
+// NaN.
+type Aligner struct {
+	period  float64 // Seconds between aligned scans
+	sources []*source
+	epoch   time.Time
+	// start is the time of the first aligned scan in seconds since epoch,
+	// once gridded is set, and next is the index of the next aligned scan.
+	start   float64
+	gridded bool
+	next    uint64
+
+	useReference bool
+	refChannel   int
+	refWindow    int
+	maxLag       time.Duration
+	refs         [][]float64
+
+	watcher mccdaq.Watcher
+}
+
+// source holds the scans of one source not yet used for aligned scans. Times
+// are host times in seconds since the Aligner's epoch, before the reference
+// offset is applied.
+type source struct {
+	started  bool
+	channels []int
+	ref      int // Position of the reference channel in channels
+	next     uint64
+	period   float64
+	timebase mccdaq.Timebase
+	times    []float64
+	volts    [][]float64
+	raw      [][]uint16
+	// revisions accumulates how much each new timebase moved the host times
+	// of the scans, as a measure of the uncertainty of the host timestamps.
+	revSumSq float64
+	revN     int
+	// offset is the reference offset in seconds, correlated once it has been
+	// measured, and lagSumSq and lagN accumulate the residual lags measured
+	// since.
+	offset     float64
+	correlated bool
+	lagSumSq   float64
+	lagN       int
+}
+
+// New creates an Aligner for the given number of sources that resamples them
+// at the given rate in Hz.
+func New(rate float64, sources int, opts ...Option) (*Aligner, error) {
+	if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+		return nil, fmt.Errorf("aligned scan rate %g Hz: %w", rate, mccdaq.ErrInvalidFrequency)
+	}
+	if sources < 1 {
+		return nil, fmt.Errorf("aligner needs at least one source")
+	}
+	a := Aligner{period: 1 / rate}
+	for _, opt := range opts {
+		opt(&a)
+	}
+	if a.useReference && (a.refWindow < 2 || a.maxLag <= 0) {
+		return nil, fmt.Errorf("bad reference window of %d scans and maximum lag %s",
+			a.refWindow, a.maxLag)
+	}
+	a.sources = make([]*source, sources)
+	for i := range a.sources {
+		a.sources[i] = &source{}
+	}
+	a.refs = make([][]float64, sources)
+	return &a, nil
+}
+
+// Push adds the next frame from the given source. The frames from each source
+// must have the same channels, and Push fails with mccdaq.ErrInvalidChannel if
+// they change, or if the reference channel is missing.
+func (a *Aligner) Push(k int, frame mccdaq.Frame) error {
+	if k < 0 || k >= len(a.sources) {
+		return fmt.Errorf("no source %d among %d sources", k, len(a.sources))
+	}
+	if frame.Timebase.Period <= 0 {
+		return fmt.Errorf("frame from source %d has no timebase", k)
+	}
+	src := a.sources[k]
+	if src.started && !frame.SameChannels(src.channels) {
+		return fmt.Errorf("channels of source %d changed from %v to %v: %w",
+			k, src.channels, frame.Channels, mccdaq.ErrInvalidChannel)
+	}
+	if a.useReference && frame.Position(a.refChannel) < 0 {
+		return fmt.Errorf("reference channel %d not in frame from source %d: %w",
+			a.refChannel, k, mccdaq.ErrInvalidChannel)
+	}
+	if a.epoch.IsZero() {
+		a.epoch = frame.Timebase.HostTime(frame.Index)
+	}
+	if !src.started {
+		src.started = true
+		src.channels = append([]int{}, frame.Channels...)
+		src.ref = frame.Position(a.refChannel)
+		src.volts = make([][]float64, len(frame.Channels))
+		src.raw = make([][]uint16, len(frame.Channels))
+	} else if frame.Index == src.next && frame.LostScans == 0 {
+		revision := src.timebase.HostTime(frame.Index).Sub(frame.Timebase.HostTime(frame.Index))
+		src.revSumSq += revision.Seconds() * revision.Seconds()
+		src.revN++
+	}
+	src.timebase = frame.Timebase
+	src.period = frame.Timebase.Period.Seconds()
+	for scan := 0; scan < frame.NumScans(); scan++ {
+		t := frame.Timebase.HostTime(frame.Index + uint64(scan)).Sub(a.epoch).Seconds()
+		// Keep the times increasing if a new timebase moved them back.
+		if n := len(src.times); n > 0 && t <= src.times[n-1] {
+			t = math.Nextafter(src.times[n-1], math.Inf(1))
+		}
+		src.times = append(src.times, t)
+		for i := range frame.Channels {
+			src.volts[i] = append(src.volts[i], frame.Volts[i][scan])
+			src.raw[i] = append(src.raw[i], frame.Raw[i][scan])
+		}
+	}
+	src.next = frame.Index + uint64(frame.NumScans())
+	return nil
+}
+
+// Next returns the next frame of the given number of aligned scans once every
+// source has been pushed scans up to the last of them, and reports whether it
+// could. The aligned frame's Index counts aligned scans, its Timebase anchors
+// the first aligned scan on the host clock, and its channels are numbered by
+// ChannelStride. Raw holds the raw code of the nearest scan of each source.
+func (a *Aligner) Next(scans int) (mccdaq.Frame, bool) {
+	var frame mccdaq.Frame
+	if scans < 1 {
+		return frame, false
+	}
+	for _, src := range a.sources {
+		if len(src.times) == 0 {
+			return frame, false
+		}
+	}
+	if !a.gridded {
+		a.start = math.Inf(-1)
+		for _, src := range a.sources {
+			a.start = math.Max(a.start, src.times[0]-src.offset)
+		}
+		a.gridded = true
+	}
+	end := a.time(a.next + uint64(scans) - 1)
+	for _, src := range a.sources {
+		if src.times[len(src.times)-1]-src.offset < end {
+			return frame, false
+		}
+	}
+	frame = mccdaq.Frame{
+		Index: a.next,
+		Timebase: mccdaq.Timebase{
+			Anchor: a.epoch.Add(seconds(a.start)),
+			Period: seconds(a.period),
+		},
+	}
+	for k, src := range a.sources {
+		first := len(frame.Channels)
+		for _, ch := range src.channels {
+			frame.Channels = append(frame.Channels, k*ChannelStride+ch)
+			frame.Volts = append(frame.Volts, make([]float64, scans))
+			frame.Raw = append(frame.Raw, make([]uint16, scans))
+		}
+		j := 0
+		for scan := 0; scan < scans; scan++ {
+			t := a.time(a.next+uint64(scan)) + src.offset
+			for j+1 < len(src.times) && src.times[j+1] <= t {
+				j++
+			}
+			src.interpolate(frame, first, scan, j, t)
+		}
+		src.trim(j)
+		if a.useReference {
+			ref := frame.Volts[first+src.ref]
+			a.refs[k] = append(a.refs[k], ref...)
+		}
+	}
+	a.next += uint64(scans)
+	if a.useReference && len(a.refs[0]) >= a.refWindow {
+		a.correlate()
+	}
+	return frame, true
+}
+
+// Estimate returns the estimated alignment of the given source with the first
+// source. Host timestamps can't place scans better than the 1 ms USB frame,
+// nor better than their revisions as each source's drift estimate is
+// updated, so without a reference signal the uncertainty combines those for
+// both sources. Once the reference signal has been correlated, the
+// uncertainty is the RMS of the lags measured since the first correlation,
+// but no less than a tenth of an aligned scan period.
+func (a *Aligner) Estimate(k int) Estimate {
+	if k <= 0 || k >= len(a.sources) {
+		return Estimate{}
+	}
+	src, first := a.sources[k], a.sources[0]
+	est := Estimate{
+		Offset: seconds(src.offset),
+		PPM:    first.timebase.Drift.PPM - src.timebase.Drift.PPM,
+	}
+	if src.correlated {
+		u := a.period
+		if src.lagN > 0 {
+			u = math.Max(math.Sqrt(src.lagSumSq/float64(src.lagN)), a.period/10)
+		}
+		est.Uncertainty = seconds(u)
+		return est
+	}
+	us, u0 := src.hostUncertainty(), first.hostUncertainty()
+	est.Uncertainty = seconds(math.Sqrt(us*us + u0*u0))
+	return est
+}
+
+// Watch pushes the frames received from each of the sources, such as the
+// Frames of a Stream from each DAQ, and sends frames of the given number of
+// aligned scans on the returned channel as they become available. The
+// channel is closed once every source is closed, a Push fails, or the context
+// is done, after which Err reports why.
+func (a *Aligner) Watch(
+	ctx context.Context, scans int, sources ...<-chan mccdaq.Frame,
+) <-chan mccdaq.Frame {
+	aligned := make(chan mccdaq.Frame)
+	if len(sources) != len(a.sources) {
+		a.watcher.Go(func() error {
+			return fmt.Errorf("watching %d sources with an aligner for %d", len(sources), len(a.sources))
+		}, func() { close(aligned) })
+		return aligned
+	}
+	type pushed struct {
+		k     int
+		frame mccdaq.Frame
+		ok    bool
+	}
+	received := make(chan pushed)
+	done := make(chan struct{})
+	for k, frames := range sources {
+		go func(k int, frames <-chan mccdaq.Frame) {
+			for {
+				frame, ok := <-frames
+				select {
+				case received <- pushed{k, frame, ok}:
+				case <-done:
+					return
+				case <-ctx.Done():
+					return
+				}
+				if !ok {
+					return
+				}
+			}
+		}(k, frames)
+	}
+	a.watcher.Go(func() error {
+		defer close(done)
+		open := len(sources)
+		for {
+			var p pushed
+			select {
+			case p = <-received:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if !p.ok {
+				open--
+				if open == 0 {
+					return nil
+				}
+				continue
+			}
+			if err := a.Push(p.k, p.frame); err != nil {
+				return err
+			}
+			for {
+				frame, ok := a.Next(scans)
+				if !ok {
+					break
+				}
+				if err := mccdaq.Send(ctx, aligned, frame); err != nil {
+					return err
+				}
+			}
+		}
+	}, func() { close(aligned) })
+	return aligned
+}
+
+// Err returns the error that stopped Watch, if any.
+func (a *Aligner) Err() error {
+	return a.watcher.Err()
+}
+
+// time returns the time of the given aligned scan in seconds since epoch.
+func (a *Aligner) time(scan uint64) float64 {
+	return a.start + float64(scan)*a.period
+}
+
+// correlate measures the lag of each source's reference signal behind the
+// first source's over the latest window, and corrects the source's offset by
+// it. Windows with missing scans or no clear match are skipped. The reference
+// scans after the window were resampled with the offsets before they were
+// corrected, so they're dropped if any offset changed.
+func (a *Aligner) correlate() {
+	window := a.refWindow
+	x0 := a.refs[0][:window]
+	maxLag := int(a.maxLag.Seconds() / a.period)
+	if maxLag >= window {
+		maxLag = window - 1
+	}
+	corrected := false
+	for k := 1; k < len(a.sources); k++ {
+		lag, ok := crossCorrelationLag(x0, a.refs[k][:window], maxLag)
+		if !ok {
+			continue
+		}
+		src := a.sources[k]
+		lagSeconds := lag * a.period
+		if src.correlated {
+			src.lagSumSq += lagSeconds * lagSeconds
+			src.lagN++
+		}
+		src.offset += lagSeconds
+		src.correlated = true
+		corrected = true
+	}
+	for k := range a.refs {
+		if corrected {
+			a.refs[k] = a.refs[k][:0]
+			continue
+		}
+		a.refs[k] = append(a.refs[k][:0], a.refs[k][window:]...)
+	}
+}
+
+// crossCorrelationLag returns the lag in samples, interpolated between
+// samples, at which y best matches x, so that y[n+lag] ≈ x[n], searching lags
+// up to maxLag either way. Each lag is scored by the correlation coefficient
+// of the overlapping samples. It reports false if either signal has missing
+// samples, or if the best match is weak or at the edge of the search.
+func crossCorrelationLag(x, y []float64, maxLag int) (float64, bool) {
+	for i := range x {
+		if math.IsNaN(x[i]) || math.IsNaN(y[i]) {
+			return 0, false
+		}
+	}
+	n := len(x)
+	corr := make([]float64, 2*maxLag+1)
+	best := 0
+	for l := -maxLag; l <= maxLag; l++ {
+		i0, i1 := 0, n
+		if l < 0 {
+			i0 = -l
+		} else {
+			i1 = n - l
+		}
+		corr[l+maxLag] = correlation(x[i0:i1], y[i0+l:i1+l])
+		if corr[l+maxLag] > corr[best] {
+			best = l + maxLag
+		}
+	}
+	if corr[best] < minCorrelation || best == 0 || best == len(corr)-1 {
+		return 0, false
+	}
+	// Fit a parabola through the peak and its neighbors.
+	c0, c1, c2 := corr[best-1], corr[best], corr[best+1]
+	shift := 0.0
+	if denom := c0 - 2*c1 + c2; denom != 0 {
+		shift = 0.5 * (c0 - c2) / denom
+	}
+	return float64(best-maxLag) + shift, true
+}
+
+// correlation returns the correlation coefficient of x and y, which is zero
+// if either is flat.
+func correlation(x, y []float64) float64 {
+	var xm, ym float64
+	for i := range x {
+		xm += x[i]
+		ym += y[i]
+	}
+	xm /= float64(len(x))
+	ym /= float64(len(y))
+	var sxy, sxx, syy float64
+	for i := range x {
+		dx, dy := x[i]-xm, y[i]-ym
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return 0
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
+
+// interpolate sets the given aligned scan of the frame's channels from first
+// onward to the source's values at time t, which lies at or after the
+// source's scan j, interpolating linearly between scans j and j+1. The time
+// is in the source's uncorrected host time.
+func (src *source) interpolate(frame mccdaq.Frame, first, scan, j int, t float64) {
+	t0 := src.times[j]
+	missing, frac := false, 0.0
+	switch {
+	case t < t0:
+		missing = true
+	case j+1 >= len(src.times):
+		missing = t > t0
+	default:
+		t1 := src.times[j+1]
+		missing = t1-t0 > maxGapPeriods*src.period
+		frac = (t - t0) / (t1 - t0)
+	}
+	nearest := j
+	if frac >= 0.5 {
+		nearest = j + 1
+	}
+	for i := range src.channels {
+		frame.Raw[first+i][scan] = src.raw[i][nearest]
+		if missing {
+			frame.Volts[first+i][scan] = math.NaN()
+			continue
+		}
+		v := src.volts[i][j]
+		if frac > 0 {
+			v += frac * (src.volts[i][j+1] - v)
+		}
+		frame.Volts[first+i][scan] = v
+	}
+}
+
+// trim discards the source's scans before scan j, which is still needed to
+// interpolate the next aligned scan.
+func (src *source) trim(j int) {
+	src.times = append(src.times[:0], src.times[j:]...)
+	for i := range src.channels {
+		src.volts[i] = append(src.volts[i][:0], src.volts[i][j:]...)
+		src.raw[i] = append(src.raw[i][:0], src.raw[i][j:]...)
+	}
+}
+
+// hostUncertainty returns the uncertainty in seconds of the source's host
+// timestamps.
+func (src *source) hostUncertainty() float64 {
+	u := usbFrameTime.Seconds()
+	if src.revN > 0 {
+		u = math.Max(u, math.Sqrt(src.revSumSq/float64(src.revN)))
+	}
+	return u
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Round(s * float64(time.Second)))
+}
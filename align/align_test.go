@@ -0,0 +1,268 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package align
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+)
+
+var epoch = time.Unix(1000, 0)
+
+// newFrames returns frames of the given size from a source whose first scan is
+// truly taken start after epoch, but whose timebase claims it was taken
+// start+skew after epoch. Channel 0 reads signal at the true time of each scan
+// in seconds since epoch.
+func newFrames(scans, size int, period, start, skew time.Duration,
+	signal func(float64) float64) []mccdaq.Frame {
+	tb := mccdaq.Timebase{Anchor: epoch.Add(start + skew), Period: period}
+	var frames []mccdaq.Frame
+	for first := 0; first < scans; first += size {
+		frame := mccdaq.Frame{
+			Index:    uint64(first),
+			Timebase: tb,
+			Channels: []int{0},
+			Raw:      make([][]uint16, 1),
+			Volts:    make([][]float64, 1),
+		}
+		for scan := first; scan < first+size && scan < scans; scan++ {
+			t := (start + time.Duration(scan)*period).Seconds()
+			frame.Raw[0] = append(frame.Raw[0], uint16(scan))
+			frame.Volts[0] = append(frame.Volts[0], signal(t))
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+func ramp(t float64) float64 {
+	return t
+}
+
+func reference(t float64) float64 {
+	return math.Sin(2*math.Pi*3.1*t) + 0.7*math.Sin(2*math.Pi*7.7*t+1) +
+		0.5*math.Sin(2*math.Pi*13.3*t+2)
+}
+
+// pushAll pushes the frames from each source in turn and returns every aligned
+// frame of the given number of scans.
+func pushAll(t *testing.T, a *Aligner, scans int, sources ...[]mccdaq.Frame) []mccdaq.Frame {
+	var aligned []mccdaq.Frame
+	for i := 0; ; i++ {
+		pushed := false
+		for k, frames := range sources {
+			if i < len(frames) {
+				if err := a.Push(k, frames[i]); err != nil {
+					t.Fatalf("Push: %v", err)
+				}
+				pushed = true
+			}
+		}
+		for {
+			frame, ok := a.Next(scans)
+			if !ok {
+				break
+			}
+			aligned = append(aligned, frame)
+		}
+		if !pushed {
+			return aligned
+		}
+	}
+}
+
+func TestAlignHostTimestamps(t *testing.T) {
+	a, err := New(500, 2)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	src0 := newFrames(1000, 100, time.Millisecond, 0, 0, ramp)
+	src1 := newFrames(800, 64, 1250*time.Microsecond, 250*time.Microsecond, 0, ramp)
+	aligned := pushAll(t, a, 50, src0, src1)
+	if len(aligned) != 10 {
+		t.Fatalf("Expected 10 aligned frames, got %d", len(aligned))
+	}
+	for i, frame := range aligned {
+		if frame.Index != uint64(50*i) {
+			t.Errorf("Expected index %d, got %d", 50*i, frame.Index)
+		}
+		if !reflect.DeepEqual(frame.Channels, []int{0, 8}) {
+			t.Errorf("Expected channels [0 8], got %v", frame.Channels)
+		}
+		if frame.Timebase.Period != 2*time.Millisecond {
+			t.Errorf("Expected 2 ms period, got %v", frame.Timebase.Period)
+		}
+		for scan := 0; scan < frame.NumScans(); scan++ {
+			want := frame.Time(scan).Sub(epoch).Seconds()
+			for j := range frame.Channels {
+				if got := frame.Volts[j][scan]; math.Abs(got-want) > 1e-9 {
+					t.Fatalf("Expected channel %d at %v s to read %v, got %v",
+						frame.Channels[j], want, want, got)
+				}
+			}
+		}
+	}
+	// The first aligned scan is the later of the first scans.
+	if got := aligned[0].Time(0).Sub(epoch); got != 250*time.Microsecond {
+		t.Errorf("Expected first aligned scan at 250µs, got %v", got)
+	}
+	est := a.Estimate(1)
+	ms := float64(time.Millisecond)
+	want := time.Duration(math.Sqrt2 * ms)
+	if d := est.Uncertainty - want; d < -time.Microsecond || d > time.Microsecond {
+		t.Errorf("Expected uncertainty %v, got %v", want, est.Uncertainty)
+	}
+	if est.Offset != 0 {
+		t.Errorf("Expected no offset without a reference, got %v", est.Offset)
+	}
+}
+
+func TestAlignReference(t *testing.T) {
+	skew := 3 * time.Millisecond
+	a, err := New(1000, 2, WithReference(0, 500, 20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	src0 := newFrames(4000, 100, time.Millisecond, 0, 0, reference)
+	src1 := newFrames(4000, 100, time.Millisecond, 0, skew, reference)
+	aligned := pushAll(t, a, 100, src0, src1)
+	est := a.Estimate(1)
+	if d := est.Offset - skew; d < -20*time.Microsecond || d > 20*time.Microsecond {
+		t.Errorf("Expected offset %v, got %v", skew, est.Offset)
+	}
+	if est.Uncertainty <= 0 || est.Uncertainty > 200*time.Microsecond {
+		t.Errorf("Expected uncertainty up to 200µs, got %v", est.Uncertainty)
+	}
+	last := aligned[len(aligned)-1]
+	for scan := 0; scan < last.NumScans(); scan++ {
+		if d := last.Volts[0][scan] - last.Volts[1][scan]; math.Abs(d) > 0.01 {
+			t.Fatalf("Expected sources to line up at scan %d, got %v and %v",
+				scan, last.Volts[0][scan], last.Volts[1][scan])
+		}
+	}
+}
+
+func TestAlignReferenceUnevenWindow(t *testing.T) {
+	// With windows that don't hold a whole number of aligned frames, the
+	// scans left over after each window were resampled with the offset
+	// before it was corrected.
+	skew := 3 * time.Millisecond
+	a, err := New(1000, 2, WithReference(0, 150, 20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	src0 := newFrames(4000, 100, time.Millisecond, 0, 0, reference)
+	src1 := newFrames(4000, 100, time.Millisecond, 0, skew, reference)
+	pushAll(t, a, 100, src0, src1)
+	est := a.Estimate(1)
+	if d := est.Offset - skew; d < -20*time.Microsecond || d > 20*time.Microsecond {
+		t.Errorf("Expected offset %v, got %v", skew, est.Offset)
+	}
+	if est.Uncertainty <= 0 || est.Uncertainty > 200*time.Microsecond {
+		t.Errorf("Expected uncertainty up to 200µs, got %v", est.Uncertainty)
+	}
+}
+
+func TestAlignGap(t *testing.T) {
+	a, err := New(1000, 2)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	src0 := newFrames(500, 100, time.Millisecond, 0, 0, ramp)
+	src1 := newFrames(500, 100, time.Millisecond, 0, 0, ramp)
+	src1 = append(src1[:2], src1[3:]...)
+	aligned := pushAll(t, a, 100, src0, src1)
+	if len(aligned) != 5 {
+		t.Fatalf("Expected 5 aligned frames, got %d", len(aligned))
+	}
+	for frame := 0; frame < 5; frame++ {
+		for scan := 0; scan < 100; scan++ {
+			index := 100*frame + scan
+			missing := index >= 200 && index < 300
+			if got := math.IsNaN(aligned[frame].Volts[1][scan]); got != missing {
+				t.Fatalf("Expected scan %d missing %v, got %v", index, missing, got)
+			}
+			if math.IsNaN(aligned[frame].Volts[0][scan]) {
+				t.Fatalf("Expected scan %d of the first source", index)
+			}
+		}
+	}
+}
+
+func TestAlignDrift(t *testing.T) {
+	a, err := New(1000, 2)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	frames := newFrames(100, 100, time.Millisecond, 0, 0, ramp)
+	frames[0].Timebase.Drift.PPM = 30
+	if err := a.Push(0, frames[0]); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	frames[0].Timebase.Drift.PPM = 10
+	if err := a.Push(1, frames[0]); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if got := a.Estimate(1).PPM; got != 20 {
+		t.Errorf("Expected 20 ppm, got %v", got)
+	}
+	if got := a.Estimate(0); got != (Estimate{}) {
+		t.Errorf("Expected no estimate for the first source, got %+v", got)
+	}
+}
+
+func TestAlignWatch(t *testing.T) {
+	a, err := New(1000, 2)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	sources := make([]chan mccdaq.Frame, 2)
+	for k := range sources {
+		sources[k] = make(chan mccdaq.Frame)
+		go func(frames chan mccdaq.Frame) {
+			defer close(frames)
+			for _, frame := range newFrames(300, 100, time.Millisecond, 0, 0, ramp) {
+				frames <- frame
+			}
+		}(sources[k])
+	}
+	count := 0
+	for range a.Watch(context.Background(), 100, sources[0], sources[1]) {
+		count++
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 aligned frames, got %d", count)
+	}
+	if err := a.Err(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestAlignErrors(t *testing.T) {
+	if _, err := New(0, 2); !errors.Is(err, mccdaq.ErrInvalidFrequency) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrInvalidFrequency, err)
+	}
+	if _, err := New(1000, 2, WithReference(0, 1, time.Millisecond)); err == nil {
+		t.Error("Expected error for a one scan reference window")
+	}
+	a, err := New(1000, 2, WithReference(3, 100, time.Millisecond))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	frames := newFrames(100, 100, time.Millisecond, 0, 0, ramp)
+	if err := a.Push(0, frames[0]); !errors.Is(err, mccdaq.ErrInvalidChannel) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrInvalidChannel, err)
+	}
+	if err := a.Push(2, frames[0]); err == nil {
+		t.Error("Expected error for a missing source")
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+// Package bridge converts the output of Wheatstone bridge sensors, such as
+// strain gauges and load cells fed through external signal conditioners,
+// into microstrain or load. The bridge output is divided by the excitation
+// voltage, measured on a companion channel or given as a fixed value, so
+// that drift in the excitation cancels out.
+package bridge
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// Type is the configuration of a strain gauge bridge. Each assumes the bridge
+// is wired so that tension gives a positive output.
+type Type int
+
+// Available bridge types.
+const (
+	// QuarterBridge has one active gauge, and its output is slightly
+	// nonlinear in strain, which the conversion corrects for.
+	QuarterBridge Type = iota
+	// HalfBridge has two active gauges in adjacent arms, one in tension and
+	// one in compression, as on opposite faces of a beam in bending.
+	HalfBridge
+	// FullBridge has four active gauges, as in a load cell.
+	FullBridge
+)
+
+var types = map[Type]string{
+	QuarterBridge: "quarter",
+	HalfBridge:    "half",
+	FullBridge:    "full",
+}
+
+// String implements the Stringer interface for Type.
+func (t Type) String() string {
+	return types[t]
+}
+
+// UnmarshalJSON implements the Unmarshaler interface for Type by taking the
+// string form of a Type.
+func (t *Type) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("bridge type should be a string, got %s", data)
+	}
+	for typ, name := range types {
+		if name == s {
+			*t = typ
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid string %q for bridge Type", s)
+}
+
+// MarshalJSON implements the Marshaler interface for Type.
+func (t Type) MarshalJSON() ([]byte, error) {
+	return json.Marshal(types[t])
+}
+
+// Config configures the conversion of one bridge channel. A strain gauge
+// bridge, converted into microstrain, needs its GaugeFactor. A load cell,
+// converted into the units of its Capacity, needs its RatedOutput and
+// Capacity instead. Either needs its excitation, from ExcitationChannel if
+// set, and otherwise the fixed Excitation. In JSON, as in
+//
+//	{"type": "quarter", "gauge_factor": 2.1, "gauge_resistance": 350, "lead_resistance": 0.5, "excitation_channel": 7}
+//	{"type": "full", "rated_output": 2, "capacity": 500, "excitation": 10}
+type Config struct {
+	Type        Type    `json:"type"`
+	GaugeFactor float64 `json:"gauge_factor,omitempty"`
+	// GaugeResistance is the resistance in Ω of each arm of the bridge,
+	// which lead-wire compensation and shunt calibration need.
+	GaugeResistance float64 `json:"gauge_resistance,omitempty"`
+	// LeadResistance is the resistance in Ω of each lead wire to the active
+	// gauges of a quarter or half bridge, which desensitizes the bridge
+	// unless compensated for. A full bridge needs no compensation.
+	LeadResistance float64 `json:"lead_resistance,omitempty"`
+	// RatedOutput is a load cell's output in mV/V at its Capacity.
+	RatedOutput float64 `json:"rated_output,omitempty"`
+	Capacity    float64 `json:"capacity,omitempty"`
+	// Excitation is the fixed excitation in volts, used unless the
+	// excitation is measured on ExcitationChannel.
+	Excitation        float64 `json:"excitation,omitempty"`
+	ExcitationChannel *int    `json:"excitation_channel,omitempty"`
+	// ZeroOffset is the bridge output in V/V with no load, which Zero sets,
+	// and Gain adjusts the sensitivity, which ShuntCalibrate sets. A zero
+	// Gain means 1.
+	ZeroOffset float64 `json:"zero_offset,omitempty"`
+	Gain       float64 `json:"gain,omitempty"`
+}
+
+// Validate checks that the config has what it needs.
+func (c Config) Validate() error {
+	if _, ok := types[c.Type]; !ok {
+		return fmt.Errorf("bad bridge type %d: %w", c.Type, mccdaq.ErrNotSupported)
+	}
+	switch {
+	case c.loadCell():
+		if c.Capacity == 0 {
+			return fmt.Errorf("load cell bridge needs a capacity")
+		}
+	case c.GaugeFactor <= 0:
+		return fmt.Errorf("strain gauge bridge needs a positive gauge factor, not %g", c.GaugeFactor)
+	}
+	if c.ExcitationChannel == nil && c.Excitation <= 0 {
+		return fmt.Errorf("bridge needs an excitation channel or a positive excitation voltage")
+	}
+	if c.LeadResistance < 0 || c.GaugeResistance < 0 {
+		return fmt.Errorf("bridge resistances can't be negative")
+	}
+	if c.LeadResistance > 0 && c.GaugeResistance == 0 {
+		return fmt.Errorf("bridge lead-wire compensation needs the gauge resistance")
+	}
+	return nil
+}
+
+// loadCell returns whether the bridge is a load cell rather than a strain
+// gauge bridge.
+func (c Config) loadCell() bool {
+	return c.RatedOutput != 0
+}
+
+// Unit returns the unit of the converted values, which is "µε" for a strain
+// gauge bridge, and empty for a load cell, whose unit is that of its
+// Capacity.
+func (c Config) Unit() string {
+	if c.loadCell() {
+		return ""
+	}
+	return "µε"
+}
+
+// Value converts the bridge output in volts with the given excitation in
+// volts into microstrain or load.
+func (c Config) Value(volts, excitation float64) float64 {
+	return c.value(volts/excitation - c.ZeroOffset)
+}
+
+// value converts the bridge output ratio in V/V, less the zero offset, into
+// microstrain or load.
+func (c Config) value(ratio float64) float64 {
+	gain := c.Gain
+	if gain == 0 {
+		gain = 1
+	}
+	if c.loadCell() {
+		return gain * ratio * 1000 / c.RatedOutput * c.Capacity
+	}
+	var strain float64
+	switch c.Type {
+	case QuarterBridge:
+		strain = 4 * ratio / (c.GaugeFactor * (1 - 2*ratio))
+	case HalfBridge:
+		strain = 2 * ratio / c.GaugeFactor
+	default:
+		strain = ratio / c.GaugeFactor
+	}
+	if c.Type != FullBridge && c.GaugeResistance > 0 {
+		strain *= 1 + c.LeadResistance/c.GaugeResistance
+	}
+	return gain * strain * 1e6
+}
+
+// ShuntValue returns the value that the bridge should read, with no load and
+// a Gain of 1, once the shunt resistor of the given resistance in Ω is
+// connected across one active gauge, at the gauge for a quarter or half
+// bridge so that the lead wires are accounted for. The shunt lowers that
+// arm's resistance, so for a strain gauge bridge the value is the compressive
+// strain that would give the same output.
+func (c Config) ShuntValue(shunt float64) (float64, error) {
+	if c.GaugeResistance <= 0 || shunt <= 0 {
+		return 0, fmt.Errorf("shunt calibration needs positive gauge and shunt resistances")
+	}
+	rg := c.GaugeResistance
+	arm := rg
+	if c.Type != FullBridge {
+		arm += c.LeadResistance
+	}
+	// The fractional change in the arm's resistance gives the bridge output
+	// of a single active arm.
+	x := (rg*shunt/(rg+shunt) - rg) / arm
+	ratio := x / (2 * (2 + x))
+	uncalibrated := c
+	uncalibrated.Gain = 1
+	return uncalibrated.value(ratio), nil
+}
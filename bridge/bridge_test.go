@@ -0,0 +1,148 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package bridge
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/gotmc/mccdaq"
+)
+
+func TestTypeString(t *testing.T) {
+	testCases := []struct {
+		typ  Type
+		want string
+	}{
+		{QuarterBridge, "quarter"},
+		{HalfBridge, "half"},
+		{FullBridge, "full"},
+	}
+	for _, tc := range testCases {
+		if got := tc.typ.String(); got != tc.want {
+			t.Errorf("Expected %s, got %s", tc.want, got)
+		}
+	}
+}
+
+func TestValue(t *testing.T) {
+	// Each bridge is strained by 1000 µε, or loaded to half capacity, with
+	// 5 V of excitation.
+	const gf = 2.0
+	x := gf * 1000e-6
+	testCases := []struct {
+		name   string
+		config Config
+		volts  float64
+		want   float64
+	}{
+		{
+			name:   "quarter",
+			config: Config{Type: QuarterBridge, GaugeFactor: gf, Excitation: 5},
+			volts:  5 * x / (4 + 2*x),
+		},
+		{
+			name: "quarter with leads",
+			config: Config{Type: QuarterBridge, GaugeFactor: gf, GaugeResistance: 350,
+				LeadResistance: 1, Excitation: 5},
+			volts: 5 * (x * 350 / 351) / (4 + 2*x*350/351),
+		},
+		{
+			name:   "half",
+			config: Config{Type: HalfBridge, GaugeFactor: gf, Excitation: 5},
+			volts:  5 * x / 2,
+		},
+		{
+			name:   "full",
+			config: Config{Type: FullBridge, GaugeFactor: gf, Excitation: 5},
+			volts:  5 * x,
+		},
+		{
+			name:   "zero offset",
+			config: Config{Type: FullBridge, GaugeFactor: gf, Excitation: 5, ZeroOffset: 1e-4},
+			volts:  5 * (x + 1e-4),
+		},
+		{
+			name:   "load cell",
+			config: Config{Type: FullBridge, RatedOutput: 2, Capacity: 2000, Excitation: 5},
+			volts:  5 * 0.001,
+		},
+	}
+	for _, tc := range testCases {
+		if err := tc.config.Validate(); err != nil {
+			t.Fatalf("%s: Validate: %v", tc.name, err)
+		}
+		if got := tc.config.Value(tc.volts, 5); math.Abs(got-1000) > 1e-6 {
+			t.Errorf("%s: Expected 1000, got %v", tc.name, got)
+		}
+	}
+}
+
+func TestShuntValue(t *testing.T) {
+	// A 174.65 kΩ shunt across a 350 Ω gauge simulates -1000 µε at a gauge
+	// factor of 2, with or without compensated lead wires.
+	testCases := []struct {
+		name   string
+		config Config
+		want   float64
+	}{
+		{"quarter", Config{Type: QuarterBridge, GaugeFactor: 2, GaugeResistance: 350}, -1000},
+		{"quarter with leads", Config{Type: QuarterBridge, GaugeFactor: 2, GaugeResistance: 350,
+			LeadResistance: 2}, -1000},
+		{"half", Config{Type: HalfBridge, GaugeFactor: 2, GaugeResistance: 350}, -500.5},
+	}
+	for _, tc := range testCases {
+		got, err := tc.config.ShuntValue(174650)
+		if err != nil {
+			t.Fatalf("%s: ShuntValue: %v", tc.name, err)
+		}
+		if math.Abs(got-tc.want) > 0.01 {
+			t.Errorf("%s: Expected %v µε, got %v", tc.name, tc.want, got)
+		}
+	}
+	if _, err := (Config{Type: QuarterBridge, GaugeFactor: 2}).ShuntValue(1000); err == nil {
+		t.Error("Expected error without the gauge resistance")
+	}
+}
+
+func TestConfigJSON(t *testing.T) {
+	var c Config
+	data := []byte(`{"type": "quarter", "gauge_factor": 2.1, "gauge_resistance": 350, "excitation_channel": 7}`)
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	seven := 7
+	want := Config{Type: QuarterBridge, GaugeFactor: 2.1, GaugeResistance: 350, ExcitationChannel: &seven}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Expected %+v, got %+v", want, c)
+	}
+	if err := json.Unmarshal([]byte(`{"type": "third"}`), &c); err == nil {
+		t.Error("Expected error for a third bridge")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config Config
+	}{
+		{"no gauge factor", Config{Type: QuarterBridge, Excitation: 5}},
+		{"no excitation", Config{Type: QuarterBridge, GaugeFactor: 2}},
+		{"no capacity", Config{Type: FullBridge, RatedOutput: 2, Excitation: 5}},
+		{"leads without gauge", Config{Type: HalfBridge, GaugeFactor: 2, Excitation: 5, LeadResistance: 1}},
+	}
+	for _, tc := range testCases {
+		if err := tc.config.Validate(); err == nil {
+			t.Errorf("%s: Expected error", tc.name)
+		}
+	}
+	if err := (Config{Type: Type(5)}).Validate(); !errors.Is(err, mccdaq.ErrNotSupported) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrNotSupported, err)
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// Converter converts the bridge channels of frames into microstrain or load.
+// Its methods may be called from any goroutine, so that a bridge can be
+// zeroed or shunt calibrated while Watch converts a stream.
+type Converter struct {
+	mu      sync.Mutex
+	configs map[int]Config
+	watcher mccdaq.Watcher
+}
+
+// New creates a Converter for the given bridge configs, keyed by channel
+// number, such as those returned by an analog input's Bridges method.
+func New(configs map[int]Config) (*Converter, error) {
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("converter needs at least one bridge")
+	}
+	c := Converter{configs: make(map[int]Config)}
+	for ch, config := range configs {
+		if err := config.Validate(); err != nil {
+			return nil, fmt.Errorf("bridge channel %d: %w", ch, err)
+		}
+		if config.ExcitationChannel != nil && *config.ExcitationChannel == ch {
+			return nil, fmt.Errorf("bridge channel %d can't measure its own excitation", ch)
+		}
+		c.configs[ch] = config
+	}
+	return &c, nil
+}
+
+// Configs returns the current config of each bridge, including any zero
+// offset and gain set since the converter was created, for saving with the
+// channel configuration.
+func (c *Converter) Configs() map[int]Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	configs := make(map[int]Config)
+	for ch, config := range c.configs {
+		configs[ch] = config
+	}
+	return configs
+}
+
+// Process returns a copy of the frame whose Volts hold the microstrain or load
+// of the bridge channels, and volts for the other channels, including the
+// excitation channels. A scan whose excitation is zero or NaN converts into
+// NaN. Process fails with mccdaq.ErrInvalidChannel if a bridge or excitation
+// channel isn't in the frame.
+func (c *Converter) Process(frame mccdaq.Frame) (mccdaq.Frame, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	converted := frame
+	converted.Volts = make([][]float64, len(frame.Volts))
+	for i := range frame.Volts {
+		converted.Volts[i] = append([]float64{}, frame.Volts[i]...)
+	}
+	for ch, config := range c.configs {
+		volts, excitation, err := config.readings(frame, ch)
+		if err != nil {
+			return frame, err
+		}
+		i := frame.Position(ch)
+		for scan, v := range volts {
+			ex := excitation(scan)
+			if ex == 0 || math.IsNaN(ex) {
+				converted.Volts[i][scan] = math.NaN()
+				continue
+			}
+			converted.Volts[i][scan] = config.Value(v, ex)
+		}
+	}
+	return converted, nil
+}
+
+// Zero sets the zero offset of each bridge, or of the given bridge channels,
+// to its mean output over the frame, which should be taken with no load.
+func (c *Converter) Zero(frame mccdaq.Frame, channels ...int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(channels) == 0 {
+		for ch := range c.configs {
+			channels = append(channels, ch)
+		}
+	}
+	offsets := make(map[int]float64)
+	for _, ch := range channels {
+		config, ok := c.configs[ch]
+		if !ok {
+			return fmt.Errorf("no bridge on channel %d: %w", ch, mccdaq.ErrInvalidChannel)
+		}
+		ratio, err := config.meanRatio(frame, ch)
+		if err != nil {
+			return err
+		}
+		offsets[ch] = ratio
+	}
+	for ch, offset := range offsets {
+		config := c.configs[ch]
+		config.ZeroOffset = offset
+		c.configs[ch] = config
+	}
+	return nil
+}
+
+// ShuntCalibrate sets the gain of the bridge on the given channel from a frame
+// taken without and a frame taken with the shunt resistor of the given
+// resistance in Ω connected, as described by ShuntValue, so that the
+// difference between them reads as the value the shunt should simulate. It
+// returns the new gain, which far from 1 suggests a wiring or configuration
+// fault.
+func (c *Converter) ShuntCalibrate(channel int, unshunted, shunted mccdaq.Frame, shunt float64) (float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	config, ok := c.configs[channel]
+	if !ok {
+		return 0, fmt.Errorf("no bridge on channel %d: %w", channel, mccdaq.ErrInvalidChannel)
+	}
+	want, err := config.ShuntValue(shunt)
+	if err != nil {
+		return 0, err
+	}
+	before, err := config.meanRatio(unshunted, channel)
+	if err != nil {
+		return 0, err
+	}
+	after, err := config.meanRatio(shunted, channel)
+	if err != nil {
+		return 0, err
+	}
+	// Compare the readings without any previous gain, relative to the
+	// unshunted reading rather than the zero offset.
+	uncalibrated := config
+	uncalibrated.Gain = 1
+	got := uncalibrated.value(after) - uncalibrated.value(before)
+	if got == 0 || math.IsNaN(got) {
+		return 0, fmt.Errorf("bridge channel %d didn't respond to the shunt", channel)
+	}
+	config.Gain = want / got
+	c.configs[channel] = config
+	return config.Gain, nil
+}
+
+// Watch converts each frame received from frames, such as a Stream's Frames,
+// and sends it on the returned channel, which is closed once frames is
+// closed, conversion fails, or the context is done, after which Err reports
+// why.
+func (c *Converter) Watch(ctx context.Context, frames <-chan mccdaq.Frame) <-chan mccdaq.Frame {
+	return c.watcher.WatchFrames(ctx, frames, c.Process)
+}
+
+// Err returns the error that stopped Watch, if any.
+func (c *Converter) Err() error {
+	return c.watcher.Err()
+}
+
+// readings returns the bridge channel's volts from the frame and a function
+// giving the excitation of each scan.
+func (c Config) readings(frame mccdaq.Frame, channel int) ([]float64, func(int) float64, error) {
+	i := frame.Position(channel)
+	if i < 0 {
+		return nil, nil, fmt.Errorf("bridge channel %d not in frame: %w", channel, mccdaq.ErrInvalidChannel)
+	}
+	if c.ExcitationChannel == nil {
+		return frame.Volts[i], func(int) float64 { return c.Excitation }, nil
+	}
+	j := frame.Position(*c.ExcitationChannel)
+	if j < 0 {
+		return nil, nil, fmt.Errorf("excitation channel %d of bridge channel %d not in frame: %w",
+			*c.ExcitationChannel, channel, mccdaq.ErrInvalidChannel)
+	}
+	return frame.Volts[i], func(scan int) float64 { return frame.Volts[j][scan] }, nil
+}
+
+// meanRatio returns the mean bridge output in V/V over the frame, skipping
+// scans without a valid reading.
+func (c Config) meanRatio(frame mccdaq.Frame, channel int) (float64, error) {
+	volts, excitation, err := c.readings(frame, channel)
+	if err != nil {
+		return 0, err
+	}
+	sum, count := 0.0, 0
+	for scan, v := range volts {
+		ratio := v / excitation(scan)
+		if !math.IsNaN(ratio) && !math.IsInf(ratio, 0) {
+			sum += ratio
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, fmt.Errorf("no readings of bridge channel %d in frame at scan %d", channel, frame.Index)
+	}
+	return sum / float64(count), nil
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package bridge
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// newFrame returns a frame of channel 0 reading the given ratios of the
+// excitation on channel 1, which varies from scan to scan.
+func newFrame(ratios ...float64) mccdaq.Frame {
+	frame := mccdaq.Frame{
+		Channels: []int{0, 1},
+		Raw:      [][]uint16{make([]uint16, len(ratios)), make([]uint16, len(ratios))},
+		Volts:    make([][]float64, 2),
+	}
+	for i, r := range ratios {
+		excitation := 5 + 0.1*float64(i%3)
+		frame.Volts[0] = append(frame.Volts[0], r*excitation)
+		frame.Volts[1] = append(frame.Volts[1], excitation)
+	}
+	return frame
+}
+
+func newConverter(t *testing.T, config Config) *Converter {
+	excitation := 1
+	config.ExcitationChannel = &excitation
+	c, err := New(map[int]Config{0: config})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c
+}
+
+func TestConverterRatiometric(t *testing.T) {
+	c := newConverter(t, Config{Type: FullBridge, GaugeFactor: 2})
+	frame := newFrame(0.001, 0.002, 0.003)
+	converted, err := c.Process(frame)
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	for scan, want := range []float64{500, 1000, 1500} {
+		if got := converted.Volts[0][scan]; math.Abs(got-want) > 1e-6 {
+			t.Errorf("Expected scan %d to read %v µε, got %v", scan, want, got)
+		}
+		if got := converted.Volts[1][scan]; got != frame.Volts[1][scan] {
+			t.Errorf("Expected the excitation channel in volts, got %v", got)
+		}
+	}
+	frame.Volts[1][1] = 0
+	converted, _ = c.Process(frame)
+	if !math.IsNaN(converted.Volts[0][1]) {
+		t.Errorf("Expected NaN without excitation, got %v", converted.Volts[0][1])
+	}
+}
+
+func TestConverterZero(t *testing.T) {
+	c := newConverter(t, Config{Type: FullBridge, GaugeFactor: 2})
+	if err := c.Zero(newFrame(1e-4, 1.2e-4, 0.8e-4)); err != nil {
+		t.Fatalf("Zero: %v", err)
+	}
+	if got := c.Configs()[0].ZeroOffset; math.Abs(got-1e-4) > 1e-12 {
+		t.Errorf("Expected zero offset 1e-4 V/V, got %v", got)
+	}
+	converted, err := c.Process(newFrame(1e-4 + 0.002))
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if got := converted.Volts[0][0]; math.Abs(got-1000) > 1e-6 {
+		t.Errorf("Expected 1000 µε, got %v", got)
+	}
+	if err := c.Zero(newFrame(0), 3); !errors.Is(err, mccdaq.ErrInvalidChannel) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrInvalidChannel, err)
+	}
+}
+
+func TestConverterShuntCalibrate(t *testing.T) {
+	config := Config{Type: HalfBridge, GaugeFactor: 2, GaugeResistance: 350}
+	c := newConverter(t, config)
+	// The conditioner's sensitivity is 2% low.
+	x := -350.0 / (350 + 174650)
+	shunt := 0.98 * x / (2 * (2 + x))
+	gain, err := c.ShuntCalibrate(0, newFrame(1e-4, 1e-4), newFrame(1e-4+shunt, 1e-4+shunt), 174650)
+	if err != nil {
+		t.Fatalf("ShuntCalibrate: %v", err)
+	}
+	if math.Abs(gain-1/0.98) > 1e-9 {
+		t.Errorf("Expected gain %v, got %v", 1/0.98, gain)
+	}
+	if got := c.Configs()[0].Gain; got != gain {
+		t.Errorf("Expected config gain %v, got %v", gain, got)
+	}
+	if _, err := c.ShuntCalibrate(0, newFrame(1e-4), newFrame(1e-4), 174650); err == nil {
+		t.Error("Expected error for a bridge that doesn't respond to the shunt")
+	}
+}
+
+func TestConverterErrors(t *testing.T) {
+	if _, err := New(nil); err == nil {
+		t.Error("Expected error without bridges")
+	}
+	self := 0
+	config := Config{Type: FullBridge, GaugeFactor: 2, ExcitationChannel: &self}
+	if _, err := New(map[int]Config{0: config}); err == nil {
+		t.Error("Expected error for a bridge measuring its own excitation")
+	}
+	missing := 5
+	config.ExcitationChannel = &missing
+	c, err := New(map[int]Config{0: config})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := c.Process(newFrame(0)); !errors.Is(err, mccdaq.ErrInvalidChannel) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrInvalidChannel, err)
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+// Package decimate oversamples and decimates analog input frames, filtering
+// each channel of a high-rate scan down to a lower output rate to reduce
+// noise and gain effective resolution.
+package decimate
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+	"github.com/gotmc/mccdaq/internal/sinc"
+)
+
+// Method selects the filter applied before decimating.
+type Method int
+
+// Available decimation methods.
+const (
+	// Average averages each block of Factor scans.
+	Average Method = iota
+	// CIC applies a cascaded integrator-comb filter of Order stages, which
+	// rejects aliases better than a plain average.
+	CIC
+	// FIR applies a Blackman-windowed sinc lowpass filter of Taps taps with
+	// its cutoff at 80% of the output Nyquist frequency.
+	FIR
+)
+
+var methods = map[Method]string{
+	Average: "average",
+	CIC:     "cic",
+	FIR:     "fir",
+}
+
+// String implements the Stringer interface for Method.
+func (m Method) String() string {
+	return methods[m]
+}
+
+// Defaults for the filters.
+const (
+	defaultOrder      = 3
+	defaultTapsFactor = 8
+	firCutoff         = 0.8
+)
+
+// Config configures the decimation of one channel. Several configs may name
+// the same channel to produce it at different output rates.
+type Config struct {
+	Channel int    // Channel number to decimate
+	Factor  int    // Number of input scans per output sample
+	Method  Method // Filter applied before decimating
+	// Order is the number of stages of a CIC filter, and is 3 if zero.
+	Order int
+	// Taps is the length of a FIR filter, and is 8*Factor+1 if zero.
+	Taps int
+}
+
+// coefficients returns the filter's impulse response, normalized to unity
+// gain at DC.
+func (c Config) coefficients() ([]float64, error) {
+	if c.Factor < 1 {
+		return nil, fmt.Errorf("bad decimation factor %d for channel %d", c.Factor, c.Channel)
+	}
+	var h []float64
+	switch c.Method {
+	case Average:
+		h = make([]float64, c.Factor)
+		for i := range h {
+			h[i] = 1
+		}
+	case CIC:
+		// A CIC filter's response is a boxcar of Factor scans convolved with
+		// itself once per stage.
+		order := c.Order
+		if order == 0 {
+			order = defaultOrder
+		}
+		if order < 1 {
+			return nil, fmt.Errorf("bad CIC order %d for channel %d", c.Order, c.Channel)
+		}
+		h = []float64{1}
+		for stage := 0; stage < order; stage++ {
+			next := make([]float64, len(h)+c.Factor-1)
+			for i, v := range h {
+				for j := 0; j < c.Factor; j++ {
+					next[i+j] += v
+				}
+			}
+			h = next
+		}
+	case FIR:
+		taps := c.Taps
+		if taps == 0 {
+			taps = defaultTapsFactor*c.Factor + 1
+		}
+		if taps < 1 {
+			return nil, fmt.Errorf("bad FIR length %d for channel %d", c.Taps, c.Channel)
+		}
+		// Cutoff in cycles per input scan.
+		fc := firCutoff * 0.5 / float64(c.Factor)
+		h = sinc.Blackman(taps, fc)
+	default:
+		return nil, fmt.Errorf("bad decimation method %d for channel %d: %w",
+			c.Method, c.Channel, mccdaq.ErrNotSupported)
+	}
+	sum := 0.0
+	for _, v := range h {
+		sum += v
+	}
+	for i := range h {
+		h[i] /= sum
+	}
+	return h, nil
+}
+
+// BitsGained returns the number of effective bits gained by the filter, from
+// how much it reduces the power of white noise, which is half a bit for each
+// doubling of the number of scans averaged. The gain is only realized if the
+// input noise is at least about one code, so that it dithers the ADC.
+func (c Config) BitsGained() (float64, error) {
+	h, err := c.coefficients()
+	if err != nil {
+		return 0, err
+	}
+	power := 0.0
+	for _, v := range h {
+		power += v * v
+	}
+	return -0.5 * math.Log2(power), nil
+}
+
+// Samples is a block of consecutive decimated samples of one channel.
+// Timebase gives the time of each output sample, accounting for the delay of
+// the filter, and Index is the index of the first output sample since the
+// scan started.
+type Samples struct {
+	Channel  int
+	Index    uint64
+	Timebase mccdaq.Timebase
+	Volts    []float64
+}
+
+// Time returns the time of the given sample in the block, counting from zero
+// for the first sample in the block.
+func (s Samples) Time(i int) time.Time {
+	return s.Timebase.Time(s.Index + uint64(i))
+}
+
+// Decimator decimates frames of scans according to its configs. Output
+// sample j of a config filters the input scans from j*Factor onward, so the
+// outputs stay on the same grid across frames and gaps.
+type Decimator struct {
+	stages  []*stage
+	watcher mccdaq.Watcher
+}
+
+// stage holds the state of one config. history holds the input scans not yet
+// consumed, the first of which has index first, and next is the index of the
+// next output sample.
+type stage struct {
+	config  Config
+	h       []float64
+	history []float64
+	first   uint64
+	next    uint64
+	started bool
+	nextIn  uint64
+}
+
+// New creates a Decimator with the given configs.
+func New(configs ...Config) (*Decimator, error) {
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("decimator needs at least one config")
+	}
+	d := Decimator{}
+	for _, c := range configs {
+		h, err := c.coefficients()
+		if err != nil {
+			return nil, err
+		}
+		d.stages = append(d.stages, &stage{config: c, h: h})
+	}
+	return &d, nil
+}
+
+// Process decimates the frame, which must follow on from the previous frame,
+// and returns the samples it completes for each config, in the order of the
+// configs. If scans were lost before the frame, each filter starts afresh at
+// the next output sample after the gap. Process fails with
+// mccdaq.ErrInvalidChannel if a configured channel isn't in the frame.
+func (d *Decimator) Process(frame mccdaq.Frame) ([]Samples, error) {
+	out := make([]Samples, len(d.stages))
+	for i, st := range d.stages {
+		position := -1
+		for j, ch := range frame.Channels {
+			if ch == st.config.Channel {
+				position = j
+			}
+		}
+		if position < 0 {
+			return nil, fmt.Errorf("decimated channel %d not in frame: %w",
+				st.config.Channel, mccdaq.ErrInvalidChannel)
+		}
+		out[i] = st.process(frame, frame.Volts[position])
+	}
+	return out, nil
+}
+
+// Watch decimates each frame received from frames, such as a Stream's Frames,
+// and sends the samples for each config, in the order of the configs, on the
+// returned channel, skipping configs with no new samples. The channel is
+// closed once frames is closed, processing fails, or the context is done,
+// after which Err reports why.
+func (d *Decimator) Watch(ctx context.Context, frames <-chan mccdaq.Frame) <-chan Samples {
+	samples := make(chan Samples)
+	d.watcher.Watch(ctx, frames, func(frame mccdaq.Frame) error {
+		completed, err := d.Process(frame)
+		if err != nil {
+			return err
+		}
+		for _, s := range completed {
+			if len(s.Volts) == 0 {
+				continue
+			}
+			select {
+			case samples <- s:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	}, func() { close(samples) })
+	return samples
+}
+
+// Err returns the error that stopped Watch, if any.
+func (d *Decimator) Err() error {
+	return d.watcher.Err()
+}
+
+// process adds the channel's volts from the frame to the history and returns
+// the output samples that are now complete.
+func (st *stage) process(frame mccdaq.Frame, volts []float64) Samples {
+	factor := uint64(st.config.Factor)
+	if !st.started || frame.Index != st.nextIn || frame.LostScans > 0 {
+		// Start afresh at the first output sample at or after the frame.
+		st.next = (frame.Index + factor - 1) / factor
+		st.first = frame.Index
+		st.history = st.history[:0]
+		st.started = true
+	}
+	st.nextIn = frame.Index + uint64(len(volts))
+	st.history = append(st.history, volts...)
+	period := frame.Timebase.Period
+	delay := time.Duration(float64(len(st.h)-1) / 2 * float64(period))
+	samples := Samples{
+		Channel: st.config.Channel,
+		Index:   st.next,
+		Timebase: mccdaq.Timebase{
+			Anchor: frame.Timebase.Anchor.Add(delay),
+			Period: time.Duration(factor) * period,
+			Drift:  frame.Timebase.Drift,
+		},
+	}
+	for {
+		offset := int(st.next*factor - st.first)
+		if offset+len(st.h) > len(st.history) {
+			break
+		}
+		sum := 0.0
+		for i, c := range st.h {
+			sum += c * st.history[offset+i]
+		}
+		samples.Volts = append(samples.Volts, sum)
+		st.next++
+	}
+	// Discard the scans before the next output sample's window.
+	if start := st.next * factor; start > st.first {
+		drop := int(start - st.first)
+		if drop > len(st.history) {
+			drop = len(st.history)
+		}
+		st.history = append(st.history[:0], st.history[drop:]...)
+		st.first += uint64(drop)
+	}
+	return samples
+}
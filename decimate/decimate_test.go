@@ -0,0 +1,271 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package decimate
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// newFrames splits the volts of channel 3, scanned every millisecond, into
+// frames of the given sizes, repeating the last size as needed.
+func newFrames(volts []float64, sizes ...int) []mccdaq.Frame {
+	tb := mccdaq.Timebase{Anchor: time.Unix(0, 0), Period: time.Millisecond}
+	var frames []mccdaq.Frame
+	for first, i := 0, 0; first < len(volts); i++ {
+		size := sizes[len(sizes)-1]
+		if i < len(sizes) {
+			size = sizes[i]
+		}
+		last := first + size
+		if last > len(volts) {
+			last = len(volts)
+		}
+		frames = append(frames, mccdaq.Frame{
+			Index:    uint64(first),
+			Timebase: tb,
+			Channels: []int{3},
+			Raw:      [][]uint16{make([]uint16, last-first)},
+			Volts:    [][]float64{volts[first:last]},
+		})
+		first = last
+	}
+	return frames
+}
+
+// decimateAll processes the frames and returns every output sample for each
+// config, along with the samples from the first frame that completed any.
+func decimateAll(t *testing.T, d *Decimator, frames []mccdaq.Frame) ([][]float64, []Samples) {
+	var all [][]float64
+	var firsts []Samples
+	for _, frame := range frames {
+		samples, err := d.Process(frame)
+		if err != nil {
+			t.Fatalf("Process: %v", err)
+		}
+		if all == nil {
+			all = make([][]float64, len(samples))
+			firsts = make([]Samples, len(samples))
+		}
+		for i, s := range samples {
+			if len(all[i]) == 0 && len(s.Volts) > 0 {
+				firsts[i] = s
+			}
+			all[i] = append(all[i], s.Volts...)
+		}
+	}
+	return all, firsts
+}
+
+func approxEqual(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func ramp(n int) []float64 {
+	volts := make([]float64, n)
+	for i := range volts {
+		volts[i] = float64(i)
+	}
+	return volts
+}
+
+func TestDecimateAverage(t *testing.T) {
+	d, err := New(Config{Channel: 3, Factor: 4})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	all, firsts := decimateAll(t, d, newFrames(ramp(18), 3, 5, 2))
+	want := []float64{1.5, 5.5, 9.5, 13.5}
+	if !reflect.DeepEqual(all[0], want) {
+		t.Errorf("Expected %v, got %v", want, all[0])
+	}
+	first := firsts[0]
+	if first.Channel != 3 || first.Index != 0 {
+		t.Errorf("Expected channel 3 from index 0, got channel %d from index %d",
+			first.Channel, first.Index)
+	}
+	if first.Timebase.Period != 4*time.Millisecond {
+		t.Errorf("Expected 4 ms period, got %v", first.Timebase.Period)
+	}
+	// Each average is centered on the middle of its scans.
+	if got := first.Time(1).Sub(time.Unix(0, 0)); got != 5500*time.Microsecond {
+		t.Errorf("Expected second sample at 5.5 ms, got %v", got)
+	}
+}
+
+func TestDecimateSeveralRates(t *testing.T) {
+	d, err := New(
+		Config{Channel: 3, Factor: 2},
+		Config{Channel: 3, Factor: 5},
+		Config{Channel: 3, Factor: 2, Method: CIC, Order: 2},
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	all, _ := decimateAll(t, d, newFrames(ramp(10), 4))
+	want := [][]float64{
+		{0.5, 2.5, 4.5, 6.5, 8.5},
+		{2, 7},
+		// The response [1 2 1]/4 of a second order CIC filter centers each
+		// output on the scan after the first.
+		{1, 3, 5, 7},
+	}
+	for i := range want {
+		if !approxEqual(all[i], want[i]) {
+			t.Errorf("Expected %v for config %d, got %v", want[i], i, all[i])
+		}
+	}
+}
+
+func TestDecimateGap(t *testing.T) {
+	d, err := New(Config{Channel: 3, Factor: 4})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	frames := newFrames(ramp(24), 6)
+	// Lose scans 6 to 11, so the outputs from 4 to 11 are incomplete.
+	frames = append(frames[:1], frames[2:]...)
+	all, _ := decimateAll(t, d, frames)
+	want := []float64{1.5, 13.5, 17.5, 21.5}
+	if !reflect.DeepEqual(all[0], want) {
+		t.Errorf("Expected %v, got %v", want, all[0])
+	}
+}
+
+func TestDecimateFIR(t *testing.T) {
+	const factor = 10
+	c := Config{Channel: 3, Factor: factor, Method: FIR}
+	d, err := New(c)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	// A DC level passes unchanged, but a tone above the output Nyquist
+	// frequency is rejected.
+	volts := make([]float64, 5000)
+	for i := range volts {
+		volts[i] = 1 + math.Sin(2*math.Pi*0.2*float64(i))
+	}
+	all, _ := decimateAll(t, d, newFrames(volts, 256))
+	if len(all[0]) != (5000-(8*factor+1))/factor+1 {
+		t.Errorf("Expected %d samples, got %d", (5000-(8*factor+1))/factor+1, len(all[0]))
+	}
+	for i, v := range all[0] {
+		if math.Abs(v-1) > 1e-3 {
+			t.Fatalf("Expected sample %d to be 1, got %v", i, v)
+		}
+	}
+}
+
+func TestBitsGained(t *testing.T) {
+	testCases := []struct {
+		config Config
+		bits   float64
+	}{
+		{Config{Factor: 1}, 0},
+		{Config{Factor: 4}, 1},
+		{Config{Factor: 256}, 4},
+		{Config{Factor: 2, Method: CIC, Order: 2}, -0.5 * math.Log2(6.0/16)},
+	}
+	for _, tc := range testCases {
+		bits, err := tc.config.BitsGained()
+		if err != nil {
+			t.Fatalf("BitsGained: %v", err)
+		}
+		if math.Abs(bits-tc.bits) > 1e-12 {
+			t.Errorf("Expected %v bits gained for %+v, got %v", tc.bits, tc.config, bits)
+		}
+	}
+}
+
+func TestBitsGainedNoise(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	volts := make([]float64, 1<<16)
+	for i := range volts {
+		volts[i] = rng.NormFloat64()
+	}
+	for _, c := range []Config{
+		{Channel: 3, Factor: 64},
+		{Channel: 3, Factor: 16, Method: CIC},
+		{Channel: 3, Factor: 16, Method: FIR},
+	} {
+		d, err := New(c)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		all, _ := decimateAll(t, d, newFrames(volts, 4096))
+		power := 0.0
+		for _, v := range all[0] {
+			power += v * v
+		}
+		measured := -0.5 * math.Log2(power/float64(len(all[0])))
+		bits, _ := c.BitsGained()
+		if math.Abs(measured-bits) > 0.15 {
+			t.Errorf("Expected about %.2f bits gained by %s, measured %.2f",
+				bits, c.Method, measured)
+		}
+	}
+}
+
+func TestDecimateErrors(t *testing.T) {
+	for _, c := range []Config{
+		{Factor: 0},
+		{Factor: 2, Method: CIC, Order: -1},
+		{Factor: 2, Method: FIR, Taps: -1},
+	} {
+		if _, err := New(c); err == nil {
+			t.Errorf("Expected error for config %+v", c)
+		}
+	}
+	if _, err := New(Config{Factor: 2, Method: Method(9)}); !errors.Is(err, mccdaq.ErrNotSupported) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrNotSupported, err)
+	}
+	d, err := New(Config{Channel: 5, Factor: 2})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := d.Process(newFrames(ramp(4), 4)[0]); !errors.Is(err, mccdaq.ErrInvalidChannel) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrInvalidChannel, err)
+	}
+}
+
+func TestDecimateWatch(t *testing.T) {
+	d, err := New(Config{Channel: 3, Factor: 4})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	frames := make(chan mccdaq.Frame)
+	go func() {
+		defer close(frames)
+		for _, frame := range newFrames(ramp(16), 2) {
+			frames <- frame
+		}
+	}()
+	var volts []float64
+	for s := range d.Watch(context.Background(), frames) {
+		volts = append(volts, s.Volts...)
+	}
+	if want := []float64{1.5, 5.5, 9.5, 13.5}; !reflect.DeepEqual(volts, want) {
+		t.Errorf("Expected %v, got %v", want, volts)
+	}
+	if err := d.Err(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package mccdaq
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors returned by the device packages. The device packages wrap
+// these errors along with the underlying cause, so use errors.Is to test for
+// them instead of matching the error string.
+var (
+	ErrDeviceNotFound   = errors.New("device not found")
+	ErrOverrun          = errors.New("analog input scan overrun")
+	ErrTimeout          = errors.New("USB transfer timed out")
+	ErrStall            = errors.New("USB endpoint stalled")
+	ErrInvalidChannel   = errors.New("invalid channel")
+	ErrInvalidRange     = errors.New("invalid voltage range")
+	ErrNotSupported     = errors.New("operation not supported")
+	ErrBusy             = errors.New("bulk endpoint in use by another reader")
+	ErrScanRunning      = errors.New("analog input scan running")
+	ErrDisconnected     = errors.New("device disconnected")
+	ErrClosed           = errors.New("device closed")
+	ErrInvalidFrequency = errors.New("invalid scan frequency")
+)
+
+// OverrunError is returned when the DAQ reports that an analog input scan
+// overran its FIFO buffer. BytesRead is the number of bytes of valid data
+// that were read before the overrun was detected. OverrunError matches
+// ErrOverrun when using errors.Is.
+//
+// If the scan was restarted after the overrun, Restarted is true and the
+// OverrunError marks a gap in the data rather than the end of the scan: Scan
+// is the index, since the scan started, of the first missing scan, and
+// LostScans is the number of scans missing, estimated from the time the scan
+// was stopped.
+type OverrunError struct {
+	BytesRead int
+	Restarted bool
+	Scan      uint64
+	LostScans uint64
+}
+
+// Error implements the error interface for OverrunError.
+func (e *OverrunError) Error() string {
+	if e.Restarted {
+		return fmt.Sprintf("%s after reading %d bytes; restarted after losing about %d scans at scan %d",
+			ErrOverrun, e.BytesRead, e.LostScans, e.Scan)
+	}
+	return fmt.Sprintf("%s after reading %d bytes", ErrOverrun, e.BytesRead)
+}
+
+// Unwrap returns ErrOverrun, so that errors.Is(err, ErrOverrun) reports true
+// for an OverrunError.
+func (e *OverrunError) Unwrap() error {
+	return ErrOverrun
+}
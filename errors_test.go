@@ -0,0 +1,42 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package mccdaq
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestOverrunError(t *testing.T) {
+	err := fmt.Errorf("reading scan: %w", &OverrunError{BytesRead: 128})
+	if !errors.Is(err, ErrOverrun) {
+		t.Errorf("Expected errors.Is(%v, ErrOverrun) to be true", err)
+	}
+	var overrun *OverrunError
+	if !errors.As(err, &overrun) {
+		t.Fatalf("Expected errors.As(%v, *OverrunError) to be true", err)
+	}
+	if overrun.BytesRead != 128 {
+		t.Errorf("Expected 128 bytes read, got %d", overrun.BytesRead)
+	}
+	expected := "reading scan: analog input scan overrun after reading 128 bytes"
+	if err.Error() != expected {
+		t.Errorf("Expected `%s`, got `%s`", expected, err)
+	}
+}
+
+func TestOverrunErrorRestarted(t *testing.T) {
+	err := &OverrunError{BytesRead: 64, Restarted: true, Scan: 1000, LostScans: 250}
+	if !errors.Is(err, ErrOverrun) {
+		t.Errorf("Expected errors.Is(%v, ErrOverrun) to be true", err)
+	}
+	expected := "analog input scan overrun after reading 64 bytes; " +
+		"restarted after losing about 250 scans at scan 1000"
+	if err.Error() != expected {
+		t.Errorf("Expected `%s`, got `%s`", expected, err)
+	}
+}
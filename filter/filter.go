@@ -0,0 +1,154 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+// Package filter provides digital filters for acquired analog input channels,
+// which keep their state between blocks of samples so that filtering a scan
+// read in pieces gives the same result as filtering it all at once. Filters
+// are designed from a cutoff frequency and the sample rate, which is the scan
+// rate of the analog input, best taken from the Rate of its ScanPlan since
+// that's the rate the pacer actually achieves.
+package filter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// Filter filters a sequence of samples one at a time.
+type Filter interface {
+	// Filter returns the filtered value of the next sample.
+	Filter(x float64) float64
+	// Reset forgets the previous samples, such as after a gap in the data.
+	Reset()
+}
+
+// Apply filters the samples in place.
+func Apply(f Filter, samples []float64) {
+	for i, x := range samples {
+		samples[i] = f.Filter(x)
+	}
+}
+
+// Chain applies its filters in turn.
+type Chain []Filter
+
+// Filter implements the Filter interface for Chain.
+func (c Chain) Filter(x float64) float64 {
+	for _, f := range c {
+		x = f.Filter(x)
+	}
+	return x
+}
+
+// Reset implements the Filter interface for Chain.
+func (c Chain) Reset() {
+	for _, f := range c {
+		f.Reset()
+	}
+}
+
+// Bank holds a filter for each of several channels, to filter the channels of
+// a stream of frames or of consecutive Voltages output.
+type Bank struct {
+	filters map[int]Filter
+	started bool
+	next    uint64
+}
+
+// NewBank creates a Bank with no filters.
+func NewBank() *Bank {
+	return &Bank{filters: make(map[int]Filter)}
+}
+
+// Set attaches the filters, applied in turn, to the given channel number,
+// replacing any filters already attached. Each filter keeps state, so don't
+// attach the same filter to more than one channel.
+func (b *Bank) Set(channel int, filters ...Filter) error {
+	if len(filters) == 0 {
+		return fmt.Errorf("no filters for channel %d", channel)
+	}
+	if channel < 0 {
+		return fmt.Errorf("bad channel %d: %w", channel, mccdaq.ErrInvalidChannel)
+	}
+	if len(filters) == 1 {
+		b.filters[channel] = filters[0]
+	} else {
+		b.filters[channel] = Chain(filters)
+	}
+	return nil
+}
+
+// Remove detaches the filters from the given channel number.
+func (b *Bank) Remove(channel int) {
+	delete(b.filters, channel)
+}
+
+// Reset resets every filter.
+func (b *Bank) Reset() {
+	for _, f := range b.filters {
+		f.Reset()
+	}
+}
+
+// Process returns a copy of the frame with the voltages of each channel that
+// has filters replaced by their filtered values, leaving the raw codes
+// unchanged. The frame must follow on from the previous frame; if scans were
+// lost before it, the filters are reset first.
+func (b *Bank) Process(frame mccdaq.Frame) mccdaq.Frame {
+	if b.started && (frame.Index != b.next || frame.LostScans > 0) {
+		b.Reset()
+	}
+	b.started = true
+	b.next = frame.Index + uint64(frame.NumScans())
+	filtered := frame
+	filtered.Volts = make([][]float64, len(frame.Volts))
+	copy(filtered.Volts, frame.Volts)
+	for i, ch := range frame.Channels {
+		f, ok := b.filters[ch]
+		if !ok {
+			continue
+		}
+		volts := make([]float64, len(frame.Volts[i]))
+		for scan, v := range frame.Volts[i] {
+			volts[scan] = f.Filter(v)
+		}
+		filtered.Volts[i] = volts
+	}
+	return filtered
+}
+
+// ApplyVoltages filters in place the voltages returned by Voltages or
+// RawVoltages, which are indexed by channel number, for each channel that has
+// filters. Successive calls carry on from the previous voltages, so call
+// ApplyVoltages on each Read's voltages in turn.
+func (b *Bank) ApplyVoltages(volts [][]float64) {
+	for ch, f := range b.filters {
+		if ch < len(volts) {
+			Apply(f, volts[ch])
+		}
+	}
+}
+
+// Watch filters each frame received from frames, such as a Stream's Frames,
+// and sends the filtered frames on the returned channel, which is closed once
+// frames is closed or the context is done.
+func (b *Bank) Watch(ctx context.Context, frames <-chan mccdaq.Frame) <-chan mccdaq.Frame {
+	var watcher mccdaq.Watcher
+	return watcher.WatchFrames(ctx, frames, func(frame mccdaq.Frame) (mccdaq.Frame, error) {
+		return b.Process(frame), nil
+	})
+}
+
+// checkCutoff checks that the frequency lies strictly between zero and the
+// Nyquist frequency of the sample rate.
+func checkCutoff(name string, freq, rate float64) error {
+	if !(rate > 0) || !(freq > 0) || !(freq < rate/2) {
+		return fmt.Errorf("%s %g Hz must be between 0 and half the sample rate %g Hz: %w",
+			name, freq, rate, mccdaq.ErrInvalidFrequency)
+	}
+	return nil
+}
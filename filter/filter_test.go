@@ -0,0 +1,185 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package filter
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gotmc/mccdaq"
+)
+
+const rate = 1000.0
+
+func noise(n int) []float64 {
+	rng := rand.New(rand.NewSource(1))
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = rng.NormFloat64()
+	}
+	return x
+}
+
+func TestFiltersContinueAcrossBlocks(t *testing.T) {
+	newFilters := func() map[string]Filter {
+		lowpass, _ := Lowpass(5, 50, rate)
+		notch, _ := Notch(60, 10, rate)
+		fir, _ := LowpassFIR(31, 100, rate)
+		average, _ := NewMovingAverage(7)
+		median, _ := NewMedian(6)
+		return map[string]Filter{
+			"lowpass": lowpass,
+			"notch":   notch,
+			"fir":     fir,
+			"average": average,
+			"median":  median,
+			"chain":   Chain{lowpass, median},
+		}
+	}
+	x := noise(1000)
+	whole := newFilters()
+	pieces := newFilters()
+	for name, f := range whole {
+		want := append([]float64{}, x...)
+		Apply(f, want)
+		got := append([]float64{}, x...)
+		for first := 0; first < len(got); first += 37 {
+			last := first + 37
+			if last > len(got) {
+				last = len(got)
+			}
+			Apply(pieces[name], got[first:last])
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %s filter in blocks to match filtering at once", name)
+		}
+	}
+}
+
+func TestBank(t *testing.T) {
+	b := NewBank()
+	average, _ := NewMovingAverage(2)
+	if err := b.Set(2, average); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	frame := mccdaq.Frame{
+		Index:    0,
+		Channels: []int{0, 2},
+		Raw:      [][]uint16{{1, 2, 3}, {4, 5, 6}},
+		Volts:    [][]float64{{1, 2, 3}, {4, 6, 8}},
+	}
+	filtered := b.Process(frame)
+	if want := [][]float64{{1, 2, 3}, {4, 5, 7}}; !reflect.DeepEqual(filtered.Volts, want) {
+		t.Errorf("Expected %v, got %v", want, filtered.Volts)
+	}
+	if frame.Volts[1][1] != 6 {
+		t.Errorf("Expected the original frame unchanged")
+	}
+	// The next frame carries on from the last, but a gap resets the filter.
+	frame.Index = 3
+	frame.Volts = [][]float64{{0, 0, 0}, {10, 10, 10}}
+	if got := b.Process(frame).Volts[1]; !reflect.DeepEqual(got, []float64{9, 10, 10}) {
+		t.Errorf("Expected [9 10 10], got %v", got)
+	}
+	frame.Index = 10
+	frame.Volts = [][]float64{{0, 0, 0}, {2, 4, 6}}
+	if got := b.Process(frame).Volts[1]; !reflect.DeepEqual(got, []float64{2, 3, 5}) {
+		t.Errorf("Expected [2 3 5] after a gap, got %v", got)
+	}
+}
+
+func TestBankApplyVoltages(t *testing.T) {
+	b := NewBank()
+	average, _ := NewMovingAverage(2)
+	median, _ := NewMedian(3)
+	if err := b.Set(1, median, average); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	volts := [][]float64{{1, 2}, {0, 9, 0}, nil}
+	b.ApplyVoltages(volts)
+	want := [][]float64{{1, 2}, {0, 2.25, 2.25}, nil}
+	if !reflect.DeepEqual(volts, want) {
+		t.Errorf("Expected %v, got %v", want, volts)
+	}
+	volts = [][]float64{nil, {4}}
+	b.ApplyVoltages(volts)
+	// The median of 9, 0, and 4 averaged with the previous median of 0.
+	if volts[1][0] != 2 {
+		t.Errorf("Expected filtering to carry on from the last call, got %v", volts[1][0])
+	}
+	b.Remove(1)
+	volts = [][]float64{nil, {4}}
+	b.ApplyVoltages(volts)
+	if volts[1][0] != 4 {
+		t.Errorf("Expected no filtering once removed, got %v", volts[1][0])
+	}
+	if err := b.Set(-1, average); !errors.Is(err, mccdaq.ErrInvalidChannel) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrInvalidChannel, err)
+	}
+}
+
+func TestBankWatch(t *testing.T) {
+	b := NewBank()
+	average, _ := NewMovingAverage(2)
+	b.Set(0, average)
+	frames := make(chan mccdaq.Frame)
+	go func() {
+		defer close(frames)
+		for i := 0; i < 2; i++ {
+			frames <- mccdaq.Frame{
+				Index:    uint64(2 * i),
+				Channels: []int{0},
+				Raw:      [][]uint16{{0, 0}},
+				Volts:    [][]float64{{float64(2 * i), float64(2*i + 1)}},
+			}
+		}
+	}()
+	var got []float64
+	for frame := range b.Watch(context.Background(), frames) {
+		got = append(got, frame.Volts[0]...)
+	}
+	if want := []float64{0, 0.5, 1.5, 2.5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestMovingAverage(t *testing.T) {
+	m, err := NewMovingAverage(3)
+	if err != nil {
+		t.Fatalf("NewMovingAverage: %v", err)
+	}
+	x := []float64{3, 6, 9, 12, 0, 0, 0}
+	Apply(m, x)
+	if want := []float64{3, 4.5, 6, 9, 7, 4, 0}; !reflect.DeepEqual(x, want) {
+		t.Errorf("Expected %v, got %v", want, x)
+	}
+	if _, err := NewMovingAverage(0); err == nil {
+		t.Error("Expected error for an empty moving average")
+	}
+}
+
+func TestMedian(t *testing.T) {
+	m, err := NewMedian(3)
+	if err != nil {
+		t.Fatalf("NewMedian: %v", err)
+	}
+	// The spike is removed but the step is kept.
+	x := []float64{1, 1, 50, 1, 1, 5, 5, 5}
+	Apply(m, x)
+	if want := []float64{1, 1, 1, 1, 1, 1, 5, 5}; !reflect.DeepEqual(x, want) {
+		t.Errorf("Expected %v, got %v", want, x)
+	}
+	m.Reset()
+	if got := m.Filter(7); got != 7 {
+		t.Errorf("Expected 7 after reset, got %v", got)
+	}
+	if _, err := NewMedian(0); err == nil {
+		t.Error("Expected error for an empty median filter")
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package filter
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gotmc/mccdaq/internal/sinc"
+)
+
+// FIR is a finite impulse response filter, whose output is the sum of the
+// latest samples weighted by its taps, the first tap weighting the latest
+// sample.
+type FIR struct {
+	taps    []float64
+	history []float64
+	head    int
+}
+
+// NewFIR creates a FIR filter with the given taps.
+func NewFIR(taps []float64) (*FIR, error) {
+	if len(taps) == 0 {
+		return nil, fmt.Errorf("FIR filter needs at least one tap")
+	}
+	f := FIR{
+		taps:    append([]float64{}, taps...),
+		history: make([]float64, len(taps)),
+	}
+	return &f, nil
+}
+
+// LowpassFIR designs a linear phase lowpass FIR filter with the given odd
+// number of taps, using a Blackman-windowed sinc with its cutoff at the given
+// frequency for the sample rate in Hz. The filter delays the signal by half
+// the number of taps less one.
+func LowpassFIR(taps int, cutoff, rate float64) (*FIR, error) {
+	h, err := windowedSinc(taps, cutoff, rate)
+	if err != nil {
+		return nil, err
+	}
+	return NewFIR(h)
+}
+
+// HighpassFIR designs a linear phase highpass FIR filter like LowpassFIR, by
+// subtracting the lowpass filter from the signal.
+func HighpassFIR(taps int, cutoff, rate float64) (*FIR, error) {
+	h, err := windowedSinc(taps, cutoff, rate)
+	if err != nil {
+		return nil, err
+	}
+	for i := range h {
+		h[i] = -h[i]
+	}
+	h[taps/2]++
+	return NewFIR(h)
+}
+
+// windowedSinc returns the taps of a Blackman-windowed sinc lowpass filter
+// with unity gain at DC.
+func windowedSinc(taps int, cutoff, rate float64) ([]float64, error) {
+	if taps < 1 || taps%2 == 0 {
+		return nil, fmt.Errorf("FIR filter needs an odd number of taps, not %d", taps)
+	}
+	if err := checkCutoff("cutoff", cutoff, rate); err != nil {
+		return nil, err
+	}
+	return sinc.Blackman(taps, cutoff/rate), nil
+}
+
+// Taps returns a copy of the filter's taps.
+func (f *FIR) Taps() []float64 {
+	return append([]float64{}, f.taps...)
+}
+
+// Filter implements the Filter interface for FIR.
+func (f *FIR) Filter(x float64) float64 {
+	n := len(f.taps)
+	f.head = (f.head + n - 1) % n
+	f.history[f.head] = x
+	y := 0.0
+	for i, tap := range f.taps {
+		y += tap * f.history[(f.head+i)%n]
+	}
+	return y
+}
+
+// Reset implements the Filter interface for FIR.
+func (f *FIR) Reset() {
+	for i := range f.history {
+		f.history[i] = 0
+	}
+	f.head = 0
+}
+
+// MovingAverage averages the latest samples, up to its length. Until it has
+// seen that many samples, it averages the samples so far.
+type MovingAverage struct {
+	window []float64
+	head   int
+	count  int
+	sum    float64
+}
+
+// NewMovingAverage creates a moving average of the given number of samples.
+func NewMovingAverage(n int) (*MovingAverage, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("bad moving average length %d", n)
+	}
+	return &MovingAverage{window: make([]float64, n)}, nil
+}
+
+// Filter implements the Filter interface for MovingAverage.
+func (m *MovingAverage) Filter(x float64) float64 {
+	n := len(m.window)
+	if m.count == n {
+		m.sum -= m.window[m.head]
+	} else {
+		m.count++
+	}
+	m.window[m.head] = x
+	m.sum += x
+	m.head = (m.head + 1) % n
+	if m.head == 0 {
+		// Recompute the sum once per window so rounding errors don't build
+		// up.
+		m.sum = 0
+		for _, v := range m.window[:m.count] {
+			m.sum += v
+		}
+	}
+	return m.sum / float64(m.count)
+}
+
+// Reset implements the Filter interface for MovingAverage.
+func (m *MovingAverage) Reset() {
+	m.head, m.count, m.sum = 0, 0, 0
+}
+
+// Median outputs the median of the latest samples, up to its length, which
+// removes impulsive noise such as spikes while preserving steps. Until it has
+// seen that many samples, it outputs the median of the samples so far. The
+// median of an even number of samples is the mean of the middle two.
+type Median struct {
+	window []float64
+	sorted []float64
+	head   int
+	count  int
+}
+
+// NewMedian creates a median filter of the given number of samples.
+func NewMedian(n int) (*Median, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("bad median filter length %d", n)
+	}
+	m := Median{
+		window: make([]float64, n),
+		sorted: make([]float64, 0, n),
+	}
+	return &m, nil
+}
+
+// Filter implements the Filter interface for Median.
+func (m *Median) Filter(x float64) float64 {
+	n := len(m.window)
+	if m.count == n {
+		old := m.window[m.head]
+		i := sort.SearchFloat64s(m.sorted, old)
+		m.sorted = append(m.sorted[:i], m.sorted[i+1:]...)
+	} else {
+		m.count++
+	}
+	m.window[m.head] = x
+	m.head = (m.head + 1) % n
+	i := sort.SearchFloat64s(m.sorted, x)
+	m.sorted = append(m.sorted, 0)
+	copy(m.sorted[i+1:], m.sorted[i:])
+	m.sorted[i] = x
+	mid := len(m.sorted) / 2
+	if len(m.sorted)%2 == 1 {
+		return m.sorted[mid]
+	}
+	return (m.sorted[mid-1] + m.sorted[mid]) / 2
+}
+
+// Reset implements the Filter interface for Median.
+func (m *Median) Reset() {
+	m.head, m.count = 0, 0
+	m.sorted = m.sorted[:0]
+}
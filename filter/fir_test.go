@@ -0,0 +1,70 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package filter
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// firGain returns the magnitude of the FIR filter's response at the given
+// frequency.
+func firGain(f *FIR, freq float64) float64 {
+	var re, im float64
+	for i, tap := range f.Taps() {
+		w := 2 * math.Pi * freq / rate * float64(i)
+		re += tap * math.Cos(w)
+		im -= tap * math.Sin(w)
+	}
+	return math.Hypot(re, im)
+}
+
+func TestFIR(t *testing.T) {
+	f, err := NewFIR([]float64{0.5, 0.25, 0.25})
+	if err != nil {
+		t.Fatalf("NewFIR: %v", err)
+	}
+	x := []float64{4, 0, 0, 0, 8}
+	Apply(f, x)
+	if want := []float64{2, 1, 1, 0, 4}; !reflect.DeepEqual(x, want) {
+		t.Errorf("Expected %v, got %v", want, x)
+	}
+	if _, err := NewFIR(nil); err == nil {
+		t.Error("Expected error for no taps")
+	}
+}
+
+func TestDesignFIR(t *testing.T) {
+	lowpass, err := LowpassFIR(101, 100, rate)
+	if err != nil {
+		t.Fatalf("LowpassFIR: %v", err)
+	}
+	highpass, err := HighpassFIR(101, 100, rate)
+	if err != nil {
+		t.Fatalf("HighpassFIR: %v", err)
+	}
+	testCases := []struct {
+		freq     float64
+		lowpass  float64
+		highpass float64
+	}{
+		{0, 1, 0},
+		{100, 0.5, 0.5},
+		{300, 0, 1},
+	}
+	for _, tc := range testCases {
+		if got := firGain(lowpass, tc.freq); math.Abs(got-tc.lowpass) > 0.01 {
+			t.Errorf("Expected lowpass gain %v at %g Hz, got %v", tc.lowpass, tc.freq, got)
+		}
+		if got := firGain(highpass, tc.freq); math.Abs(got-tc.highpass) > 0.01 {
+			t.Errorf("Expected highpass gain %v at %g Hz, got %v", tc.highpass, tc.freq, got)
+		}
+	}
+	if _, err := LowpassFIR(100, 100, rate); err == nil {
+		t.Error("Expected error for an even number of taps")
+	}
+}
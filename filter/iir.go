@@ -0,0 +1,177 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package filter
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// Biquad is a second order IIR filter section with the transfer function
+//
+//	H(z) = (B0 + B1 z^-1 + B2 z^-2) / (1 + A1 z^-1 + A2 z^-2)
+//
+// implemented in transposed direct form II. A first order section has B2 and
+// A2 zero.
+type Biquad struct {
+	B0, B1, B2 float64
+	A1, A2     float64
+
+	z1, z2 float64
+}
+
+// Filter implements the Filter interface for Biquad.
+func (q *Biquad) Filter(x float64) float64 {
+	y := q.B0*x + q.z1
+	q.z1 = q.B1*x - q.A1*y + q.z2
+	q.z2 = q.B2*x - q.A2*y
+	return y
+}
+
+// Reset implements the Filter interface for Biquad.
+func (q *Biquad) Reset() {
+	q.z1, q.z2 = 0, 0
+}
+
+// Gain returns the magnitude of the section's frequency response at the given
+// frequency for the sample rate.
+func (q *Biquad) Gain(freq, rate float64) float64 {
+	z1 := cmplx.Exp(complex(0, -2*math.Pi*freq/rate))
+	z2 := z1 * z1
+	num := complex(q.B0, 0) + complex(q.B1, 0)*z1 + complex(q.B2, 0)*z2
+	den := 1 + complex(q.A1, 0)*z1 + complex(q.A2, 0)*z2
+	return cmplx.Abs(num / den)
+}
+
+// Cascade is an IIR filter made of biquad sections applied in turn.
+type Cascade struct {
+	Sections []Biquad
+}
+
+// Filter implements the Filter interface for Cascade.
+func (c *Cascade) Filter(x float64) float64 {
+	for i := range c.Sections {
+		x = c.Sections[i].Filter(x)
+	}
+	return x
+}
+
+// Reset implements the Filter interface for Cascade.
+func (c *Cascade) Reset() {
+	for i := range c.Sections {
+		c.Sections[i].Reset()
+	}
+}
+
+// Gain returns the magnitude of the filter's frequency response at the given
+// frequency for the sample rate.
+func (c *Cascade) Gain(freq, rate float64) float64 {
+	gain := 1.0
+	for i := range c.Sections {
+		gain *= c.Sections[i].Gain(freq, rate)
+	}
+	return gain
+}
+
+// Lowpass designs a Butterworth lowpass filter of the given order, which is
+// 3 dB down at the cutoff frequency, for the sample rate in Hz.
+func Lowpass(order int, cutoff, rate float64) (*Cascade, error) {
+	return butterworth(order, cutoff, rate, false)
+}
+
+// Highpass designs a Butterworth highpass filter of the given order, which
+// is 3 dB down at the cutoff frequency, for the sample rate in Hz.
+func Highpass(order int, cutoff, rate float64) (*Cascade, error) {
+	return butterworth(order, cutoff, rate, true)
+}
+
+// butterworth designs a Butterworth filter from the analog prototype by the
+// bilinear transform, with the cutoff prewarped so that it's exact.
+func butterworth(order int, cutoff, rate float64, highpass bool) (*Cascade, error) {
+	if order < 1 {
+		return nil, fmt.Errorf("bad Butterworth filter order %d", order)
+	}
+	if err := checkCutoff("cutoff", cutoff, rate); err != nil {
+		return nil, err
+	}
+	k := math.Tan(math.Pi * cutoff / rate)
+	var c Cascade
+	for i := 0; i < order/2; i++ {
+		q := 1 / (2 * math.Sin(math.Pi*float64(2*i+1)/float64(2*order)))
+		norm := 1 / (1 + k/q + k*k)
+		s := Biquad{
+			A1: 2 * (k*k - 1) * norm,
+			A2: (1 - k/q + k*k) * norm,
+		}
+		if highpass {
+			s.B0, s.B1, s.B2 = norm, -2*norm, norm
+		} else {
+			s.B0 = k * k * norm
+			s.B1, s.B2 = 2*s.B0, s.B0
+		}
+		c.Sections = append(c.Sections, s)
+	}
+	if order%2 == 1 {
+		norm := 1 / (1 + k)
+		s := Biquad{A1: (k - 1) * norm}
+		if highpass {
+			s.B0, s.B1 = norm, -norm
+		} else {
+			s.B0, s.B1 = k*norm, k*norm
+		}
+		c.Sections = append(c.Sections, s)
+	}
+	return &c, nil
+}
+
+// Bandpass designs a biquad bandpass filter centered on the given frequency
+// with unity gain there, whose bandwidth is the center frequency divided by
+// q, for the sample rate in Hz.
+func Bandpass(center, q, rate float64) (*Biquad, error) {
+	cos, alpha, err := resonator(center, q, rate)
+	if err != nil {
+		return nil, err
+	}
+	a0 := 1 + alpha
+	return &Biquad{
+		B0: alpha / a0,
+		B2: -alpha / a0,
+		A1: -2 * cos / a0,
+		A2: (1 - alpha) / a0,
+	}, nil
+}
+
+// Notch designs a biquad notch filter that rejects the given frequency, such
+// as 50 or 60 Hz mains interference, whose bandwidth is the notch frequency
+// divided by q, for the sample rate in Hz.
+func Notch(freq, q, rate float64) (*Biquad, error) {
+	cos, alpha, err := resonator(freq, q, rate)
+	if err != nil {
+		return nil, err
+	}
+	a0 := 1 + alpha
+	return &Biquad{
+		B0: 1 / a0,
+		B1: -2 * cos / a0,
+		B2: 1 / a0,
+		A1: -2 * cos / a0,
+		A2: (1 - alpha) / a0,
+	}, nil
+}
+
+// resonator returns the cosine of the center frequency and the alpha term of
+// a bandpass or notch biquad.
+func resonator(freq, q, rate float64) (cos, alpha float64, err error) {
+	if err := checkCutoff("center frequency", freq, rate); err != nil {
+		return 0, 0, err
+	}
+	if !(q > 0) {
+		return 0, 0, fmt.Errorf("bad filter Q %g", q)
+	}
+	w := 2 * math.Pi * freq / rate
+	return math.Cos(w), math.Sin(w) / (2 * q), nil
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package filter
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// butterworthGain returns the ideal gain of a digital Butterworth lowpass
+// filter designed by the bilinear transform.
+func butterworthGain(order int, freq, cutoff float64) float64 {
+	ratio := math.Tan(math.Pi*freq/rate) / math.Tan(math.Pi*cutoff/rate)
+	return 1 / math.Sqrt(1+math.Pow(ratio, float64(2*order)))
+}
+
+func TestButterworth(t *testing.T) {
+	for _, order := range []int{1, 2, 3, 4, 7} {
+		lowpass, err := Lowpass(order, 100, rate)
+		if err != nil {
+			t.Fatalf("Lowpass: %v", err)
+		}
+		highpass, err := Highpass(order, 100, rate)
+		if err != nil {
+			t.Fatalf("Highpass: %v", err)
+		}
+		if len(lowpass.Sections) != (order+1)/2 {
+			t.Errorf("Expected %d sections for order %d, got %d",
+				(order+1)/2, order, len(lowpass.Sections))
+		}
+		for _, freq := range []float64{0, 20, 100, 250, 400} {
+			want := butterworthGain(order, freq, 100)
+			if got := lowpass.Gain(freq, rate); math.Abs(got-want) > 1e-9 {
+				t.Errorf("Expected order %d lowpass gain %v at %g Hz, got %v", order, want, freq, got)
+			}
+			// The highpass gain is complementary in power.
+			want = math.Sqrt(1 - want*want)
+			if got := highpass.Gain(freq, rate); math.Abs(got-want) > 1e-9 {
+				t.Errorf("Expected order %d highpass gain %v at %g Hz, got %v", order, want, freq, got)
+			}
+		}
+	}
+}
+
+func TestLowpassSine(t *testing.T) {
+	lowpass, err := Lowpass(4, 100, rate)
+	if err != nil {
+		t.Fatalf("Lowpass: %v", err)
+	}
+	// Once settled, a sine comes out with the amplitude given by Gain.
+	const freq = 150.0
+	peak := 0.0
+	for i := 0; i < 2000; i++ {
+		y := lowpass.Filter(math.Sin(2 * math.Pi * freq * float64(i) / rate))
+		if i >= 1000 {
+			peak = math.Max(peak, math.Abs(y))
+		}
+	}
+	if want := lowpass.Gain(freq, rate); math.Abs(peak-want) > 0.01 {
+		t.Errorf("Expected amplitude %v, got %v", want, peak)
+	}
+}
+
+func TestNotchBandpass(t *testing.T) {
+	notch, err := Notch(60, 10, rate)
+	if err != nil {
+		t.Fatalf("Notch: %v", err)
+	}
+	bandpass, err := Bandpass(60, 10, rate)
+	if err != nil {
+		t.Fatalf("Bandpass: %v", err)
+	}
+	if got := notch.Gain(60, rate); got > 1e-9 {
+		t.Errorf("Expected notch to reject 60 Hz, got gain %v", got)
+	}
+	if got := notch.Gain(5, rate); math.Abs(got-1) > 0.01 {
+		t.Errorf("Expected notch to pass 5 Hz, got gain %v", got)
+	}
+	if got := bandpass.Gain(60, rate); math.Abs(got-1) > 1e-9 {
+		t.Errorf("Expected bandpass gain 1 at 60 Hz, got %v", got)
+	}
+	if got := bandpass.Gain(400, rate); got > 0.05 {
+		t.Errorf("Expected bandpass to reject 400 Hz, got gain %v", got)
+	}
+}
+
+func TestIIRErrors(t *testing.T) {
+	for _, cutoff := range []float64{0, -1, 500, 600, math.NaN()} {
+		if _, err := Lowpass(2, cutoff, rate); !errors.Is(err, mccdaq.ErrInvalidFrequency) {
+			t.Errorf("Expected error %v for cutoff %g, got %v", mccdaq.ErrInvalidFrequency, cutoff, err)
+		}
+	}
+	if _, err := Lowpass(2, 100, 0); !errors.Is(err, mccdaq.ErrInvalidFrequency) {
+		t.Errorf("Expected error %v for no sample rate, got %v", mccdaq.ErrInvalidFrequency, err)
+	}
+	if _, err := Highpass(0, 100, rate); err == nil {
+		t.Error("Expected error for order 0")
+	}
+	if _, err := Notch(60, 0, rate); err == nil {
+		t.Error("Expected error for Q 0")
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package mccdaq
+
+import "time"
+
+// Frame is a block of consecutive scans from an analog input scan, decoded by
+// channel. Raw and Volts are indexed first by the position of the channel in
+// Channels and then by scan, so Volts[i][j] is the voltage of channel
+// Channels[i] in scan Index+j.
+//
+// If scans were lost to an overrun just before the frame, LostScans is the
+// estimated number of scans missing, and Index already accounts for them.
+type Frame struct {
+	Index     uint64      // Index of the first scan in the frame since the scan started
+	LostScans uint64      // Estimated number of scans lost just before the frame
+	Timebase  Timebase    // Timebase of the scan when the frame was read
+	Channels  []int       // Enabled channel numbers
+	Raw       [][]uint16  // Raw ADC codes by channel and scan
+	Volts     [][]float64 // Calibrated voltages by channel and scan
+}
+
+// NumScans returns the number of scans in the frame.
+func (f Frame) NumScans() int {
+	if len(f.Raw) == 0 {
+		return 0
+	}
+	return len(f.Raw[0])
+}
+
+// Time returns the pacer time of the given scan in the frame, counting from
+// zero for the first scan in the frame.
+func (f Frame) Time(scan int) time.Time {
+	return f.Timebase.Time(f.Index + uint64(scan))
+}
+
+// Position returns the position in Channels of the given channel number, or -1
+// if the channel isn't in the frame.
+func (f Frame) Position(channel int) int {
+	for i, ch := range f.Channels {
+		if ch == channel {
+			return i
+		}
+	}
+	return -1
+}
+
+// SameChannels reports whether the frame holds exactly the given channels in
+// the same order, such as those of the previous frame in a stream.
+func (f Frame) SameChannels(channels []int) bool {
+	if len(f.Channels) != len(channels) {
+		return false
+	}
+	for i := range channels {
+		if f.Channels[i] != channels[i] {
+			return false
+		}
+	}
+	return true
+}
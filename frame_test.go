@@ -0,0 +1,74 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package mccdaq
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFrameNumScans(t *testing.T) {
+	testCases := []struct {
+		frame    Frame
+		expected int
+	}{
+		{Frame{}, 0},
+		{Frame{Channels: []int{0}, Raw: [][]uint16{{1, 2, 3}}}, 3},
+		{Frame{Channels: []int{0, 3}, Raw: [][]uint16{{1, 2}, {3, 4}}}, 2},
+	}
+	for _, tc := range testCases {
+		if computed := tc.frame.NumScans(); computed != tc.expected {
+			t.Errorf("Expected %d scans, got %d", tc.expected, computed)
+		}
+	}
+}
+
+func TestFrameTime(t *testing.T) {
+	anchor := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	frame := Frame{
+		Index:    100,
+		Timebase: Timebase{Anchor: anchor, Period: time.Millisecond},
+	}
+	expected := anchor.Add(102 * time.Millisecond)
+	if computed := frame.Time(2); !computed.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, computed)
+	}
+}
+
+func TestFramePosition(t *testing.T) {
+	frame := Frame{Channels: []int{0, 3, 5}}
+	testCases := []struct {
+		channel  int
+		expected int
+	}{
+		{0, 0},
+		{5, 2},
+		{1, -1},
+	}
+	for _, tc := range testCases {
+		if computed := frame.Position(tc.channel); computed != tc.expected {
+			t.Errorf("Expected channel %d at %d, got %d", tc.channel, tc.expected, computed)
+		}
+	}
+}
+
+func TestFrameSameChannels(t *testing.T) {
+	frame := Frame{Channels: []int{0, 3}}
+	testCases := []struct {
+		channels []int
+		expected bool
+	}{
+		{[]int{0, 3}, true},
+		{[]int{3, 0}, false},
+		{[]int{0}, false},
+		{nil, false},
+	}
+	for _, tc := range testCases {
+		if computed := frame.SameChannels(tc.channels); computed != tc.expected {
+			t.Errorf("Expected %t for %v, got %t", tc.expected, tc.channels, computed)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+// Package sinc designs the windowed sinc lowpass FIR filters shared by the
+// filter and decimate packages.
+package sinc
+
+import "math"
+
+// Blackman returns the given number of taps, at least one, of a
+// Blackman-windowed sinc lowpass filter with its cutoff at fc cycles per
+// sample, normalized to unity gain at DC. The taps are symmetric about their
+// middle, so the filter has linear phase.
+func Blackman(taps int, fc float64) []float64 {
+	h := make([]float64, taps)
+	mid := float64(taps-1) / 2
+	sum := 0.0
+	for i := range h {
+		x := float64(i) - mid
+		sinc := 2 * fc
+		if x != 0 {
+			sinc = math.Sin(2*math.Pi*fc*x) / (math.Pi * x)
+		}
+		w := 1.0
+		if taps > 1 {
+			phase := 2 * math.Pi * float64(i) / float64(taps-1)
+			w = 0.42 - 0.5*math.Cos(phase) + 0.08*math.Cos(2*phase)
+		}
+		h[i] = sinc * w
+		sum += h[i]
+	}
+	for i := range h {
+		h[i] /= sum
+	}
+	return h
+}
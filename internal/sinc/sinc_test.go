@@ -0,0 +1,38 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package sinc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBlackman(t *testing.T) {
+	testCases := []struct {
+		taps int
+		fc   float64
+	}{
+		{1, 0.25},
+		{8, 0.1},
+		{31, 0.05},
+	}
+	for _, tc := range testCases {
+		h := Blackman(tc.taps, tc.fc)
+		if len(h) != tc.taps {
+			t.Fatalf("Expected %d taps, got %d", tc.taps, len(h))
+		}
+		sum := 0.0
+		for i, v := range h {
+			sum += v
+			if mirror := h[len(h)-1-i]; math.Abs(v-mirror) > 1e-15 {
+				t.Errorf("Expected tap %d of %d to mirror %v, got %v", i, tc.taps, mirror, v)
+			}
+		}
+		if math.Abs(sum-1) > 1e-12 {
+			t.Errorf("Expected unity DC gain with %d taps, got %v", tc.taps, sum)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+// Package usberr maps the libusb error codes returned by the device packages'
+// USB transfers to the mccdaq sentinel errors.
+package usberr
+
+import (
+	"github.com/gotmc/libusb"
+	"github.com/gotmc/mccdaq"
+)
+
+// The libusb error codes that the device packages check for. IO and NotFound
+// don't map to any mccdaq sentinel error.
+const (
+	IO           libusb.ErrorCode = -1  // LIBUSB_ERROR_IO
+	NotFound     libusb.ErrorCode = -5  // LIBUSB_ERROR_NOT_FOUND
+	NoDevice     libusb.ErrorCode = -4  // LIBUSB_ERROR_NO_DEVICE
+	Timeout      libusb.ErrorCode = -7  // LIBUSB_ERROR_TIMEOUT
+	Pipe         libusb.ErrorCode = -9  // LIBUSB_ERROR_PIPE
+	NotSupported libusb.ErrorCode = -12 // LIBUSB_ERROR_NOT_SUPPORTED
+)
+
+// usbError wraps a libusb error code returned by a USB transfer. It unwraps to
+// the libusb.ErrorCode, so errors.As can still retrieve the code, and it
+// matches the corresponding mccdaq sentinel error when using errors.Is.
+type usbError struct {
+	code libusb.ErrorCode
+}
+
+// Error implements the error interface for usbError.
+func (e *usbError) Error() string {
+	return e.code.Error()
+}
+
+// Unwrap returns the underlying libusb error code.
+func (e *usbError) Unwrap() error {
+	return e.code
+}
+
+// Is reports whether the libusb error code corresponds to the given mccdaq
+// sentinel error.
+func (e *usbError) Is(target error) bool {
+	switch e.code {
+	case NoDevice:
+		return target == mccdaq.ErrDisconnected
+	case Timeout:
+		return target == mccdaq.ErrTimeout
+	case Pipe:
+		return target == mccdaq.ErrStall
+	case NotSupported:
+		return target == mccdaq.ErrNotSupported
+	}
+	return false
+}
+
+// Wrap wraps a libusb error code so that it matches the corresponding mccdaq
+// sentinel error. Any other error, including nil, is returned unchanged.
+func Wrap(err error) error {
+	if code, ok := err.(libusb.ErrorCode); ok {
+		return &usbError{code: code}
+	}
+	return err
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package usberr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gotmc/libusb"
+	"github.com/gotmc/mccdaq"
+)
+
+func TestWrap(t *testing.T) {
+	testCases := []struct {
+		code     libusb.ErrorCode
+		expected error
+	}{
+		{NoDevice, mccdaq.ErrDisconnected},
+		{Timeout, mccdaq.ErrTimeout},
+		{Pipe, mccdaq.ErrStall},
+		{NotSupported, mccdaq.ErrNotSupported},
+	}
+	for _, tc := range testCases {
+		err := fmt.Errorf("transfer failed: %w", Wrap(tc.code))
+		if !errors.Is(err, tc.expected) {
+			t.Errorf("Expected libusb error %d to match %v", tc.code, tc.expected)
+		}
+		var code libusb.ErrorCode
+		if !errors.As(err, &code) || code != tc.code {
+			t.Errorf("Expected errors.As to find libusb error %d, got %d", tc.code, code)
+		}
+	}
+	if err := Wrap(nil); err != nil {
+		t.Errorf("Expected nil error to stay nil, got %v", err)
+	}
+	if errors.Is(Wrap(Pipe), mccdaq.ErrTimeout) {
+		t.Errorf("Expected a stall not to match ErrTimeout")
+	}
+}
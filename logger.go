@@ -0,0 +1,145 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package mccdaq
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// Logger is the structured logger used by the device packages. Each method
+// takes a message followed by alternating keys and values, such as
+//
+//	logger.Info("Found S/N", "serial_number", sn)
+//
+// A *slog.Logger satisfies Logger as is. Other structured loggers need a thin
+// adapter; for example, a zap.SugaredLogger's Debugw, Infow, Warnw, and Errorw
+// methods take the same arguments.
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// Level is the severity of a log event. The values match those used by the
+// log/slog package.
+type Level int
+
+// Available log levels.
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+)
+
+var levels = map[Level]string{
+	LevelDebug: "DEBUG",
+	LevelInfo:  "INFO",
+	LevelWarn:  "WARN",
+	LevelError: "ERROR",
+}
+
+// String implements the Stringer interface for Level.
+func (l Level) String() string {
+	if s, ok := levels[l]; ok {
+		return s
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+// StdLogger is a Logger that writes events at or above Level as key=value
+// pairs using a standard library *log.Logger. If Logger is nil, the log
+// package's standard logger is used. The zero value logs events at LevelInfo
+// and above to the standard logger.
+type StdLogger struct {
+	Logger *log.Logger
+	Level  Level
+}
+
+// NewStdLogger returns a StdLogger that writes events at or above the given
+// level to l. If l is nil, the log package's standard logger is used.
+func NewStdLogger(l *log.Logger, level Level) *StdLogger {
+	return &StdLogger{Logger: l, Level: level}
+}
+
+// Debug logs the message and key/value pairs at LevelDebug.
+func (s *StdLogger) Debug(msg string, keysAndValues ...interface{}) {
+	s.log(LevelDebug, msg, keysAndValues)
+}
+
+// Info logs the message and key/value pairs at LevelInfo.
+func (s *StdLogger) Info(msg string, keysAndValues ...interface{}) {
+	s.log(LevelInfo, msg, keysAndValues)
+}
+
+// Warn logs the message and key/value pairs at LevelWarn.
+func (s *StdLogger) Warn(msg string, keysAndValues ...interface{}) {
+	s.log(LevelWarn, msg, keysAndValues)
+}
+
+// Error logs the message and key/value pairs at LevelError.
+func (s *StdLogger) Error(msg string, keysAndValues ...interface{}) {
+	s.log(LevelError, msg, keysAndValues)
+}
+
+func (s *StdLogger) log(level Level, msg string, keysAndValues []interface{}) {
+	if level < s.Level {
+		return
+	}
+	line := formatEvent(level, msg, keysAndValues)
+	if s.Logger == nil {
+		log.Print(line)
+		return
+	}
+	s.Logger.Print(line)
+}
+
+// formatEvent formats a log event as level=LEVEL msg="message" key=value...
+// A trailing key without a value is logged with the key !BADKEY, as slog does.
+func formatEvent(level Level, msg string, keysAndValues []interface{}) string {
+	var b strings.Builder
+	b.WriteString("level=")
+	b.WriteString(level.String())
+	b.WriteString(" msg=")
+	b.WriteString(formatValue(msg))
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key := "!BADKEY"
+		value := keysAndValues[i]
+		if i+1 < len(keysAndValues) {
+			key = fmt.Sprint(keysAndValues[i])
+			value = keysAndValues[i+1]
+		}
+		b.WriteString(" ")
+		b.WriteString(key)
+		b.WriteString("=")
+		b.WriteString(formatValue(value))
+	}
+	return b.String()
+}
+
+// formatValue formats the value using its default format, quoting it if it
+// contains spaces, quotes, or equal signs.
+func formatValue(value interface{}) string {
+	s := fmt.Sprint(value)
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// NopLogger is a Logger that discards all log events.
+var NopLogger Logger = nopLogger{}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
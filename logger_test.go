@@ -0,0 +1,76 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package mccdaq
+
+import (
+	"bytes"
+	"log"
+	"testing"
+)
+
+func TestStdLogger(t *testing.T) {
+	testCases := []struct {
+		level    Level
+		logFunc  func(l Logger)
+		expected string
+	}{
+		{
+			LevelInfo,
+			func(l Logger) { l.Info("Found S/N", "serial_number", "01AF3FAE") },
+			"level=INFO msg=\"Found S/N\" serial_number=01AF3FAE\n",
+		},
+		{
+			LevelInfo,
+			func(l Logger) { l.Debug("Sent command", "command", "Blink LED", "bytes", 1) },
+			"",
+		},
+		{
+			LevelDebug,
+			func(l Logger) { l.Debug("Sent command", "command", "Blink LED", "bytes", 1) },
+			"level=DEBUG msg=\"Sent command\" command=\"Blink LED\" bytes=1\n",
+		},
+		{
+			LevelError,
+			func(l Logger) { l.Warn("Analog input scan overrun", "bytes", 128) },
+			"",
+		},
+		{
+			LevelWarn,
+			func(l Logger) { l.Warn("Analog input scan overrun", "bytes", 128) },
+			"level=WARN msg=\"Analog input scan overrun\" bytes=128\n",
+		},
+		{
+			LevelWarn,
+			func(l Logger) { l.Error("Odd number of values", "bytes") },
+			"level=ERROR msg=\"Odd number of values\" !BADKEY=bytes\n",
+		},
+	}
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		tc.logFunc(NewStdLogger(log.New(&buf, "", 0), tc.level))
+		if buf.String() != tc.expected {
+			t.Errorf("Expected `%s`, got `%s`", tc.expected, buf.String())
+		}
+	}
+}
+
+func TestLevelString(t *testing.T) {
+	testCases := []struct {
+		level    Level
+		expected string
+	}{
+		{LevelDebug, "DEBUG"},
+		{LevelInfo, "INFO"},
+		{LevelWarn, "WARN"},
+		{LevelError, "ERROR"},
+		{Level(2), "LEVEL(2)"},
+	}
+	for _, tc := range testCases {
+		if tc.level.String() != tc.expected {
+			t.Errorf("Expected %s, got %s", tc.expected, tc.level)
+		}
+	}
+}
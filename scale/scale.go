@@ -0,0 +1,277 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+// Package scale converts channel voltages into engineering units, such as the
+// pressure or flow read by a transducer, with linear, polynomial, lookup
+// table, or reverse polynomial scales that can be set in the JSON
+// configuration of a channel.
+package scale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// Type identifies how a Scale converts volts into engineering units.
+type Type int
+
+// Available types of scale.
+const (
+	// Linear converts volts v into Slope*v + Offset.
+	Linear Type = iota
+	// Polynomial converts volts v into the sum of Coefficients[i]*v^i.
+	Polynomial
+	// Table interpolates linearly between the (volts, value) points of Table,
+	// extrapolating the first and last segments beyond the table.
+	Table
+	// ReversePolynomial has Coefficients giving volts as a polynomial of the
+	// value, as transducer data sheets often do, and converts volts into the
+	// value by solving the polynomial, which must be monotonic between Min
+	// and Max. Beyond that range the value is extrapolated from the slope at
+	// the end of the range.
+	ReversePolynomial
+)
+
+var types = map[Type]string{
+	Linear:            "linear",
+	Polynomial:        "polynomial",
+	Table:             "table",
+	ReversePolynomial: "reverse_polynomial",
+}
+
+// String implements the Stringer interface for Type.
+func (t Type) String() string {
+	return types[t]
+}
+
+// UnmarshalJSON implements the Unmarshaler interface for Type by taking the
+// string form of a Type.
+func (t *Type) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("scale type should be a string, got %s", data)
+	}
+	for typ, name := range types {
+		if name == s {
+			*t = typ
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid string %q for scale Type", s)
+}
+
+// MarshalJSON implements the Marshaler interface for Type.
+func (t Type) MarshalJSON() ([]byte, error) {
+	return json.Marshal(types[t])
+}
+
+// Scale converts volts into engineering units. Only the fields used by its
+// Type need be set, as in the JSON
+//
+//	{"type": "linear", "slope": 25, "offset": -12.5}
+//	{"type": "table", "table": [[0.5, 0], [2.5, 40], [4.5, 100]]}
+//	{"type": "reverse_polynomial", "coefficients": [0.5, 0.04, 0.0001], "min": 0, "max": 100}
+type Scale struct {
+	Type         Type         `json:"type"`
+	Slope        float64      `json:"slope,omitempty"`
+	Offset       float64      `json:"offset,omitempty"`
+	Coefficients []float64    `json:"coefficients,omitempty"`
+	Table        [][2]float64 `json:"table,omitempty"`
+	Min          float64      `json:"min,omitempty"`
+	Max          float64      `json:"max,omitempty"`
+}
+
+// UnmarshalJSON implements the Unmarshaler interface for Scale, validating
+// the scale so that a bad configuration is caught when it's loaded.
+func (s *Scale) UnmarshalJSON(data []byte) error {
+	type plain Scale
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*s = Scale(p)
+	return s.Validate()
+}
+
+// Validate checks that the scale has what its Type needs.
+func (s Scale) Validate() error {
+	switch s.Type {
+	case Linear:
+		if s.Slope == 0 {
+			return fmt.Errorf("linear scale needs a nonzero slope")
+		}
+	case Polynomial:
+		if len(s.Coefficients) == 0 {
+			return fmt.Errorf("polynomial scale needs coefficients")
+		}
+	case Table:
+		if len(s.Table) < 2 {
+			return fmt.Errorf("table scale needs at least 2 points, not %d", len(s.Table))
+		}
+		for i := 1; i < len(s.Table); i++ {
+			if s.Table[i][0] <= s.Table[i-1][0] {
+				return fmt.Errorf("table scale volts must increase, but point %d is %g V after %g V",
+					i, s.Table[i][0], s.Table[i-1][0])
+			}
+		}
+	case ReversePolynomial:
+		if len(s.Coefficients) < 2 {
+			return fmt.Errorf("reverse polynomial scale needs at least 2 coefficients")
+		}
+		if !(s.Min < s.Max) {
+			return fmt.Errorf("reverse polynomial scale range %g to %g is empty", s.Min, s.Max)
+		}
+		// Check that the polynomial is monotonic by the sign of its slope
+		// across the range.
+		const checks = 1000
+		sign := 0.0
+		for i := 0; i <= checks; i++ {
+			x := s.Min + (s.Max-s.Min)*float64(i)/checks
+			d := derivative(s.Coefficients, x)
+			if d == 0 || (sign != 0 && math.Signbit(d) != math.Signbit(sign)) {
+				return fmt.Errorf("reverse polynomial scale isn't monotonic near %g", x)
+			}
+			sign = d
+		}
+	default:
+		return fmt.Errorf("bad scale type %d: %w", s.Type, mccdaq.ErrNotSupported)
+	}
+	return nil
+}
+
+// Value converts volts into engineering units. The scale must be valid.
+func (s Scale) Value(volts float64) float64 {
+	switch s.Type {
+	case Linear:
+		return s.Slope*volts + s.Offset
+	case Polynomial:
+		return polynomial(s.Coefficients, volts)
+	case Table:
+		return s.interpolate(volts)
+	case ReversePolynomial:
+		return s.solve(volts)
+	}
+	return math.NaN()
+}
+
+// Values converts each of the volts into engineering units.
+func (s Scale) Values(volts []float64) []float64 {
+	values := make([]float64, len(volts))
+	for i, v := range volts {
+		values[i] = s.Value(v)
+	}
+	return values
+}
+
+// interpolate interpolates the table at the given volts.
+func (s Scale) interpolate(volts float64) float64 {
+	n := len(s.Table)
+	i := sort.Search(n, func(i int) bool { return s.Table[i][0] > volts })
+	// Use the segment ending at point i, or the first or last segment.
+	if i < 1 {
+		i = 1
+	}
+	if i > n-1 {
+		i = n - 1
+	}
+	a, b := s.Table[i-1], s.Table[i]
+	return a[1] + (volts-a[0])*(b[1]-a[1])/(b[0]-a[0])
+}
+
+// solve returns the value whose polynomial gives the volts, by Newton's method
+// safeguarded by bisection.
+func (s Scale) solve(volts float64) float64 {
+	c := s.Coefficients
+	lo, hi := s.Min, s.Max
+	flo, fhi := polynomial(c, lo)-volts, polynomial(c, hi)-volts
+	switch {
+	case flo == 0:
+		return lo
+	case fhi == 0:
+		return hi
+	case (flo < 0) == (fhi < 0):
+		// Beyond the range, extrapolate from the nearer end.
+		end := lo
+		if math.Abs(fhi) < math.Abs(flo) {
+			end = hi
+		}
+		return end - (polynomial(c, end)-volts)/derivative(c, end)
+	}
+	x := lo - flo*(hi-lo)/(fhi-flo)
+	for i := 0; i < 100; i++ {
+		f := polynomial(c, x) - volts
+		if f == 0 {
+			break
+		}
+		if (f < 0) == (flo < 0) {
+			lo, flo = x, f
+		} else {
+			hi = x
+		}
+		next := x - f/derivative(c, x)
+		if !(next > lo && next < hi) {
+			next = (lo + hi) / 2
+		}
+		if math.Abs(next-x) <= 1e-12*math.Max(1, math.Abs(x)) {
+			return next
+		}
+		x = next
+	}
+	return x
+}
+
+// polynomial evaluates the sum of c[i]*x^i.
+func polynomial(c []float64, x float64) float64 {
+	sum := 0.0
+	for i := len(c) - 1; i >= 0; i-- {
+		sum = sum*x + c[i]
+	}
+	return sum
+}
+
+// derivative evaluates the derivative of the polynomial at x.
+func derivative(c []float64, x float64) float64 {
+	sum := 0.0
+	for i := len(c) - 1; i >= 1; i-- {
+		sum = sum*x + float64(i)*c[i]
+	}
+	return sum
+}
+
+// Scales holds the scale of each channel that has one, keyed by channel
+// number.
+type Scales map[int]Scale
+
+// Frame returns a copy of the frame whose Volts hold the values in
+// engineering units of the channels with scales, and volts for the other
+// channels.
+func (s Scales) Frame(frame mccdaq.Frame) mccdaq.Frame {
+	scaled := frame
+	scaled.Volts = make([][]float64, len(frame.Volts))
+	for i, ch := range frame.Channels {
+		if sc, ok := s[ch]; ok {
+			scaled.Volts[i] = sc.Values(frame.Volts[i])
+		} else {
+			scaled.Volts[i] = append([]float64{}, frame.Volts[i]...)
+		}
+	}
+	return scaled
+}
+
+// Watch scales each frame received from frames, such as a Stream's Frames,
+// and sends it on the returned channel, which is closed once frames is
+// closed or the context is done. Pass the channel to alarm.Engine's Watch to
+// apply limits in engineering units.
+func (s Scales) Watch(ctx context.Context, frames <-chan mccdaq.Frame) <-chan mccdaq.Frame {
+	var watcher mccdaq.Watcher
+	return watcher.WatchFrames(ctx, frames, func(frame mccdaq.Frame) (mccdaq.Frame, error) {
+		return s.Frame(frame), nil
+	})
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package scale
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/gotmc/mccdaq"
+)
+
+func TestTypeString(t *testing.T) {
+	testCases := []struct {
+		typ  Type
+		want string
+	}{
+		{Linear, "linear"},
+		{Polynomial, "polynomial"},
+		{Table, "table"},
+		{ReversePolynomial, "reverse_polynomial"},
+	}
+	for _, tc := range testCases {
+		if got := tc.typ.String(); got != tc.want {
+			t.Errorf("Expected %s, got %s", tc.want, got)
+		}
+	}
+}
+
+func TestValue(t *testing.T) {
+	// 0.5 V at 0 psi to 4.5 V at 100 psi.
+	linear := Scale{Type: Linear, Slope: 25, Offset: -12.5}
+	poly := Scale{Type: Polynomial, Coefficients: []float64{1, 2, 3}}
+	table := Scale{Type: Table, Table: [][2]float64{{0.5, 0}, {2.5, 40}, {4.5, 100}}}
+	// Volts are 0.5 + 0.04x + 0.0001x² for x from 0 to 100.
+	reverse := Scale{
+		Type:         ReversePolynomial,
+		Coefficients: []float64{0.5, 0.04, 0.0001},
+		Min:          0,
+		Max:          100,
+	}
+	testCases := []struct {
+		name  string
+		scale Scale
+		volts float64
+		want  float64
+	}{
+		{"linear low", linear, 0.5, 0},
+		{"linear high", linear, 4.5, 100},
+		{"polynomial", poly, 2, 17},
+		{"table point", table, 2.5, 40},
+		{"table between", table, 1.5, 20},
+		{"table second segment", table, 3.5, 70},
+		{"table below", table, 0, -10},
+		{"table above", table, 5.5, 130},
+		{"reverse low", reverse, 0.5, 0},
+		{"reverse middle", reverse, 0.5 + 0.04*37 + 0.0001*37*37, 37},
+		{"reverse high", reverse, 5.5, 100},
+		{"reverse above", reverse, 5.56, 101},
+	}
+	for _, tc := range testCases {
+		if got := tc.scale.Value(tc.volts); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%s: Expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestScaleJSON(t *testing.T) {
+	var s Scale
+	data := []byte(`{"type": "table", "table": [[0.5, 0], [4.5, 100]]}`)
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	want := Scale{Type: Table, Table: [][2]float64{{0.5, 0}, {4.5, 100}}}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("Expected %+v, got %+v", want, s)
+	}
+	b, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if got := string(b); got != `{"type":"table","table":[[0.5,0],[4.5,100]]}` {
+		t.Errorf("Expected marshaled table scale, got %s", got)
+	}
+	bad := []string{
+		`{"type": "cubic"}`,
+		`{"type": "linear"}`,
+		`{"type": "table", "table": [[1, 0], [1, 2]]}`,
+	}
+	for _, data := range bad {
+		if err := json.Unmarshal([]byte(data), &s); err == nil {
+			t.Errorf("Expected error for %s", data)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name  string
+		scale Scale
+	}{
+		{"polynomial without coefficients", Scale{Type: Polynomial}},
+		{"table of one point", Scale{Type: Table, Table: [][2]float64{{0, 0}}}},
+		{"reverse without range", Scale{Type: ReversePolynomial, Coefficients: []float64{0, 1}}},
+		{"reverse not monotonic", Scale{Type: ReversePolynomial, Coefficients: []float64{0, -1, 1}, Max: 1}},
+	}
+	for _, tc := range testCases {
+		if err := tc.scale.Validate(); err == nil {
+			t.Errorf("%s: Expected error", tc.name)
+		}
+	}
+	if err := (Scale{Type: Type(9)}).Validate(); !errors.Is(err, mccdaq.ErrNotSupported) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrNotSupported, err)
+	}
+}
+
+func TestScalesFrame(t *testing.T) {
+	scales := Scales{2: {Type: Linear, Slope: 2, Offset: 1}}
+	frame := mccdaq.Frame{
+		Channels: []int{0, 2},
+		Raw:      [][]uint16{{1, 2}, {3, 4}},
+		Volts:    [][]float64{{0.5, 1}, {1.5, 2}},
+	}
+	frames := make(chan mccdaq.Frame, 1)
+	frames <- frame
+	close(frames)
+	var got []mccdaq.Frame
+	for f := range scales.Watch(context.Background(), frames) {
+		got = append(got, f)
+	}
+	want := [][]float64{{0.5, 1}, {4, 5}}
+	if len(got) != 1 || !reflect.DeepEqual(got[0].Volts, want) {
+		t.Fatalf("Expected values %v, got %+v", want, got)
+	}
+	if frame.Volts[1][0] != 1.5 {
+		t.Errorf("Expected the original frame to be unchanged, got %v", frame.Volts)
+	}
+}
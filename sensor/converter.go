@@ -0,0 +1,200 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package sensor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// Condition is the condition of a sensor and its wiring.
+type Condition int
+
+// Available conditions. Any but OK is a fault.
+const (
+	OK Condition = iota
+	// Open is a broken wire or sensor: a thermistor or RTD above its
+	// OpenAbove resistance, or a current loop below 1 mA.
+	Open
+	// Short is a thermistor or RTD below its ShortBelow resistance.
+	Short
+	// UnderRange is a current loop between 1 mA and 3.6 mA, which a
+	// transmitter signals a failure with.
+	UnderRange
+	// OverRange is a current loop above 21 mA, which a transmitter signals a
+	// failure with, or which a shorted loop draws.
+	OverRange
+)
+
+var conditions = map[Condition]string{
+	OK:         "ok",
+	Open:       "open",
+	Short:      "short",
+	UnderRange: "under range",
+	OverRange:  "over range",
+}
+
+// String implements the Stringer interface for Condition.
+func (c Condition) String() string {
+	return conditions[c]
+}
+
+// Fault reports that the condition of a channel's sensor changed, either into
+// a fault or, if Condition is OK, back out of one. Signal is the resistance
+// in Ω or current in amps the sensor presented at the scan.
+type Fault struct {
+	Channel   int
+	Condition Condition
+	Scan      uint64    // Index of the scan since the scan started
+	Time      time.Time // Pacer time of the scan
+	Signal    float64
+}
+
+// Handler is called with each fault.
+type Handler func(Fault)
+
+// Option configures a Converter.
+type Option func(*Converter)
+
+// WithHandler calls the handler with each fault, in order, as the frames are
+// processed. The handler runs on the goroutine processing the frames, so it
+// should act quickly.
+func WithHandler(h Handler) Option {
+	return func(c *Converter) {
+		c.handlers = append(c.handlers, h)
+	}
+}
+
+// Converter converts the sensor channels of frames into temperature or
+// engineering units, tracking the condition of each sensor.
+type Converter struct {
+	configs  map[int]Config
+	channels []int
+	handlers []Handler
+
+	mu      sync.Mutex
+	faults  map[int]Fault
+	watcher mccdaq.Watcher
+}
+
+// New creates a Converter for the given sensor configs, keyed by channel
+// number, such as those returned by an analog input's Sensors method.
+func New(configs map[int]Config, opts ...Option) (*Converter, error) {
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("converter needs at least one sensor")
+	}
+	c := Converter{
+		configs: make(map[int]Config),
+		faults:  make(map[int]Fault),
+	}
+	for ch, config := range configs {
+		if err := config.Validate(); err != nil {
+			return nil, fmt.Errorf("sensor channel %d: %w", ch, err)
+		}
+		c.configs[ch] = config
+		c.channels = append(c.channels, ch)
+	}
+	sort.Ints(c.channels)
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &c, nil
+}
+
+// Process returns a copy of the frame whose Volts hold the temperature or
+// engineering value of the sensor channels, and volts for the other
+// channels. A scan whose sensor is faulted converts into +Inf or -Inf, as
+// described for Config.Convert, and the handlers are called whenever a
+// sensor's condition changes. Process fails
+// with mccdaq.ErrInvalidChannel if a sensor channel isn't in the frame.
+func (c *Converter) Process(frame mccdaq.Frame) (mccdaq.Frame, error) {
+	c.mu.Lock()
+	converted, faults, err := c.process(frame)
+	c.mu.Unlock()
+	if err != nil {
+		return frame, err
+	}
+	for _, fault := range faults {
+		for _, h := range c.handlers {
+			h(fault)
+		}
+	}
+	return converted, nil
+}
+
+func (c *Converter) process(frame mccdaq.Frame) (mccdaq.Frame, []Fault, error) {
+	positions := make(map[int]int)
+	for i, ch := range frame.Channels {
+		positions[ch] = i
+	}
+	for _, ch := range c.channels {
+		if _, ok := positions[ch]; !ok {
+			return frame, nil, fmt.Errorf("sensor channel %d not in frame: %w", ch, mccdaq.ErrInvalidChannel)
+		}
+	}
+	converted := frame
+	converted.Volts = make([][]float64, len(frame.Volts))
+	for i := range frame.Volts {
+		converted.Volts[i] = append([]float64{}, frame.Volts[i]...)
+	}
+	var faults []Fault
+	for scan := 0; scan < frame.NumScans(); scan++ {
+		for _, ch := range c.channels {
+			config, i := c.configs[ch], positions[ch]
+			v := frame.Volts[i][scan]
+			value, condition := config.Convert(v)
+			converted.Volts[i][scan] = value
+			if condition == c.faults[ch].Condition {
+				continue
+			}
+			fault := Fault{
+				Channel:   ch,
+				Condition: condition,
+				Scan:      frame.Index + uint64(scan),
+				Time:      frame.Time(scan),
+				Signal:    config.Signal(v),
+			}
+			if condition == OK {
+				delete(c.faults, ch)
+			} else {
+				c.faults[ch] = fault
+			}
+			faults = append(faults, fault)
+		}
+	}
+	return converted, faults, nil
+}
+
+// Faults returns the current fault of each faulted sensor, in channel order.
+func (c *Converter) Faults() []Fault {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var faults []Fault
+	for _, ch := range c.channels {
+		if fault, ok := c.faults[ch]; ok {
+			faults = append(faults, fault)
+		}
+	}
+	return faults
+}
+
+// Watch converts each frame received from frames, such as a Stream's Frames,
+// and sends it on the returned channel, which is closed once frames is
+// closed, conversion fails, or the context is done, after which Err reports
+// why.
+func (c *Converter) Watch(ctx context.Context, frames <-chan mccdaq.Frame) <-chan mccdaq.Frame {
+	return c.watcher.WatchFrames(ctx, frames, c.Process)
+}
+
+// Err returns the error that stopped Watch, if any.
+func (c *Converter) Err() error {
+	return c.watcher.Err()
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package sensor
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/gotmc/mccdaq"
+	"github.com/gotmc/mccdaq/alarm"
+)
+
+// newFrame returns a frame of a current loop on channel 0, measured across a
+// 250 Ω shunt, and an unconverted channel 1.
+func newFrame(index uint64, milliamps ...float64) mccdaq.Frame {
+	frame := mccdaq.Frame{
+		Index:    index,
+		Channels: []int{0, 1},
+		Raw:      [][]uint16{make([]uint16, len(milliamps)), make([]uint16, len(milliamps))},
+		Volts:    [][]float64{make([]float64, len(milliamps)), make([]float64, len(milliamps))},
+	}
+	for i, ma := range milliamps {
+		frame.Volts[0][i] = ma * 1e-3 * 250
+		frame.Volts[1][i] = float64(i)
+	}
+	return frame
+}
+
+func TestConverter(t *testing.T) {
+	var faults []Fault
+	c, err := New(map[int]Config{0: loop}, WithHandler(func(f Fault) {
+		faults = append(faults, f)
+	}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	converted, err := c.Process(newFrame(0, 4, 12, 0, 0, 3))
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	for scan, want := range []float64{0, 75} {
+		if got := converted.Volts[0][scan]; math.Abs(got-want) > 1e-9 {
+			t.Errorf("Expected scan %d to read %v, got %v", scan, want, got)
+		}
+	}
+	for scan, want := range []float64{math.Inf(1), math.Inf(1), math.Inf(-1)} {
+		if got := converted.Volts[0][scan+2]; got != want {
+			t.Errorf("Expected %v for the faulted scan %d, got %v", want, scan+2, got)
+		}
+	}
+	if got := converted.Volts[1][2]; got != 2 {
+		t.Errorf("Expected channel 1 in volts, got %v", got)
+	}
+	if len(faults) != 2 || faults[0].Condition != Open || faults[0].Scan != 2 ||
+		faults[1].Condition != UnderRange || faults[1].Scan != 4 {
+		t.Fatalf("Expected open at scan 2 then under range at scan 4, got %+v", faults)
+	}
+	if got := c.Faults(); len(got) != 1 || got[0].Condition != UnderRange {
+		t.Errorf("Expected the loop to be under range, got %+v", got)
+	}
+	if _, err := c.Process(newFrame(5, 3, 20)); err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if len(faults) != 3 || faults[2].Condition != OK || faults[2].Scan != 6 {
+		t.Errorf("Expected the fault to clear at scan 6, got %+v", faults)
+	}
+	if got := c.Faults(); len(got) != 0 {
+		t.Errorf("Expected no faults, got %+v", got)
+	}
+}
+
+func TestConverterErrors(t *testing.T) {
+	if _, err := New(nil); err == nil {
+		t.Errorf("Expected an error without sensors")
+	}
+	if _, err := New(map[int]Config{0: {Type: CurrentLoop}}); err == nil {
+		t.Errorf("Expected an error for an invalid config")
+	}
+	c, err := New(map[int]Config{3: loop})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := c.Process(newFrame(0, 4)); !errors.Is(err, mccdaq.ErrInvalidChannel) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrInvalidChannel, err)
+	}
+}
+
+func TestConverterWatch(t *testing.T) {
+	c, err := New(map[int]Config{0: loop})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	frames := make(chan mccdaq.Frame, 2)
+	frames <- newFrame(0, 20)
+	frames <- newFrame(1, 4)
+	close(frames)
+	var got []float64
+	for frame := range c.Watch(context.Background(), frames) {
+		got = append(got, frame.Volts[0][0])
+	}
+	if err := c.Err(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(got) != 2 || got[0] != 150 || got[1] != 0 {
+		t.Errorf("Expected [150 0], got %v", got)
+	}
+}
+
+func TestConverterTripsAlarm(t *testing.T) {
+	c, err := New(map[int]Config{0: loop})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	high, low := 140.0, 10.0
+	e, err := alarm.New(map[int]alarm.Limits{0: {High: &high, Low: &low}})
+	if err != nil {
+		t.Fatalf("alarm.New: %v", err)
+	}
+	// A healthy reading, a broken wire, a recovery, and a shorted transmitter
+	// pulling the loop under range.
+	converted, err := c.Process(newFrame(0, 12, 0, 12, 3))
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	events, err := e.Process(converted)
+	if err != nil {
+		t.Fatalf("alarm Process: %v", err)
+	}
+	type summary struct {
+		kind   alarm.Kind
+		raised bool
+		scan   uint64
+	}
+	want := []summary{
+		{alarm.High, true, 1},
+		{alarm.High, false, 2},
+		{alarm.Low, true, 3},
+	}
+	var got []summary
+	for _, event := range events {
+		got = append(got, summary{event.Kind, event.Raised, event.Scan})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected alarm events %+v, got %+v", want, got)
+	}
+}
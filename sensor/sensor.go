@@ -0,0 +1,389 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+// Package sensor converts the voltages of thermistors, RTDs, and 4–20 mA
+// current loops into temperature or engineering units, detecting open and
+// shorted wiring and out of range loops the same way for every type of
+// sensor.
+package sensor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// Type is a type of sensor.
+type Type int
+
+// Available sensor types.
+const (
+	// Thermistor is converted into °C by the beta model or, if its
+	// SteinhartHart coefficients are set, the Steinhart-Hart equation.
+	Thermistor Type = iota
+	// RTD is a platinum resistance thermometer converted into °C by the
+	// Callendar–Van Dusen equation.
+	RTD
+	// CurrentLoop is a 4–20 mA transmitter measured across a shunt resistor,
+	// converted linearly into the units of Low and High.
+	CurrentLoop
+)
+
+var types = map[Type]string{
+	Thermistor:  "thermistor",
+	RTD:         "rtd",
+	CurrentLoop: "current_loop",
+}
+
+// String implements the Stringer interface for Type.
+func (t Type) String() string {
+	return types[t]
+}
+
+// UnmarshalJSON implements the Unmarshaler interface for Type by taking the
+// string form of a Type.
+func (t *Type) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("sensor type should be a string, got %s", data)
+	}
+	for typ, name := range types {
+		if name == s {
+			*t = typ
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid string %q for sensor Type", s)
+}
+
+// MarshalJSON implements the Marshaler interface for Type.
+func (t Type) MarshalJSON() ([]byte, error) {
+	return json.Marshal(types[t])
+}
+
+// Circuit is how a thermistor or RTD is excited so that its resistance can be
+// measured.
+type Circuit int
+
+// Available excitation circuits.
+const (
+	// Divider puts the sensor in series with the Reference resistor across
+	// the Excitation voltage, and measures the voltage across the sensor, or
+	// across the Reference resistor if HighSide is set.
+	Divider Circuit = iota
+	// CurrentSource drives the Excitation current in amps through the sensor
+	// and measures the voltage across it.
+	CurrentSource
+)
+
+var circuits = map[Circuit]string{
+	Divider:       "divider",
+	CurrentSource: "current_source",
+}
+
+// String implements the Stringer interface for Circuit.
+func (c Circuit) String() string {
+	return circuits[c]
+}
+
+// UnmarshalJSON implements the Unmarshaler interface for Circuit by taking
+// the string form of a Circuit.
+func (c *Circuit) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("sensor circuit should be a string, got %s", data)
+	}
+	for circuit, name := range circuits {
+		if name == s {
+			*c = circuit
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid string %q for sensor Circuit", s)
+}
+
+// MarshalJSON implements the Marshaler interface for Circuit.
+func (c Circuit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(circuits[c])
+}
+
+// The IEC 60751 Callendar–Van Dusen coefficients of industrial platinum RTDs.
+var iec60751 = []float64{3.9083e-03, -5.775e-07, -4.183e-12}
+
+// The NAMUR NE 43 limits in amps of a 4–20 mA loop, outside of which the
+// current signals a failure rather than a measurement. Below brokenLoop the
+// loop is taken to be open.
+const (
+	brokenLoop   = 1e-3
+	loopLow      = 3.6e-3
+	loopHigh     = 21e-3
+	loopZero     = 4e-3
+	loopFullSpan = 16e-3
+)
+
+// Config configures the conversion of one sensor channel. Only the fields used
+// by its Type need be set, as in the JSON
+//
+//	{"type": "thermistor", "r0": 10000, "beta": 3950, "excitation": 2.5, "reference": 10000}
+//	{"type": "rtd", "r0": 100, "circuit": "current_source", "excitation": 0.001}
+//	{"type": "current_loop", "shunt": 250, "low": 0, "high": 150}
+type Config struct {
+	Type Type `json:"type"`
+	// R0 is a thermistor's resistance in Ω at T0, or an RTD's at 0 °C.
+	R0 float64 `json:"r0,omitempty"`
+	// T0 is the temperature in °C at which a thermistor's resistance is R0,
+	// and is 25 °C if zero.
+	T0   float64 `json:"t0,omitempty"`
+	Beta float64 `json:"beta,omitempty"`
+	// SteinhartHart holds the coefficients A, B, and C of a thermistor in
+	// 1/T = A + B ln R + C (ln R)³, with T in kelvin.
+	SteinhartHart []float64 `json:"steinhart_hart,omitempty"`
+	// CVD holds the Callendar–Van Dusen coefficients A, B, and C of an RTD,
+	// and defaults to those of IEC 60751.
+	CVD []float64 `json:"cvd,omitempty"`
+	// Circuit, Excitation, Reference, and HighSide describe how a thermistor
+	// or RTD is excited.
+	Circuit    Circuit `json:"circuit,omitempty"`
+	Excitation float64 `json:"excitation,omitempty"`
+	Reference  float64 `json:"reference,omitempty"`
+	HighSide   bool    `json:"high_side,omitempty"`
+	// OpenAbove and ShortBelow are the resistances in Ω of a thermistor or
+	// RTD beyond which its wiring is taken to be open or shorted. They
+	// default to 5*R0 and R0/10 for an RTD, and 1000*R0 and R0/1000 for a
+	// thermistor, or 10 MΩ and 1 Ω for one without R0.
+	OpenAbove  float64 `json:"open_above,omitempty"`
+	ShortBelow float64 `json:"short_below,omitempty"`
+	// Shunt is the resistance in Ω across which a current loop is measured,
+	// and Low and High are the values it represents at 4 and 20 mA.
+	Shunt float64 `json:"shunt,omitempty"`
+	Low   float64 `json:"low,omitempty"`
+	High  float64 `json:"high,omitempty"`
+}
+
+// Validate checks that the config has what its Type needs.
+func (c Config) Validate() error {
+	switch c.Type {
+	case Thermistor:
+		if len(c.SteinhartHart) != 0 && len(c.SteinhartHart) != 3 {
+			return fmt.Errorf("thermistor needs 3 Steinhart-Hart coefficients, not %d", len(c.SteinhartHart))
+		}
+		if len(c.SteinhartHart) == 0 && (c.R0 <= 0 || c.Beta <= 0) {
+			return fmt.Errorf("thermistor needs a positive r0 and beta, or Steinhart-Hart coefficients")
+		}
+		return c.validateCircuit()
+	case RTD:
+		if c.R0 <= 0 {
+			return fmt.Errorf("RTD needs a positive r0, not %g", c.R0)
+		}
+		if len(c.CVD) != 0 && len(c.CVD) != 3 {
+			return fmt.Errorf("RTD needs 3 Callendar–Van Dusen coefficients, not %d", len(c.CVD))
+		}
+		return c.validateCircuit()
+	case CurrentLoop:
+		if c.Shunt <= 0 {
+			return fmt.Errorf("current loop needs a positive shunt resistance, not %g", c.Shunt)
+		}
+		if c.Low == c.High {
+			return fmt.Errorf("current loop needs different low and high values")
+		}
+		return nil
+	}
+	return fmt.Errorf("bad sensor type %d: %w", c.Type, mccdaq.ErrNotSupported)
+}
+
+func (c Config) validateCircuit() error {
+	switch c.Circuit {
+	case Divider:
+		if c.Excitation <= 0 || c.Reference <= 0 {
+			return fmt.Errorf("%s divider needs a positive excitation and reference resistance", c.Type)
+		}
+	case CurrentSource:
+		if c.Excitation <= 0 {
+			return fmt.Errorf("%s current source needs a positive excitation", c.Type)
+		}
+	default:
+		return fmt.Errorf("bad sensor circuit %d: %w", c.Circuit, mccdaq.ErrNotSupported)
+	}
+	return nil
+}
+
+// Unit returns the unit of the converted values, which is "°C" for a
+// thermistor or RTD, and empty for a current loop, whose unit is that of Low
+// and High.
+func (c Config) Unit() string {
+	if c.Type == CurrentLoop {
+		return ""
+	}
+	return "°C"
+}
+
+// Convert converts the volts measured from the sensor into °C or the units of
+// a current loop, along with the condition of the sensor. A faulted sensor
+// converts into +Inf if it's open or over range, and -Inf if it's shorted or
+// under range, so that alarm limits trip on it, as they do on an open
+// thermocouple.
+func (c Config) Convert(volts float64) (float64, Condition) {
+	signal := c.Signal(volts)
+	condition := c.condition(signal)
+	switch condition {
+	case Open, OverRange:
+		return math.Inf(1), condition
+	case Short, UnderRange:
+		return math.Inf(-1), condition
+	}
+	switch c.Type {
+	case Thermistor:
+		return c.thermistor(signal), OK
+	case RTD:
+		return c.rtd(signal), OK
+	default:
+		return c.Low + (signal-loopZero)/loopFullSpan*(c.High-c.Low), OK
+	}
+}
+
+// Signal returns what the sensor presents for the volts measured from it: the
+// resistance in Ω of a thermistor or RTD, which is +Inf for a divider reading
+// at the rail with the sensor open, or the current in amps of a current loop.
+func (c Config) Signal(volts float64) float64 {
+	if c.Type == CurrentLoop {
+		return volts / c.Shunt
+	}
+	if c.Circuit == CurrentSource {
+		return math.Max(volts/c.Excitation, 0)
+	}
+	sensor, reference := volts, c.Excitation-volts
+	if c.HighSide {
+		sensor, reference = reference, sensor
+	}
+	switch {
+	case reference <= 0:
+		return math.Inf(1)
+	case sensor <= 0:
+		return 0
+	}
+	return c.Reference * sensor / reference
+}
+
+// condition returns the condition of the sensor presenting the signal.
+func (c Config) condition(signal float64) Condition {
+	if math.IsNaN(signal) {
+		return Open
+	}
+	if c.Type == CurrentLoop {
+		switch {
+		case signal < brokenLoop:
+			return Open
+		case signal < loopLow:
+			return UnderRange
+		case signal > loopHigh:
+			return OverRange
+		}
+		return OK
+	}
+	open, short := c.OpenAbove, c.ShortBelow
+	switch {
+	case c.Type == RTD:
+		open, short = orDefault(open, 5*c.R0), orDefault(short, c.R0/10)
+	case c.R0 > 0:
+		open, short = orDefault(open, 1000*c.R0), orDefault(short, c.R0/1000)
+	default:
+		open, short = orDefault(open, 10e6), orDefault(short, 1)
+	}
+	switch {
+	case signal > open:
+		return Open
+	case signal < short:
+		return Short
+	}
+	return OK
+}
+
+func orDefault(v, def float64) float64 {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+// kelvin is 0 °C in kelvin.
+const kelvin = 273.15
+
+// thermistor returns the temperature in °C of a thermistor of the given
+// resistance.
+func (c Config) thermistor(r float64) float64 {
+	if len(c.SteinhartHart) == 3 {
+		l := math.Log(r)
+		a, b, cc := c.SteinhartHart[0], c.SteinhartHart[1], c.SteinhartHart[2]
+		return 1/(a+b*l+cc*l*l*l) - kelvin
+	}
+	t0 := c.T0
+	if t0 == 0 {
+		t0 = 25
+	}
+	return 1/(1/(t0+kelvin)+math.Log(r/c.R0)/c.Beta) - kelvin
+}
+
+// Resistance returns the resistance in Ω of a thermistor or RTD at the given
+// temperature in °C, which is useful for checking a configuration against
+// the sensor's data sheet.
+func (c Config) Resistance(celsius float64) (float64, error) {
+	switch c.Type {
+	case Thermistor:
+		t := celsius + kelvin
+		if len(c.SteinhartHart) == 3 {
+			// Solve the cubic in ln R by Cardano's method.
+			a, b, cc := c.SteinhartHart[0], c.SteinhartHart[1], c.SteinhartHart[2]
+			x := (a - 1/t) / (2 * cc)
+			y := math.Sqrt(math.Pow(b/(3*cc), 3) + x*x)
+			return math.Exp(math.Cbrt(y-x) - math.Cbrt(y+x)), nil
+		}
+		t0 := c.T0
+		if t0 == 0 {
+			t0 = 25
+		}
+		return c.R0 * math.Exp(c.Beta*(1/t-1/(t0+kelvin))), nil
+	case RTD:
+		a, b, cc := c.cvd()
+		r := 1 + a*celsius + b*celsius*celsius
+		if celsius < 0 {
+			r += cc * (celsius - 100) * celsius * celsius * celsius
+		}
+		return c.R0 * r, nil
+	}
+	return 0, fmt.Errorf("%s has no resistance: %w", c.Type, mccdaq.ErrNotSupported)
+}
+
+func (c Config) cvd() (a, b, cc float64) {
+	coefficients := c.CVD
+	if len(coefficients) != 3 {
+		coefficients = iec60751
+	}
+	return coefficients[0], coefficients[1], coefficients[2]
+}
+
+// rtd returns the temperature in °C of an RTD of the given resistance. At and
+// above 0 °C the Callendar–Van Dusen equation is a quadratic solved directly,
+// and below 0 °C it's solved by Newton's method starting from the quadratic's
+// solution.
+func (c Config) rtd(r float64) float64 {
+	a, b, cc := c.cvd()
+	ratio := r / c.R0
+	t := (-a + math.Sqrt(a*a-4*b*(1-ratio))) / (2 * b)
+	if ratio >= 1 {
+		return t
+	}
+	for i := 0; i < 20; i++ {
+		f := 1 + a*t + b*t*t + cc*(t-100)*t*t*t - ratio
+		df := a + 2*b*t + cc*(4*t-300)*t*t
+		step := f / df
+		t -= step
+		if math.Abs(step) < 1e-9 {
+			break
+		}
+	}
+	return t
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package sensor
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+var (
+	pt100 = Config{Type: RTD, R0: 100, Circuit: CurrentSource, Excitation: 1e-3}
+	ntc   = Config{Type: Thermistor, R0: 10000, Beta: 3950, Excitation: 2.5, Reference: 10000}
+	sh    = Config{
+		Type:          Thermistor,
+		SteinhartHart: []float64{1.009249522e-3, 2.378405444e-4, 2.019202697e-7},
+		Circuit:       CurrentSource,
+		Excitation:    10e-6,
+	}
+	loop = Config{Type: CurrentLoop, Shunt: 250, Low: 0, High: 150}
+)
+
+func TestRTDResistance(t *testing.T) {
+	// IEC 60751 table values for a Pt100.
+	testCases := []struct {
+		celsius float64
+		ohms    float64
+	}{
+		{-200, 18.5201},
+		{-100, 60.2558},
+		{0, 100},
+		{100, 138.5055},
+		{850, 390.4811},
+	}
+	for _, tc := range testCases {
+		got, err := pt100.Resistance(tc.celsius)
+		if err != nil {
+			t.Fatalf("Resistance: %v", err)
+		}
+		if math.Abs(got-tc.ohms) > 1e-4 {
+			t.Errorf("Expected %v Ω at %v °C, got %v", tc.ohms, tc.celsius, got)
+		}
+		value, condition := pt100.Convert(tc.ohms * pt100.Excitation)
+		if condition != OK || math.Abs(value-tc.celsius) > 1e-3 {
+			t.Errorf("Expected %v °C (ok), got %v (%s)", tc.celsius, value, condition)
+		}
+	}
+}
+
+func TestThermistor(t *testing.T) {
+	testCases := []struct {
+		name    string
+		config  Config
+		celsius float64
+	}{
+		{"beta at t0", ntc, 25},
+		{"beta cold", ntc, -20},
+		{"beta hot", ntc, 100},
+		{"steinhart-hart cold", sh, -20},
+		{"steinhart-hart at 25 °C", sh, 25},
+		{"steinhart-hart hot", sh, 100},
+	}
+	for _, tc := range testCases {
+		for _, highSide := range []bool{false, true} {
+			config := tc.config
+			config.HighSide = highSide
+			r, err := config.Resistance(tc.celsius)
+			if err != nil {
+				t.Fatalf("Resistance: %v", err)
+			}
+			var volts float64
+			switch {
+			case config.Circuit == CurrentSource:
+				volts = r * config.Excitation
+			case highSide:
+				volts = config.Excitation * config.Reference / (r + config.Reference)
+			default:
+				volts = config.Excitation * r / (r + config.Reference)
+			}
+			got, condition := config.Convert(volts)
+			if condition != OK || math.Abs(got-tc.celsius) > 1e-6 {
+				t.Errorf("%s: Expected %v °C (ok), got %v (%s)", tc.name, tc.celsius, got, condition)
+			}
+		}
+	}
+	if r, _ := ntc.Resistance(25); math.Abs(r-10000) > 1e-9 {
+		t.Errorf("Expected 10 kΩ at 25 °C, got %v", r)
+	}
+}
+
+func TestCurrentLoop(t *testing.T) {
+	testCases := []struct {
+		milliamps float64
+		value     float64
+		condition Condition
+	}{
+		{4, 0, OK},
+		{12, 75, OK},
+		{20, 150, OK},
+		{3.8, -1.875, OK},
+		{20.5, 154.6875, OK},
+		{0, math.Inf(1), Open},
+		{0.5, math.Inf(1), Open},
+		{3, math.Inf(-1), UnderRange},
+		{22, math.Inf(1), OverRange},
+	}
+	for _, tc := range testCases {
+		got, condition := loop.Convert(tc.milliamps * 1e-3 * loop.Shunt)
+		if condition != tc.condition {
+			t.Errorf("Expected %v mA to be %s, got %s", tc.milliamps, tc.condition, condition)
+		}
+		if got != tc.value && math.Abs(got-tc.value) > 1e-9 {
+			t.Errorf("Expected %v mA to read %v, got %v", tc.milliamps, tc.value, got)
+		}
+	}
+}
+
+func TestResistiveFaults(t *testing.T) {
+	highSide := ntc
+	highSide.HighSide = true
+	testCases := []struct {
+		name      string
+		config    Config
+		volts     float64
+		condition Condition
+	}{
+		{"divider at the rail", ntc, 2.5, Open},
+		{"divider above the rail", ntc, 2.6, Open},
+		{"divider at ground", ntc, 0, Short},
+		{"divider below ground", ntc, -0.01, Short},
+		{"high side at ground", highSide, 0, Open},
+		{"high side at the rail", highSide, 2.5, Short},
+		{"rtd open", pt100, 1, Open},
+		{"rtd short", pt100, 0.001, Short},
+		{"rtd no current", pt100, -0.001, Short},
+	}
+	for _, tc := range testCases {
+		want := math.Inf(1)
+		if tc.condition == Short {
+			want = math.Inf(-1)
+		}
+		got, condition := tc.config.Convert(tc.volts)
+		if condition != tc.condition || got != want {
+			t.Errorf("%s: Expected %v (%s), got %v (%s)", tc.name, want, tc.condition, got, condition)
+		}
+	}
+	custom := pt100
+	custom.OpenAbove = 200
+	if _, condition := custom.Convert(0.3); condition != Open {
+		t.Errorf("Expected 300 Ω to be open above 200 Ω, got %s", condition)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config Config
+		valid  bool
+	}{
+		{"beta", ntc, true},
+		{"steinhart-hart", sh, true},
+		{"rtd", pt100, true},
+		{"loop", loop, true},
+		{"thermistor without beta", Config{Type: Thermistor, R0: 10000, Excitation: 2.5, Reference: 10000}, false},
+		{"two steinhart-hart", Config{Type: Thermistor, SteinhartHart: []float64{1, 2}, Excitation: 1, Reference: 1}, false},
+		{"divider without reference", Config{Type: RTD, R0: 100, Excitation: 5}, false},
+		{"current source without current", Config{Type: RTD, R0: 100, Circuit: CurrentSource}, false},
+		{"rtd without r0", Config{Type: RTD, Circuit: CurrentSource, Excitation: 1e-3}, false},
+		{"loop without shunt", Config{Type: CurrentLoop, High: 100}, false},
+		{"loop without span", Config{Type: CurrentLoop, Shunt: 250, Low: 5, High: 5}, false},
+		{"bad type", Config{Type: Type(9)}, false},
+	}
+	for _, tc := range testCases {
+		if err := tc.config.Validate(); (err == nil) != tc.valid {
+			t.Errorf("%s: Expected valid %v, got error %v", tc.name, tc.valid, err)
+		}
+	}
+}
+
+func TestConfigJSON(t *testing.T) {
+	data := `{"type": "rtd", "r0": 1000, "circuit": "current_source", "excitation": 0.0005}`
+	var config Config
+	if err := json.Unmarshal([]byte(data), &config); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if config.Type != RTD || config.Circuit != CurrentSource || config.R0 != 1000 {
+		t.Errorf("Expected a Pt1000 on a current source, got %+v", config)
+	}
+	if got := config.Unit(); got != "°C" {
+		t.Errorf("Expected °C, got %q", got)
+	}
+	out, err := json.Marshal(loop)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var again Config
+	if err := json.Unmarshal(out, &again); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if again.Type != CurrentLoop || again.Shunt != 250 || again.High != 150 {
+		t.Errorf("Expected %+v, got %+v", loop, again)
+	}
+	if err := json.Unmarshal([]byte(`{"type": "strain"}`), &config); err == nil {
+		t.Errorf("Expected an error for an unknown type")
+	}
+	if err := json.Unmarshal([]byte(`{"type": "rtd", "circuit": "bridge"}`), &config); err == nil {
+		t.Errorf("Expected an error for an unknown circuit")
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package spectral
+
+import (
+	"fmt"
+	"math"
+)
+
+// Distortion describes how cleanly a block of samples carries a single tone.
+// The ratios are in dB: SNR compares the tone with the noise, THD compares
+// the harmonics with the tone, so it's negative, and SINAD compares the tone
+// with the noise and harmonics together.
+type Distortion struct {
+	Fundamental float64   // Frequency of the tone in Hz
+	Amplitude   float64   // Peak amplitude of the tone in volts
+	Harmonics   []float64 // Peak amplitudes of the 2nd harmonic onward in volts
+	SNR         float64
+	THD         float64
+	SINAD       float64
+}
+
+// Analyze measures the distortion of the strongest tone in the samples, taken
+// at the given rate in Hz, counting the given number of harmonics, including
+// the fundamental, as distortion rather than noise. Harmonics above the
+// Nyquist frequency are counted where they alias. The power of the tone and
+// each harmonic is summed over the window's main lobe, and everything else
+// above the DC lobe counts as noise, so use a window with low sidelobes, such
+// as BlackmanHarris, unless the tone is coherently sampled.
+func Analyze(x []float64, rate float64, w Window, harmonics int) (Distortion, error) {
+	if harmonics < 1 {
+		return Distortion{}, fmt.Errorf("bad number of harmonics %d", harmonics)
+	}
+	s, err := NewSpectrum(removeMean(x), rate, w)
+	if err != nil {
+		return Distortion{}, err
+	}
+	hw := w.halfWidth()
+	peak := s.peak(hw)
+	if peak < 0 {
+		return Distortion{}, fmt.Errorf("no tone in %d samples", len(x))
+	}
+	used := make([]bool, len(s.Power))
+	for k := 0; k <= hw && k < len(used); k++ {
+		used[k] = true
+	}
+	fundamental := s.Frequency(peak) + s.interpolate(peak)*s.Resolution
+	d := Distortion{Fundamental: fundamental}
+	signal := s.lobePower(peak, hw, used)
+	d.Amplitude = math.Sqrt(2 * signal)
+	distortion := 0.0
+	for h := 2; h <= harmonics; h++ {
+		bin := int(math.Round(alias(float64(h)*fundamental, rate) / s.Resolution))
+		power := s.lobePower(bin, hw, used)
+		d.Harmonics = append(d.Harmonics, math.Sqrt(2*power))
+		distortion += power
+	}
+	noise := 0.0
+	for k, p := range s.Power {
+		if !used[k] {
+			noise += p
+		}
+	}
+	d.SNR = decibels(signal / noise)
+	d.THD = decibels(distortion / signal)
+	d.SINAD = decibels(signal / (noise + distortion))
+	return d, nil
+}
+
+// lobePower returns the power in the bins within halfWidth of the given bin
+// not already used, and marks them used.
+func (s Spectrum) lobePower(bin, halfWidth int, used []bool) float64 {
+	power := 0.0
+	for k := bin - halfWidth; k <= bin+halfWidth; k++ {
+		if k >= 0 && k < len(used) && !used[k] {
+			power += s.Power[k]
+			used[k] = true
+		}
+	}
+	return power
+}
+
+// alias returns the frequency at which the given frequency appears when
+// sampled at the rate.
+func alias(freq, rate float64) float64 {
+	freq = math.Mod(freq, rate)
+	if freq > rate/2 {
+		freq = rate - freq
+	}
+	return freq
+}
+
+func decibels(ratio float64) float64 {
+	return 10 * math.Log10(ratio)
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package spectral
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// FFT returns the discrete Fourier transform of x, which may be any length.
+// Lengths that are powers of two use the radix-2 algorithm directly, and
+// other lengths use Bluestein's algorithm, so every length takes O(n log n)
+// time.
+func FFT(x []complex128) []complex128 {
+	n := len(x)
+	out := append([]complex128{}, x...)
+	if n <= 1 {
+		return out
+	}
+	if n&(n-1) == 0 {
+		radix2(out, false)
+		return out
+	}
+	return bluestein(out)
+}
+
+// radix2 transforms x in place, whose length must be a power of two, or
+// computes the unnormalized inverse transform if inverse is true.
+func radix2(x []complex128, inverse bool) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Rect(1, sign*2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				a, b := x[start+k], x[start+k+size/2]*w
+				x[start+k], x[start+k+size/2] = a+b, a-b
+				w *= step
+			}
+		}
+	}
+}
+
+// bluestein transforms x by expressing the transform as a convolution with a
+// chirp, which is computed with power of two transforms.
+func bluestein(x []complex128) []complex128 {
+	n := len(x)
+	m := 1
+	for m < 2*n-1 {
+		m <<= 1
+	}
+	// The chirp's phase repeats every 2n, so reduce k² modulo 2n to keep it
+	// accurate for large k.
+	chirp := make([]complex128, n)
+	for k := range chirp {
+		k2 := (k * k) % (2 * n)
+		chirp[k] = cmplx.Rect(1, -math.Pi*float64(k2)/float64(n))
+	}
+	a := make([]complex128, m)
+	b := make([]complex128, m)
+	for k := 0; k < n; k++ {
+		a[k] = x[k] * chirp[k]
+	}
+	b[0] = cmplx.Conj(chirp[0])
+	for k := 1; k < n; k++ {
+		b[k] = cmplx.Conj(chirp[k])
+		b[m-k] = b[k]
+	}
+	radix2(a, false)
+	radix2(b, false)
+	for i := range a {
+		a[i] *= b[i]
+	}
+	radix2(a, true)
+	out := make([]complex128, n)
+	for k := range out {
+		out[k] = a[k] * chirp[k] / complex(float64(m), 0)
+	}
+	return out
+}
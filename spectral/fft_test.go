@@ -0,0 +1,43 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package spectral
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func dft(x []complex128) []complex128 {
+	n := len(x)
+	out := make([]complex128, n)
+	for k := range out {
+		for i, v := range x {
+			out[k] += v * cmplx.Rect(1, -2*math.Pi*float64(k*i)/float64(n))
+		}
+	}
+	return out
+}
+
+func TestFFT(t *testing.T) {
+	testCases := []int{1, 2, 8, 12, 17, 64, 100}
+	for _, n := range testCases {
+		x := make([]complex128, n)
+		for i := range x {
+			x[i] = complex(math.Sin(float64(i*i)+0.3), math.Cos(float64(3*i)))
+		}
+		got := FFT(x)
+		want := dft(x)
+		if len(got) != n {
+			t.Fatalf("Expected %d bins, got %d", n, len(got))
+		}
+		for k := range want {
+			if cmplx.Abs(got[k]-want[k]) > 1e-9*float64(n) {
+				t.Errorf("Expected bin %d of %d-point FFT %v, got %v", k, n, want[k], got[k])
+			}
+		}
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+// Package spectral analyzes the spectrum of acquired channel data in pure Go:
+// windowed FFTs, Welch power spectral density, the dominant frequency, and
+// the SNR, THD, and SINAD of a tone. The sample rate is the analog input's
+// scan rate, which Samples takes from a frame's timebase.
+package spectral
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// Samples returns the voltages of the given channel number from the frame,
+// and the scan rate in Hz from the frame's timebase.
+func Samples(frame mccdaq.Frame, channel int) ([]float64, float64, error) {
+	if frame.Timebase.Period <= 0 {
+		return nil, 0, fmt.Errorf("frame has no scan period: %w", mccdaq.ErrInvalidFrequency)
+	}
+	rate := 1 / frame.Timebase.Period.Seconds()
+	for i, ch := range frame.Channels {
+		if ch == channel {
+			return frame.Volts[i], rate, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("channel %d not in frame: %w", channel, mccdaq.ErrInvalidChannel)
+}
+
+// Spectrum is the one-sided spectrum of a block of samples, with a bin every
+// Resolution Hz from DC up to the Nyquist frequency.
+type Spectrum struct {
+	Rate       float64 // Sample rate in Hz
+	Resolution float64 // Spacing of the bins in Hz
+	Window     Window
+	// Amplitude is the peak amplitude in volts of a tone centered in each
+	// bin, corrected for the window's coherent gain.
+	Amplitude []float64
+	// Power is the power in V² in each bin, so that summing it over a band
+	// gives the power of the signal in that band, whether tones or noise.
+	Power []float64
+}
+
+// NewSpectrum computes the spectrum of the samples, taken at the given rate in
+// Hz, with the given window.
+func NewSpectrum(x []float64, rate float64, w Window) (Spectrum, error) {
+	if err := check(x, rate); err != nil {
+		return Spectrum{}, err
+	}
+	n := len(x)
+	c := w.Coefficients(n)
+	var sum, sumSq float64
+	for _, v := range c {
+		sum += v
+		sumSq += v * v
+	}
+	X := transform(x, c)
+	s := Spectrum{
+		Rate:       rate,
+		Resolution: rate / float64(n),
+		Window:     w,
+		Amplitude:  make([]float64, len(X)),
+		Power:      make([]float64, len(X)),
+	}
+	for k, v := range X {
+		mag := cmplx.Abs(v)
+		// Every bin but DC and Nyquist folds in its negative frequency.
+		scale := 2.0
+		if k == 0 || (n%2 == 0 && k == n/2) {
+			scale = 1
+		}
+		s.Amplitude[k] = scale * mag / sum
+		s.Power[k] = scale * mag * mag / (float64(n) * sumSq)
+	}
+	return s, nil
+}
+
+// Frequency returns the frequency in Hz of the given bin.
+func (s Spectrum) Frequency(bin int) float64 {
+	return float64(bin) * s.Resolution
+}
+
+// transform windows the samples and returns the one-sided transform.
+func transform(x, c []float64) []complex128 {
+	z := make([]complex128, len(x))
+	for i, v := range x {
+		z[i] = complex(v*c[i], 0)
+	}
+	return FFT(z)[:len(x)/2+1]
+}
+
+// PSD is a one-sided power spectral density estimate, with a bin every
+// Resolution Hz from DC up to the Nyquist frequency.
+type PSD struct {
+	Rate       float64   // Sample rate in Hz
+	Resolution float64   // Spacing of the bins in Hz
+	Density    []float64 // Power spectral density in V²/Hz
+	Segments   int       // Number of segments averaged
+}
+
+// Welch estimates the power spectral density of the samples, taken at the
+// given rate in Hz, by Welch's method: the samples are split into segments of
+// the given length that overlap by the given fraction, each segment is
+// windowed after removing its mean, and the segments' periodograms are
+// averaged. Longer segments give finer resolution, and more segments give a
+// smoother estimate. A Hann window overlapped by half is the usual choice.
+func Welch(x []float64, rate float64, segment int, overlap float64, w Window) (PSD, error) {
+	if err := check(x, rate); err != nil {
+		return PSD{}, err
+	}
+	if segment < 2 || segment > len(x) {
+		return PSD{}, fmt.Errorf("segment length %d must be from 2 to the %d samples", segment, len(x))
+	}
+	if overlap < 0 || overlap >= 1 {
+		return PSD{}, fmt.Errorf("segment overlap %g must be from 0 up to 1", overlap)
+	}
+	step := int(float64(segment) * (1 - overlap))
+	if step < 1 {
+		step = 1
+	}
+	c := w.Coefficients(segment)
+	sumSq := 0.0
+	for _, v := range c {
+		sumSq += v * v
+	}
+	psd := PSD{
+		Rate:       rate,
+		Resolution: rate / float64(segment),
+		Density:    make([]float64, segment/2+1),
+	}
+	seg := make([]float64, segment)
+	for start := 0; start+segment <= len(x); start += step {
+		mean := 0.0
+		for _, v := range x[start : start+segment] {
+			mean += v
+		}
+		mean /= float64(segment)
+		for i, v := range x[start : start+segment] {
+			seg[i] = v - mean
+		}
+		for k, v := range transform(seg, c) {
+			mag := cmplx.Abs(v)
+			scale := 2.0
+			if k == 0 || (segment%2 == 0 && k == segment/2) {
+				scale = 1
+			}
+			psd.Density[k] += scale * mag * mag / (rate * sumSq)
+		}
+		psd.Segments++
+	}
+	for k := range psd.Density {
+		psd.Density[k] /= float64(psd.Segments)
+	}
+	return psd, nil
+}
+
+// Frequency returns the frequency in Hz of the given bin.
+func (p PSD) Frequency(bin int) float64 {
+	return float64(bin) * p.Resolution
+}
+
+// BandPower returns the power in V² between the given frequencies, summing
+// the bins whose frequencies lie in the band.
+func (p PSD) BandPower(low, high float64) float64 {
+	power := 0.0
+	for k, d := range p.Density {
+		if f := p.Frequency(k); f >= low && f <= high {
+			power += d * p.Resolution
+		}
+	}
+	return power
+}
+
+// DominantFrequency returns the frequency in Hz of the strongest tone in the
+// samples, taken at the given rate in Hz, ignoring DC. The frequency is
+// interpolated between bins from the shape of the peak, which locates it to
+// within a few hundredths of a bin with the Hann or BlackmanHarris window,
+// but only to within about a tenth of a bin with the broad FlatTop window.
+func DominantFrequency(x []float64, rate float64, w Window) (float64, error) {
+	s, err := NewSpectrum(removeMean(x), rate, w)
+	if err != nil {
+		return 0, err
+	}
+	peak := s.peak(w.halfWidth())
+	if peak < 0 {
+		return 0, fmt.Errorf("no tone in %d samples", len(x))
+	}
+	return s.Frequency(peak) + s.interpolate(peak)*s.Resolution, nil
+}
+
+// peak returns the bin with the most power above the DC lobe, or -1 if there
+// are no such bins.
+func (s Spectrum) peak(halfWidth int) int {
+	peak := -1
+	for k := halfWidth + 1; k < len(s.Power); k++ {
+		if peak < 0 || s.Power[k] > s.Power[peak] {
+			peak = k
+		}
+	}
+	return peak
+}
+
+// interpolate returns the offset in bins of a tone from the peak bin, by
+// fitting a Gaussian through the peak and its neighbors.
+func (s Spectrum) interpolate(peak int) float64 {
+	if peak <= 0 || peak+1 >= len(s.Amplitude) {
+		return 0
+	}
+	a, b, c := s.Amplitude[peak-1], s.Amplitude[peak], s.Amplitude[peak+1]
+	if a <= 0 || b <= 0 || c <= 0 {
+		return 0
+	}
+	la, lb, lc := math.Log(a), math.Log(b), math.Log(c)
+	denom := la - 2*lb + lc
+	if denom == 0 {
+		return 0
+	}
+	return 0.5 * (la - lc) / denom
+}
+
+func removeMean(x []float64) []float64 {
+	mean := 0.0
+	for _, v := range x {
+		mean += v
+	}
+	mean /= float64(len(x))
+	out := make([]float64, len(x))
+	for i, v := range x {
+		out[i] = v - mean
+	}
+	return out
+}
+
+// check checks that there are samples and a valid sample rate.
+func check(x []float64, rate float64) error {
+	if !(rate > 0) || math.IsInf(rate, 0) {
+		return fmt.Errorf("sample rate %g Hz: %w", rate, mccdaq.ErrInvalidFrequency)
+	}
+	if len(x) < 2 {
+		return fmt.Errorf("spectrum needs at least 2 samples, not %d", len(x))
+	}
+	return nil
+}
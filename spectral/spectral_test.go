@@ -0,0 +1,232 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package spectral
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// tone returns n samples at the given rate of a sum of sines, with the given
+// amplitudes at multiples of freq, plus gaussian noise of the given RMS.
+func tone(n int, rate, freq float64, amplitudes []float64, noise float64) []float64 {
+	r := rand.New(rand.NewSource(1))
+	x := make([]float64, n)
+	for i := range x {
+		t := float64(i) / rate
+		for h, a := range amplitudes {
+			x[i] += a * math.Sin(2*math.Pi*float64(h+1)*freq*t+float64(h))
+		}
+		x[i] += noise * r.NormFloat64()
+	}
+	return x
+}
+
+func TestWindowString(t *testing.T) {
+	testCases := []struct {
+		window Window
+		want   string
+	}{
+		{Rectangular, "rectangular"},
+		{Hann, "hann"},
+		{BlackmanHarris, "blackman-harris"},
+		{FlatTop, "flat-top"},
+	}
+	for _, tc := range testCases {
+		if got := tc.window.String(); got != tc.want {
+			t.Errorf("Expected %s, got %s", tc.want, got)
+		}
+	}
+}
+
+func TestWindowENBW(t *testing.T) {
+	testCases := []struct {
+		window Window
+		want   float64
+	}{
+		{Rectangular, 1},
+		{Hann, 1.5},
+		{BlackmanHarris, 2.0044},
+		{FlatTop, 3.7702},
+	}
+	for _, tc := range testCases {
+		if got := tc.window.ENBW(1024); math.Abs(got-tc.want) > 0.001 {
+			t.Errorf("Expected %s ENBW %v, got %v", tc.window, tc.want, got)
+		}
+	}
+}
+
+func TestSpectrumAmplitude(t *testing.T) {
+	// 1 V at 103.3 Hz falls between bins, where only the flat-top window
+	// measures its amplitude accurately.
+	x := tone(4096, 4096, 103.3, []float64{1}, 0)
+	testCases := []struct {
+		window    Window
+		tolerance float64
+	}{
+		{FlatTop, 0.001},
+		{BlackmanHarris, 0.2},
+		{Hann, 0.2},
+	}
+	for _, tc := range testCases {
+		s, err := NewSpectrum(x, 4096, tc.window)
+		if err != nil {
+			t.Fatalf("NewSpectrum: %v", err)
+		}
+		if s.Resolution != 1 || len(s.Amplitude) != 2049 {
+			t.Fatalf("Expected 2049 bins of 1 Hz, got %d of %v Hz", len(s.Amplitude), s.Resolution)
+		}
+		peak := s.Amplitude[103]
+		if math.Abs(peak-1) > tc.tolerance {
+			t.Errorf("Expected %s amplitude 1 V within %v, got %v", tc.window, tc.tolerance, peak)
+		}
+		power := 0.0
+		for _, p := range s.Power {
+			power += p
+		}
+		if math.Abs(power-0.5) > 0.005 {
+			t.Errorf("Expected %s total power 0.5 V², got %v", tc.window, power)
+		}
+	}
+}
+
+func TestWelch(t *testing.T) {
+	// White noise of 0.1 V RMS at 1 kHz has a density of 2·0.01/1000 V²/Hz.
+	x := tone(65536, 1000, 0, nil, 0.1)
+	psd, err := Welch(x, 1000, 1024, 0.5, Hann)
+	if err != nil {
+		t.Fatalf("Welch: %v", err)
+	}
+	if psd.Segments != 127 {
+		t.Errorf("Expected 127 segments, got %d", psd.Segments)
+	}
+	mean := 0.0
+	for _, d := range psd.Density[1 : len(psd.Density)-1] {
+		mean += d
+	}
+	mean /= float64(len(psd.Density) - 2)
+	if math.Abs(mean-2e-5) > 1e-6 {
+		t.Errorf("Expected density 2e-05 V²/Hz, got %v", mean)
+	}
+	if got := psd.BandPower(0, 500); math.Abs(got-0.01) > 0.0005 {
+		t.Errorf("Expected power 0.01 V², got %v", got)
+	}
+}
+
+func TestDominantFrequency(t *testing.T) {
+	x := tone(1000, 1000, 61.37, []float64{0.5, 0.1}, 0.01)
+	for i := range x {
+		x[i] += 2
+	}
+	testCases := []struct {
+		window    Window
+		tolerance float64
+	}{
+		{Hann, 0.05},
+		{BlackmanHarris, 0.05},
+		{FlatTop, 0.15},
+	}
+	for _, tc := range testCases {
+		got, err := DominantFrequency(x, 1000, tc.window)
+		if err != nil {
+			t.Fatalf("DominantFrequency: %v", err)
+		}
+		if math.Abs(got-61.37) > tc.tolerance {
+			t.Errorf("Expected %s dominant frequency 61.37 Hz, got %v", tc.window, got)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	// THD is 20·log10(√(0.01²+0.001²)) and SNR 10·log10(0.5/0.001²).
+	x := tone(8192, 10000, 251.7, []float64{1, 0.01, 0.001}, 0.001)
+	d, err := Analyze(x, 10000, BlackmanHarris, 5)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	testCases := []struct {
+		name      string
+		got, want float64
+		tolerance float64
+	}{
+		{"fundamental", d.Fundamental, 251.7, 0.05},
+		{"amplitude", d.Amplitude, 1, 0.001},
+		{"2nd harmonic", d.Harmonics[0], 0.01, 0.0005},
+		{"THD", d.THD, -39.96, 0.2},
+		{"SNR", d.SNR, 56.99, 0.5},
+		{"SINAD", d.SINAD, 39.87, 0.2},
+	}
+	for _, tc := range testCases {
+		if math.Abs(tc.got-tc.want) > tc.tolerance {
+			t.Errorf("Expected %s %v, got %v", tc.name, tc.want, tc.got)
+		}
+	}
+	if len(d.Harmonics) != 4 {
+		t.Errorf("Expected 4 harmonics, got %d", len(d.Harmonics))
+	}
+}
+
+func TestAlias(t *testing.T) {
+	testCases := []struct {
+		freq, want float64
+	}{
+		{100, 100},
+		{600, 400},
+		{1100, 100},
+		{1900, 100},
+	}
+	for _, tc := range testCases {
+		if got := alias(tc.freq, 1000); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("Expected %v Hz to alias to %v Hz, got %v", tc.freq, tc.want, got)
+		}
+	}
+}
+
+func TestSamples(t *testing.T) {
+	frame := mccdaq.Frame{
+		Timebase: mccdaq.Timebase{Period: 500 * time.Microsecond},
+		Channels: []int{2, 5},
+		Volts:    [][]float64{{1, 2}, {3, 4}},
+	}
+	volts, rate, err := Samples(frame, 5)
+	if err != nil {
+		t.Fatalf("Samples: %v", err)
+	}
+	if rate != 2000 || volts[0] != 3 {
+		t.Errorf("Expected 2000 Hz from channel 5, got %v Hz and %v", rate, volts)
+	}
+	if _, _, err := Samples(frame, 0); !errors.Is(err, mccdaq.ErrInvalidChannel) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrInvalidChannel, err)
+	}
+	frame.Timebase.Period = 0
+	if _, _, err := Samples(frame, 5); !errors.Is(err, mccdaq.ErrInvalidFrequency) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrInvalidFrequency, err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	x := make([]float64, 100)
+	if _, err := NewSpectrum(x, 0, Hann); !errors.Is(err, mccdaq.ErrInvalidFrequency) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrInvalidFrequency, err)
+	}
+	if _, err := NewSpectrum(x[:1], 1000, Hann); err == nil {
+		t.Error("Expected error for a single sample")
+	}
+	if _, err := Welch(x, 1000, 200, 0.5, Hann); err == nil {
+		t.Error("Expected error for a segment longer than the samples")
+	}
+	if _, err := Welch(x, 1000, 50, 1, Hann); err == nil {
+		t.Error("Expected error for a full overlap")
+	}
+	if _, err := Analyze(x, 1000, Hann, 0); err == nil {
+		t.Error("Expected error for no harmonics")
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package spectral
+
+import "math"
+
+// Window is a window function applied to a block of samples before
+// transforming it, to reduce the leakage of each frequency into its
+// neighbors.
+type Window int
+
+// Available windows.
+const (
+	// Rectangular applies no window, which gives the finest resolution but
+	// the worst leakage.
+	Rectangular Window = iota
+	// Hann is a good general purpose window.
+	Hann
+	// BlackmanHarris is the four-term Blackman-Harris window, whose
+	// sidelobes are 92 dB down, for measuring small signals near large ones.
+	BlackmanHarris
+	// FlatTop has a main lobe flat to within 0.01 dB, for measuring the
+	// amplitude of tones accurately wherever they fall between bins.
+	FlatTop
+)
+
+var windows = map[Window]string{
+	Rectangular:    "rectangular",
+	Hann:           "hann",
+	BlackmanHarris: "blackman-harris",
+	FlatTop:        "flat-top",
+}
+
+// String implements the Stringer interface for Window.
+func (w Window) String() string {
+	return windows[w]
+}
+
+// cosineTerms returns the coefficients of the window as a sum of cosines.
+func (w Window) cosineTerms() []float64 {
+	switch w {
+	case Hann:
+		return []float64{0.5, 0.5}
+	case BlackmanHarris:
+		return []float64{0.35875, 0.48829, 0.14128, 0.01168}
+	case FlatTop:
+		return []float64{0.21557895, 0.41663158, 0.277263158, 0.083578947, 0.006947368}
+	default:
+		return []float64{1}
+	}
+}
+
+// halfWidth returns the half width in bins of the window's main lobe, over
+// which the power of a tone is summed.
+func (w Window) halfWidth() int {
+	switch w {
+	case Hann:
+		return 2
+	case BlackmanHarris:
+		return 4
+	case FlatTop:
+		return 5
+	default:
+		return 1
+	}
+}
+
+// Coefficients returns the window of length n. The windows are periodic, as
+// suits spectral analysis, rather than symmetric.
+func (w Window) Coefficients(n int) []float64 {
+	terms := w.cosineTerms()
+	c := make([]float64, n)
+	for i := range c {
+		sign := 1.0
+		for k, a := range terms {
+			c[i] += sign * a * math.Cos(2*math.Pi*float64(k*i)/float64(n))
+			sign = -sign
+		}
+	}
+	return c
+}
+
+// ENBW returns the equivalent noise bandwidth of the window of length n in
+// bins, which is how much wider than a bin the window makes the noise
+// bandwidth of each bin.
+func (w Window) ENBW(n int) float64 {
+	c := w.Coefficients(n)
+	var sum, sumSq float64
+	for _, v := range c {
+		sum += v
+		sumSq += v * v
+	}
+	return float64(n) * sumSq / (sum * sum)
+}
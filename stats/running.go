@@ -0,0 +1,125 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package stats
+
+import "math"
+
+// Running accumulates the statistics of a sequence of samples using Welford's
+// algorithm, which avoids the loss of precision of summing squares when the
+// samples have a large mean compared with their spread. The zero value is
+// ready to use.
+type Running struct {
+	n    int
+	mean float64
+	m2   float64
+	min  float64
+	max  float64
+}
+
+// Add adds a sample. NaN samples, such as samples missing from aligned data,
+// are ignored.
+func (r *Running) Add(x float64) {
+	if math.IsNaN(x) {
+		return
+	}
+	r.n++
+	if r.n == 1 {
+		r.mean, r.m2, r.min, r.max = x, 0, x, x
+		return
+	}
+	delta := x - r.mean
+	r.mean += delta / float64(r.n)
+	r.m2 += delta * (x - r.mean)
+	if x < r.min {
+		r.min = x
+	}
+	if x > r.max {
+		r.max = x
+	}
+}
+
+// Merge adds the samples accumulated by other, using the parallel form of
+// Welford's algorithm by Chan et al.
+func (r *Running) Merge(other Running) {
+	if other.n == 0 {
+		return
+	}
+	if r.n == 0 {
+		*r = other
+		return
+	}
+	n := r.n + other.n
+	delta := other.mean - r.mean
+	r.mean += delta * float64(other.n) / float64(n)
+	r.m2 += other.m2 + delta*delta*float64(r.n)*float64(other.n)/float64(n)
+	r.n = n
+	if other.min < r.min {
+		r.min = other.min
+	}
+	if other.max > r.max {
+		r.max = other.max
+	}
+}
+
+// Reset forgets every sample.
+func (r *Running) Reset() {
+	*r = Running{}
+}
+
+// Count returns the number of samples.
+func (r Running) Count() int {
+	return r.n
+}
+
+// Mean returns the mean of the samples, or NaN if there are none.
+func (r Running) Mean() float64 {
+	if r.n == 0 {
+		return math.NaN()
+	}
+	return r.mean
+}
+
+// Variance returns the population variance of the samples, or NaN if there
+// are none.
+func (r Running) Variance() float64 {
+	if r.n == 0 {
+		return math.NaN()
+	}
+	return r.m2 / float64(r.n)
+}
+
+// StdDev returns the population standard deviation of the samples, or NaN if
+// there are none.
+func (r Running) StdDev() float64 {
+	return math.Sqrt(r.Variance())
+}
+
+// RMS returns the root mean square of the samples, or NaN if there are none.
+func (r Running) RMS() float64 {
+	return math.Sqrt(r.Mean()*r.Mean() + r.Variance())
+}
+
+// Min returns the smallest sample, or NaN if there are none.
+func (r Running) Min() float64 {
+	if r.n == 0 {
+		return math.NaN()
+	}
+	return r.min
+}
+
+// Max returns the largest sample, or NaN if there are none.
+func (r Running) Max() float64 {
+	if r.n == 0 {
+		return math.NaN()
+	}
+	return r.max
+}
+
+// PeakToPeak returns the difference between the largest and smallest
+// samples, or NaN if there are none.
+func (r Running) PeakToPeak() float64 {
+	return r.Max() - r.Min()
+}
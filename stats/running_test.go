@@ -0,0 +1,87 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRunning(t *testing.T) {
+	var r Running
+	for _, x := range []float64{2, 4, 4, 4, 5, 5, 7, 9, math.NaN()} {
+		r.Add(x)
+	}
+	testCases := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"count", float64(r.Count()), 8},
+		{"mean", r.Mean(), 5},
+		{"variance", r.Variance(), 4},
+		{"standard deviation", r.StdDev(), 2},
+		{"RMS", r.RMS(), math.Sqrt(29)},
+		{"min", r.Min(), 2},
+		{"max", r.Max(), 9},
+		{"peak-to-peak", r.PeakToPeak(), 7},
+	}
+	for _, tc := range testCases {
+		if math.Abs(tc.got-tc.want) > 1e-12 {
+			t.Errorf("Expected %s %v, got %v", tc.name, tc.want, tc.got)
+		}
+	}
+}
+
+func TestRunningEmpty(t *testing.T) {
+	var r Running
+	for name, v := range map[string]float64{
+		"mean": r.Mean(), "standard deviation": r.StdDev(), "RMS": r.RMS(),
+		"min": r.Min(), "max": r.Max(), "peak-to-peak": r.PeakToPeak(),
+	} {
+		if !math.IsNaN(v) {
+			t.Errorf("Expected %s NaN with no samples, got %v", name, v)
+		}
+	}
+}
+
+func TestRunningLargeOffset(t *testing.T) {
+	// Summing squares loses the spread of samples far from zero, but
+	// Welford's algorithm doesn't.
+	var r Running
+	for i := 0; i < 1000; i++ {
+		r.Add(1e9 + float64(i%2))
+	}
+	if got := r.StdDev(); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("Expected standard deviation 0.5, got %v", got)
+	}
+}
+
+func TestRunningMerge(t *testing.T) {
+	var whole, a, b, empty Running
+	for i := 0; i < 100; i++ {
+		x := math.Sin(float64(i)) * float64(i)
+		whole.Add(x)
+		if i < 37 {
+			a.Add(x)
+		} else {
+			b.Add(x)
+		}
+	}
+	a.Merge(b)
+	a.Merge(empty)
+	empty.Merge(a)
+	for _, merged := range []Running{a, empty} {
+		if merged.Count() != whole.Count() || merged.Min() != whole.Min() || merged.Max() != whole.Max() {
+			t.Errorf("Expected merged count, min, and max to match")
+		}
+		if math.Abs(merged.Mean()-whole.Mean()) > 1e-12 ||
+			math.Abs(merged.Variance()-whole.Variance()) > 1e-9 {
+			t.Errorf("Expected mean %v and variance %v, got %v and %v",
+				whole.Mean(), whole.Variance(), merged.Mean(), merged.Variance())
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+// Package stats summarizes the channels of a stream of analog input frames
+// over tumbling or sliding windows of scans, for monitoring that only needs
+// summaries rather than the raw data.
+package stats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// Summary summarizes one channel over a window of scans. Start and End are
+// the pacer times of the window's first and last scans. The standard
+// deviation is the population standard deviation.
+type Summary struct {
+	Channel    int
+	Label      string
+	FirstScan  uint64
+	Start      time.Time
+	End        time.Time
+	Count      int
+	Min        float64
+	Max        float64
+	Mean       float64
+	RMS        float64
+	StdDev     float64
+	PeakToPeak float64
+}
+
+// newSummary returns the summary of the accumulated samples.
+func newSummary(r Running) Summary {
+	return Summary{
+		Count:      r.Count(),
+		Min:        r.Min(),
+		Max:        r.Max(),
+		Mean:       r.Mean(),
+		RMS:        r.RMS(),
+		StdDev:     r.StdDev(),
+		PeakToPeak: r.PeakToPeak(),
+	}
+}
+
+// Option configures a Windowed.
+type Option func(*Windowed)
+
+// WithLabels labels the summaries of each channel number, such as with the
+// channel descriptions returned by AnalogInput.Descriptions.
+func WithLabels(labels map[int]string) Option {
+	return func(w *Windowed) {
+		w.labels = make(map[int]string)
+		for ch, label := range labels {
+			w.labels[ch] = label
+		}
+	}
+}
+
+// Windowed summarizes each channel of a stream of frames over windows of
+// scans. A new summary of each channel is made every step scans, once a whole
+// window has been seen, so the windows are tumbling if step equals the window
+// and sliding if step is smaller. The window must be a whole number of steps.
+// For example, a scan at 1 kHz summarized with a window and step of 1000
+// scans gives a summary per second.
+//
+// Each window is kept as the statistics of its steps, which are merged to
+// summarize the window, so sliding the window doesn't need the raw data.
+type Windowed struct {
+	window int
+	step   int
+	labels map[int]string
+
+	started  bool
+	next     uint64
+	channels []int
+	// blocks holds the statistics of the latest steps by channel position,
+	// with the current step at head, which has had scans scans so far, and
+	// filled counts the steps completed since the windows were reset.
+	blocks [][]Running
+	head   int
+	scans  int
+	filled int
+}
+
+// New creates a Windowed that summarizes windows of the given number of scans
+// every step scans.
+func New(window, step int, opts ...Option) (*Windowed, error) {
+	if step < 1 || window < step || window%step != 0 {
+		return nil, fmt.Errorf("window of %d scans must be a whole number of steps of %d scans",
+			window, step)
+	}
+	w := Windowed{window: window, step: step}
+	for _, opt := range opts {
+		opt(&w)
+	}
+	return &w, nil
+}
+
+// Process adds the scans of the frame, which must follow on from the previous
+// frame, and returns the summaries completed by the frame, in order of
+// completion and then in the order of the frame's channels. If scans were
+// lost before the frame, or the channels change, any partial windows are
+// discarded so that no window spans the gap.
+func (w *Windowed) Process(frame mccdaq.Frame) []Summary {
+	if !w.started || frame.Index != w.next || frame.LostScans > 0 ||
+		!frame.SameChannels(w.channels) {
+		w.reset(frame.Channels)
+	}
+	w.next = frame.Index + uint64(frame.NumScans())
+	var summaries []Summary
+	for scan := 0; scan < frame.NumScans(); scan++ {
+		for i := range frame.Channels {
+			w.blocks[i][w.head].Add(frame.Volts[i][scan])
+		}
+		w.scans++
+		if w.scans < w.step {
+			continue
+		}
+		w.filled++
+		if w.filled >= len(w.blocks[0]) {
+			last := frame.Index + uint64(scan)
+			summaries = append(summaries, w.summarize(frame, last)...)
+		}
+		w.head = (w.head + 1) % len(w.blocks[0])
+		for i := range frame.Channels {
+			w.blocks[i][w.head].Reset()
+		}
+		w.scans = 0
+	}
+	return summaries
+}
+
+// Watch processes each frame received from frames, such as a Stream's Frames,
+// and sends the summaries on the returned channel, which is closed once frames
+// is closed or the context is done.
+func (w *Windowed) Watch(ctx context.Context, frames <-chan mccdaq.Frame) <-chan Summary {
+	summaries := make(chan Summary)
+	var watcher mccdaq.Watcher
+	watcher.Watch(ctx, frames, func(frame mccdaq.Frame) error {
+		for _, s := range w.Process(frame) {
+			select {
+			case summaries <- s:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	}, func() { close(summaries) })
+	return summaries
+}
+
+// reset discards the windows for a stream of frames with the given channels.
+func (w *Windowed) reset(channels []int) {
+	w.started = true
+	w.channels = append(w.channels[:0], channels...)
+	w.blocks = make([][]Running, len(channels))
+	for i := range w.blocks {
+		w.blocks[i] = make([]Running, w.window/w.step)
+	}
+	w.head, w.scans, w.filled = 0, 0, 0
+}
+
+// summarize returns the summary of each channel over the window ending at the
+// given scan.
+func (w *Windowed) summarize(frame mccdaq.Frame, last uint64) []Summary {
+	first := last + 1 - uint64(w.window)
+	summaries := make([]Summary, len(frame.Channels))
+	for i, ch := range frame.Channels {
+		var r Running
+		for _, block := range w.blocks[i] {
+			r.Merge(block)
+		}
+		s := newSummary(r)
+		s.Channel = ch
+		s.Label = w.labels[ch]
+		s.FirstScan = first
+		s.Start = frame.Timebase.Time(first)
+		s.End = frame.Timebase.Time(last)
+		summaries[i] = s
+	}
+	return summaries
+}
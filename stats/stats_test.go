@@ -0,0 +1,136 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package stats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// newFrames splits scans of channels 1 and 4 into frames of the given size.
+// Channel 1 reads the scan number, and channel 4 reads minus the scan number.
+func newFrames(first, scans, size int) []mccdaq.Frame {
+	tb := mccdaq.Timebase{Anchor: time.Unix(0, 0), Period: time.Millisecond}
+	var frames []mccdaq.Frame
+	for start := first; start < first+scans; start += size {
+		frame := mccdaq.Frame{
+			Index:    uint64(start),
+			Timebase: tb,
+			Channels: []int{1, 4},
+			Raw:      make([][]uint16, 2),
+			Volts:    make([][]float64, 2),
+		}
+		for scan := start; scan < start+size && scan < first+scans; scan++ {
+			frame.Raw[0] = append(frame.Raw[0], 0)
+			frame.Raw[1] = append(frame.Raw[1], 0)
+			frame.Volts[0] = append(frame.Volts[0], float64(scan))
+			frame.Volts[1] = append(frame.Volts[1], -float64(scan))
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+func processAll(w *Windowed, frames []mccdaq.Frame) []Summary {
+	var summaries []Summary
+	for _, frame := range frames {
+		summaries = append(summaries, w.Process(frame)...)
+	}
+	return summaries
+}
+
+func TestTumbling(t *testing.T) {
+	w, err := New(4, 4, WithLabels(map[int]string{1: "Pressure"}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	summaries := processAll(w, newFrames(0, 10, 3))
+	if len(summaries) != 4 {
+		t.Fatalf("Expected 4 summaries, got %d", len(summaries))
+	}
+	first, second := summaries[0], summaries[2]
+	if first.Channel != 1 || first.Label != "Pressure" || summaries[1].Label != "" {
+		t.Errorf("Expected channel 1 labeled Pressure, got %d labeled %q", first.Channel, first.Label)
+	}
+	if first.Count != 4 || first.Min != 0 || first.Max != 3 || first.Mean != 1.5 ||
+		first.PeakToPeak != 3 {
+		t.Errorf("Expected scans 0 to 3 summarized, got %+v", first)
+	}
+	if second.FirstScan != 4 || second.Mean != 5.5 || summaries[3].Mean != -5.5 {
+		t.Errorf("Expected scans 4 to 7 summarized, got %+v", second)
+	}
+	if got := second.Start.Sub(time.Unix(0, 0)); got != 4*time.Millisecond {
+		t.Errorf("Expected window start at 4 ms, got %v", got)
+	}
+	if got := second.End.Sub(time.Unix(0, 0)); got != 7*time.Millisecond {
+		t.Errorf("Expected window end at 7 ms, got %v", got)
+	}
+}
+
+func TestSliding(t *testing.T) {
+	w, err := New(6, 2)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	summaries := processAll(w, newFrames(0, 12, 5))
+	// Windows end at scans 5, 7, 9, and 11.
+	if len(summaries) != 8 {
+		t.Fatalf("Expected 8 summaries, got %d", len(summaries))
+	}
+	for i := 0; i < 4; i++ {
+		s := summaries[2*i]
+		first := float64(2 * i)
+		if s.FirstScan != uint64(2*i) || s.Min != first || s.Max != first+5 ||
+			s.Mean != first+2.5 || s.Count != 6 {
+			t.Errorf("Expected window from scan %d, got %+v", 2*i, s)
+		}
+	}
+}
+
+func TestWindowGap(t *testing.T) {
+	w, err := New(4, 2)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	frames := append(newFrames(0, 3, 3), newFrames(10, 6, 3)...)
+	summaries := processAll(w, frames)
+	// No window spans the gap, so the windows start at scans 10 and 12.
+	if len(summaries) != 4 || summaries[0].FirstScan != 10 || summaries[2].FirstScan != 12 {
+		t.Errorf("Expected windows from scans 10 and 12, got %+v", summaries)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	w, err := New(5, 5)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	frames := make(chan mccdaq.Frame)
+	go func() {
+		defer close(frames)
+		for _, frame := range newFrames(0, 20, 7) {
+			frames <- frame
+		}
+	}()
+	count := 0
+	for range w.Watch(context.Background(), frames) {
+		count++
+	}
+	if count != 8 {
+		t.Errorf("Expected 8 summaries, got %d", count)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	for _, tc := range [][2]int{{4, 0}, {3, 4}, {5, 2}} {
+		if _, err := New(tc[0], tc[1]); err == nil {
+			t.Errorf("Expected error for window %d and step %d", tc[0], tc[1])
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package thermocouple
+
+import "math"
+
+// segment is one range of a NIST ITS-90 reference function, giving the EMF in
+// mV as the sum of c[i]*t^i for temperatures t in °C up to max.
+type segment struct {
+	max float64
+	c   []float64
+}
+
+// reference is the NIST ITS-90 reference function of a thermocouple type,
+// with its segments in order of temperature from min.
+type reference struct {
+	min      float64
+	segments []segment
+	// solveMin, if nonzero, is the lowest temperature that Celsius solves
+	// for, instead of min.
+	solveMin float64
+}
+
+// references holds the NIST ITS-90 reference functions from NIST Monograph
+// 175.
+var references = map[Type]reference{
+	J: {min: -210, segments: []segment{
+		{760, []float64{
+			0, 5.0381187815e-02, 3.0475836930e-05, -8.5681065720e-08,
+			1.3228195295e-10, -1.7052958337e-13, 2.0948090697e-16,
+			-1.2538395336e-19, 1.5631725697e-23,
+		}},
+		{1200, []float64{
+			2.9645625681e+02, -1.4976127786e+00, 3.1787103924e-03,
+			-3.1847686701e-06, 1.5720819004e-09, -3.0691369056e-13,
+		}},
+	}},
+	K: {min: -270, segments: []segment{
+		{0, []float64{
+			0, 3.9450128025e-02, 2.3622373598e-05, -3.2858906784e-07,
+			-4.9904828777e-09, -6.7509059173e-11, -5.7410327428e-13,
+			-3.1088872894e-15, -1.0451609365e-17, -1.9889266878e-20,
+			-1.6322697486e-23,
+		}},
+		{1372, []float64{
+			-1.7600413686e-02, 3.8921204975e-02, 1.8558770032e-05,
+			-9.9457592874e-08, 3.1840945719e-10, -5.6072844889e-13,
+			5.6075059059e-16, -3.2020720003e-19, 9.7151147152e-23,
+			-1.2104721275e-26,
+		}},
+	}},
+	T: {min: -270, segments: []segment{
+		{0, []float64{
+			0, 3.8748106364e-02, 4.4194434347e-05, 1.1844323105e-07,
+			2.0032973554e-08, 9.0138019559e-10, 2.2651156593e-11,
+			3.6071154205e-13, 3.8493939883e-15, 2.8213521925e-17,
+			1.4251594779e-19, 4.8768662286e-22, 1.0795539270e-24,
+			1.3945027062e-27, 7.9795153927e-31,
+		}},
+		{400, []float64{
+			0, 3.8748106364e-02, 3.3292227880e-05, 2.0618243404e-07,
+			-2.1882256846e-09, 1.0996880928e-11, -3.0815758772e-14,
+			4.5479135290e-17, -2.7512901673e-20,
+		}},
+	}},
+	E: {min: -270, segments: []segment{
+		{0, []float64{
+			0, 5.8665508708e-02, 4.5410977124e-05, -7.7998048686e-07,
+			-2.5800160843e-08, -5.9452583057e-10, -9.3214058667e-12,
+			-1.0287605534e-13, -8.0370123621e-16, -4.3979497391e-18,
+			-1.6414776355e-20, -3.9673619516e-23, -5.5827328721e-26,
+			-3.4657842013e-29,
+		}},
+		{1000, []float64{
+			0, 5.8665508710e-02, 4.5032275582e-05, 2.8908407212e-08,
+			-3.3056896652e-10, 6.5024403270e-13, -1.9197495504e-16,
+			-1.2536600497e-18, 2.1489217569e-21, -1.4388041782e-24,
+			3.5960899481e-28,
+		}},
+	}},
+	N: {min: -270, segments: []segment{
+		{0, []float64{
+			0, 2.6159105962e-02, 1.0957484228e-05, -9.3841111554e-08,
+			-4.6412039759e-11, -2.6303357716e-12, -2.2653438003e-14,
+			-7.6089300791e-17, -9.3419667835e-20,
+		}},
+		{1300, []float64{
+			0, 2.5929394601e-02, 1.5710141880e-05, 4.3825627237e-08,
+			-2.5261169794e-10, 6.4311819339e-13, -1.0063471519e-15,
+			9.9745338992e-19, -6.0863245607e-22, 2.0849229339e-25,
+			-3.0682196151e-29,
+		}},
+	}},
+	R: {min: -50, segments: []segment{
+		{1064.18, []float64{
+			0, 5.28961729765e-03, 1.39166589782e-05, -2.38855693017e-08,
+			3.56916001063e-11, -4.62347666298e-14, 5.00777441034e-17,
+			-3.73105886191e-20, 1.57716482367e-23, -2.81038625251e-27,
+		}},
+		{1664.5, []float64{
+			2.95157925316e+00, -2.52061251332e-03, 1.59564501865e-05,
+			-7.64085947576e-09, 2.05305291024e-12, -2.93359668173e-16,
+		}},
+		{1768.1, []float64{
+			1.52232118209e+02, -2.68819888545e-01, 1.71280280471e-04,
+			-3.45895706453e-08, -9.34633971046e-15,
+		}},
+	}},
+	S: {min: -50, segments: []segment{
+		{1064.18, []float64{
+			0, 5.40313308631e-03, 1.25934289740e-05, -2.32477968689e-08,
+			3.22028823036e-11, -3.31465196389e-14, 2.55744251786e-17,
+			-1.25068871393e-20, 2.71443176145e-24,
+		}},
+		{1664.5, []float64{
+			1.32900444085e+00, 3.34509311344e-03, 6.54805192818e-06,
+			-1.64856259209e-09, 1.29989605174e-14,
+		}},
+		{1768.1, []float64{
+			1.46628232636e+02, -2.58430516752e-01, 1.63693574641e-04,
+			-3.30439046987e-08, -9.43223690612e-15,
+		}},
+	}},
+	// Type B's EMF has a minimum near 21 °C and is only a few µV up to
+	// 50 °C, so its temperature is only solved for from 50 °C.
+	B: {min: 0, solveMin: 50, segments: []segment{
+		{630.615, []float64{
+			0, -2.4650818346e-04, 5.9040421171e-06, -1.3257931636e-09,
+			1.5668291901e-12, -1.6944529240e-15, 6.2990347094e-19,
+		}},
+		{1820, []float64{
+			-3.8938168621e+00, 2.8571747470e-02, -8.4885104785e-05,
+			1.5785280164e-07, -1.6835344864e-10, 1.1109794013e-13,
+			-4.4515431033e-17, 9.8975640821e-21, -9.3791330289e-25,
+		}},
+	}},
+}
+
+// Type K's reference function above 0 °C adds a Gaussian term to the
+// polynomial.
+const (
+	kA0 = 1.185976e-01
+	kA1 = -1.183432e-04
+	kA2 = 1.269686e+02
+)
+
+// emf returns the EMF in mV at the given temperature in °C, which must be in
+// range.
+func (r reference) emf(typ Type, celsius float64) float64 {
+	seg := r.segments[len(r.segments)-1]
+	for _, s := range r.segments {
+		if celsius <= s.max {
+			seg = s
+			break
+		}
+	}
+	sum := 0.0
+	for i := len(seg.c) - 1; i >= 0; i-- {
+		sum = sum*celsius + seg.c[i]
+	}
+	if typ == K && celsius > 0 {
+		d := celsius - kA2
+		sum += kA0 * math.Exp(kA1*d*d)
+	}
+	return sum
+}
+
+// max returns the highest temperature of the reference function.
+func (r reference) max() float64 {
+	return r.segments[len(r.segments)-1].max
+}
+
+// lowest returns the lowest temperature that Celsius solves for.
+func (r reference) lowest() float64 {
+	if r.solveMin != 0 {
+		return r.solveMin
+	}
+	return r.min
+}
+
+// solve returns the temperature in °C whose EMF is the given mV, which must
+// lie between the EMFs at the ends of the range Celsius solves over, by the
+// secant method safeguarded by bisection, since the reference functions are
+// monotonic over that range.
+func (r reference) solve(typ Type, mV float64) float64 {
+	lo, hi := r.lowest(), r.max()
+	flo, fhi := r.emf(typ, lo)-mV, r.emf(typ, hi)-mV
+	switch {
+	case flo == 0:
+		return lo
+	case fhi == 0:
+		return hi
+	}
+	for i := 0; i < 100; i++ {
+		x := lo - flo*(hi-lo)/(fhi-flo)
+		if !(x > lo && x < hi) {
+			x = (lo + hi) / 2
+		}
+		f := r.emf(typ, x) - mV
+		if math.Abs(f) < 1e-9 || hi-lo < 1e-9 {
+			return x
+		}
+		if (f < 0) == (flo < 0) {
+			// Halve the stale end's value so that the secant doesn't stall
+			// on one side of the root (the Illinois method).
+			lo, flo = x, f
+			fhi /= 2
+		} else {
+			hi, fhi = x, f
+			flo /= 2
+		}
+	}
+	return (lo + hi) / 2
+}
@@ -0,0 +1,271 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+// Package thermocouple converts the voltages of thermocouple channels into
+// temperatures using the NIST ITS-90 reference functions for types J, K, T,
+// E, N, R, S, and B, with cold-junction compensation from a fixed temperature
+// or from a channel measuring the temperature of the terminals.
+//
+// A thermocouple gives tens of µV per °C, so measure it on the most sensitive
+// range, and consider oversampling it with the decimate package to resolve
+// fractions of a degree.
+package thermocouple
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// Type is a thermocouple type.
+type Type int
+
+// Available thermocouple types.
+const (
+	J Type = iota
+	K
+	T
+	E
+	N
+	R
+	S
+	B
+)
+
+var types = map[Type]string{
+	J: "J",
+	K: "K",
+	T: "T",
+	E: "E",
+	N: "N",
+	R: "R",
+	S: "S",
+	B: "B",
+}
+
+// String implements the Stringer interface for Type.
+func (t Type) String() string {
+	return types[t]
+}
+
+// UnmarshalJSON implements the Unmarshaler interface for Type by taking the
+// letter of the type, in either case.
+func (t *Type) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("thermocouple type should be a string, got %s", data)
+	}
+	for typ, name := range types {
+		if name == strings.ToUpper(s) {
+			*t = typ
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid thermocouple type %q", s)
+}
+
+// MarshalJSON implements the Marshaler interface for Type.
+func (t Type) MarshalJSON() ([]byte, error) {
+	return json.Marshal(types[t])
+}
+
+// Range returns the range of temperatures in °C covered by the type's
+// reference function.
+func (t Type) Range() (min, max float64) {
+	r := references[t]
+	return r.min, r.max()
+}
+
+// Volts returns the thermoelectric voltage of the type with its reference
+// junction at 0 °C and its measuring junction at the given temperature in
+// °C. Volts fails with mccdaq.ErrInvalidRange if the temperature is outside
+// the type's Range.
+func (t Type) Volts(celsius float64) (float64, error) {
+	r, ok := references[t]
+	if !ok {
+		return 0, fmt.Errorf("bad thermocouple type %d: %w", t, mccdaq.ErrNotSupported)
+	}
+	if !(celsius >= r.min && celsius <= r.max()) {
+		return 0, fmt.Errorf("%g °C outside type %s range %g °C to %g °C: %w",
+			celsius, t, r.min, r.max(), mccdaq.ErrInvalidRange)
+	}
+	return r.emf(t, celsius) / 1000, nil
+}
+
+// Celsius returns the temperature in °C of the measuring junction of the type
+// whose thermoelectric voltage, with its reference junction at 0 °C, is the
+// given volts. The temperature is found by solving the reference function
+// rather than by the approximate inverse polynomials, so Celsius and Volts
+// agree to within a microdegree. Celsius fails with mccdaq.ErrInvalidRange
+// if the voltage is outside the type's range, which for type B starts at
+// 50 °C.
+func (t Type) Celsius(volts float64) (float64, error) {
+	r, ok := references[t]
+	if !ok {
+		return 0, fmt.Errorf("bad thermocouple type %d: %w", t, mccdaq.ErrNotSupported)
+	}
+	mV := volts * 1000
+	lo, hi := r.emf(t, r.lowest()), r.emf(t, r.max())
+	if !(mV >= lo && mV <= hi) {
+		return 0, fmt.Errorf("%g V outside type %s range %g V to %g V: %w",
+			volts, t, lo/1000, hi/1000, mccdaq.ErrInvalidRange)
+	}
+	return r.solve(t, mV), nil
+}
+
+// ColdJunction gives the temperature of the cold junction, where the
+// thermocouple wires meet the DAQ's terminals.
+type ColdJunction struct {
+	fixed   float64
+	channel int
+	celsius func(volts float64) float64
+}
+
+// Fixed returns a cold junction held at the given temperature in °C, such as
+// an ice bath or an isothermal block in a temperature-controlled enclosure.
+func Fixed(celsius float64) ColdJunction {
+	return ColdJunction{fixed: celsius, channel: -1}
+}
+
+// Channel returns a cold junction whose temperature is measured by a sensor
+// on the given channel number, whose volts the function converts into °C.
+// For example, an LM35 gives 10 mV per °C, so
+//
+//	thermocouple.Channel(7, func(v float64) float64 { return 100 * v })
+//
+// and the Value method of a scale.Scale also suits. Since the cold junction
+// changes slowly, its temperature is averaged over each frame to reduce
+// noise.
+func Channel(channel int, celsius func(volts float64) float64) ColdJunction {
+	return ColdJunction{channel: channel, celsius: celsius}
+}
+
+// temperature returns the temperature of the cold junction during the frame.
+func (cj ColdJunction) temperature(frame mccdaq.Frame) (float64, error) {
+	if cj.channel < 0 {
+		return cj.fixed, nil
+	}
+	for i, ch := range frame.Channels {
+		if ch != cj.channel {
+			continue
+		}
+		sum, count := 0.0, 0
+		for _, v := range frame.Volts[i] {
+			if !math.IsNaN(v) {
+				sum += cj.celsius(v)
+				count++
+			}
+		}
+		if count == 0 {
+			return 0, fmt.Errorf("no cold junction readings in frame at scan %d", frame.Index)
+		}
+		return sum / float64(count), nil
+	}
+	return 0, fmt.Errorf("cold junction channel %d not in frame: %w", cj.channel, mccdaq.ErrInvalidChannel)
+}
+
+// Converter converts the thermocouple channels of frames into temperatures.
+type Converter struct {
+	thermocouples map[int]Type
+	coldJunction  ColdJunction
+	watcher       mccdaq.Watcher
+}
+
+// New creates a Converter for the given thermocouple types, keyed by channel
+// number, such as those returned by an analog input's Thermocouples method,
+// all of which share the cold junction.
+func New(thermocouples map[int]Type, cj ColdJunction) (*Converter, error) {
+	if len(thermocouples) == 0 {
+		return nil, fmt.Errorf("converter needs at least one thermocouple")
+	}
+	for ch, t := range thermocouples {
+		if _, ok := references[t]; !ok {
+			return nil, fmt.Errorf("bad thermocouple type %d for channel %d: %w", t, ch, mccdaq.ErrNotSupported)
+		}
+		if ch == cj.channel {
+			return nil, fmt.Errorf("channel %d can't be both a thermocouple and the cold junction", ch)
+		}
+	}
+	if cj.channel >= 0 && cj.celsius == nil {
+		return nil, fmt.Errorf("cold junction channel %d needs a conversion into °C", cj.channel)
+	}
+	c := Converter{
+		thermocouples: make(map[int]Type),
+		coldJunction:  cj,
+	}
+	for ch, t := range thermocouples {
+		c.thermocouples[ch] = t
+	}
+	return &c, nil
+}
+
+// Process returns a copy of the frame whose Volts hold the temperatures in °C
+// of the thermocouple channels, and volts for the other channels. Each
+// thermocouple's voltage is compensated by adding the voltage it would give
+// at the cold junction's temperature before it's converted. A voltage beyond
+// the type's range, as from an open or shorted thermocouple, converts into
+// +Inf or -Inf, so that alarm limits trip on it, and a NaN voltage stays
+// NaN. Process fails with mccdaq.ErrInvalidChannel if a thermocouple or the
+// cold junction channel isn't in the frame, or with mccdaq.ErrInvalidRange if
+// the cold junction is outside a thermocouple type's range.
+func (c *Converter) Process(frame mccdaq.Frame) (mccdaq.Frame, error) {
+	converted := frame
+	cj, err := c.coldJunction.temperature(frame)
+	if err != nil {
+		return converted, err
+	}
+	positions := make(map[int]int)
+	for i, ch := range frame.Channels {
+		positions[ch] = i
+	}
+	converted.Volts = make([][]float64, len(frame.Volts))
+	for i := range frame.Volts {
+		converted.Volts[i] = append([]float64{}, frame.Volts[i]...)
+	}
+	for ch, t := range c.thermocouples {
+		i, ok := positions[ch]
+		if !ok {
+			return frame, fmt.Errorf("thermocouple channel %d not in frame: %w", ch, mccdaq.ErrInvalidChannel)
+		}
+		offset, err := t.Volts(cj)
+		if err != nil {
+			return frame, fmt.Errorf("cold junction of channel %d: %w", ch, err)
+		}
+		_, max := t.Range()
+		top, _ := t.Volts(max)
+		for scan, v := range converted.Volts[i] {
+			if math.IsNaN(v) {
+				continue
+			}
+			celsius, err := t.Celsius(v + offset)
+			switch {
+			case err == nil:
+				converted.Volts[i][scan] = celsius
+			case v+offset > top:
+				converted.Volts[i][scan] = math.Inf(1)
+			default:
+				converted.Volts[i][scan] = math.Inf(-1)
+			}
+		}
+	}
+	return converted, nil
+}
+
+// Watch converts each frame received from frames, such as a Stream's Frames,
+// and sends it on the returned channel, which is closed once frames is
+// closed, conversion fails, or the context is done, after which Err reports
+// why.
+func (c *Converter) Watch(ctx context.Context, frames <-chan mccdaq.Frame) <-chan mccdaq.Frame {
+	return c.watcher.WatchFrames(ctx, frames, c.Process)
+}
+
+// Err returns the error that stopped Watch, if any.
+func (c *Converter) Err() error {
+	return c.watcher.Err()
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package thermocouple
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/gotmc/mccdaq"
+)
+
+func TestVolts(t *testing.T) {
+	// Values from the NIST ITS-90 thermocouple tables in mV.
+	testCases := []struct {
+		typ     Type
+		celsius float64
+		mV      float64
+	}{
+		{J, -200, -7.890},
+		{J, 100, 5.269},
+		{J, 500, 27.393},
+		{J, 1000, 57.953},
+		{K, -200, -5.891},
+		{K, 0, 0},
+		{K, 100, 4.096},
+		{K, 500, 20.644},
+		{K, 1000, 41.276},
+		{K, 1372, 54.886},
+		{T, -200, -5.603},
+		{T, 100, 4.279},
+		{T, 400, 20.872},
+		{E, -200, -8.825},
+		{E, 100, 6.319},
+		{E, 1000, 76.373},
+		{N, -200, -3.990},
+		{N, 100, 2.774},
+		{N, 1300, 47.513},
+		{R, 1000, 10.506},
+		{R, 1768.1, 21.103},
+		{S, 1000, 9.587},
+		{S, 1768.1, 18.694},
+		{B, 1000, 4.834},
+		{B, 1820, 13.820},
+	}
+	for _, tc := range testCases {
+		got, err := tc.typ.Volts(tc.celsius)
+		if err != nil {
+			t.Fatalf("Volts: %v", err)
+		}
+		if math.Abs(got*1000-tc.mV) > 0.0005 {
+			t.Errorf("Expected type %s at %v °C to give %v mV, got %v", tc.typ, tc.celsius, tc.mV, got*1000)
+		}
+	}
+}
+
+func TestCelsius(t *testing.T) {
+	for typ := range types {
+		lo, hi := typ.Range()
+		if typ == B {
+			lo = 50
+		}
+		for i := 0; i <= 20; i++ {
+			want := lo + (hi-lo)*float64(i)/20
+			v, err := typ.Volts(want)
+			if err != nil {
+				t.Fatalf("Volts: %v", err)
+			}
+			got, err := typ.Celsius(v)
+			if err != nil {
+				t.Fatalf("Celsius: %v", err)
+			}
+			if math.Abs(got-want) > 1e-6 {
+				t.Errorf("Expected type %s to read %v °C, got %v", typ, want, got)
+			}
+		}
+	}
+}
+
+func TestRangeErrors(t *testing.T) {
+	if _, err := K.Volts(1400); !errors.Is(err, mccdaq.ErrInvalidRange) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrInvalidRange, err)
+	}
+	if _, err := K.Celsius(0.06); !errors.Is(err, mccdaq.ErrInvalidRange) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrInvalidRange, err)
+	}
+	if _, err := B.Celsius(0); !errors.Is(err, mccdaq.ErrInvalidRange) {
+		t.Errorf("Expected error %v for type B near 0 °C, got %v", mccdaq.ErrInvalidRange, err)
+	}
+	if _, err := Type(12).Volts(0); !errors.Is(err, mccdaq.ErrNotSupported) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrNotSupported, err)
+	}
+}
+
+func TestTypeJSON(t *testing.T) {
+	var typ Type
+	if err := json.Unmarshal([]byte(`"k"`), &typ); err != nil || typ != K {
+		t.Errorf("Expected type K, got %s (%v)", typ, err)
+	}
+	b, err := json.Marshal(T)
+	if err != nil || string(b) != `"T"` {
+		t.Errorf("Expected \"T\", got %s (%v)", b, err)
+	}
+	if err := json.Unmarshal([]byte(`"X"`), &typ); err == nil {
+		t.Error("Expected error for type X")
+	}
+}
+
+// newFrame returns a frame of one scan reading the given volts on each of
+// channels 0, 1, and 2.
+func newFrame(volts ...float64) mccdaq.Frame {
+	frame := mccdaq.Frame{Channels: []int{0, 1, 2}}
+	for _, v := range volts {
+		frame.Raw = append(frame.Raw, []uint16{0})
+		frame.Volts = append(frame.Volts, []float64{v})
+	}
+	return frame
+}
+
+func TestConverter(t *testing.T) {
+	// A type K thermocouple at 500 °C with its cold junction at 25 °C reads
+	// EMF(500) - EMF(25).
+	hot, _ := K.Volts(500)
+	cold, _ := K.Volts(25)
+	lm35 := func(v float64) float64 { return 100 * v }
+	testCases := []struct {
+		name  string
+		cj    ColdJunction
+		volts []float64
+		want  []float64
+	}{
+		{"fixed", Fixed(25), []float64{hot - cold, 1, 2}, []float64{500, 1, 2}},
+		{"channel", Channel(2, lm35), []float64{hot - cold, 1, 0.25}, []float64{500, 1, 0.25}},
+		{"open", Fixed(25), []float64{1, 1, 2}, []float64{math.Inf(1), 1, 2}},
+		{"reversed", Fixed(25), []float64{-1, 1, 2}, []float64{math.Inf(-1), 1, 2}},
+	}
+	for _, tc := range testCases {
+		c, err := New(map[int]Type{0: K}, tc.cj)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		frame := newFrame(tc.volts...)
+		converted, err := c.Process(frame)
+		if err != nil {
+			t.Fatalf("%s: Process: %v", tc.name, err)
+		}
+		for i, want := range tc.want {
+			got := converted.Volts[i][0]
+			if !(got == want || math.Abs(got-want) < 1e-6) {
+				t.Errorf("%s: Expected channel %d to read %v, got %v", tc.name, i, want, got)
+			}
+		}
+		if frame.Volts[0][0] != tc.volts[0] {
+			t.Errorf("%s: Expected the original frame to be unchanged", tc.name)
+		}
+	}
+}
+
+func TestConverterErrors(t *testing.T) {
+	if _, err := New(nil, Fixed(25)); err == nil {
+		t.Error("Expected error without thermocouples")
+	}
+	if _, err := New(map[int]Type{0: K}, ColdJunction{}); err == nil {
+		t.Error("Expected error for a thermocouple on the cold junction channel")
+	}
+	if _, err := New(map[int]Type{0: K}, Channel(1, nil)); err == nil {
+		t.Error("Expected error for a cold junction channel without a conversion")
+	}
+	c, err := New(map[int]Type{0: K}, Channel(5, func(v float64) float64 { return v }))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := c.Process(newFrame(0, 0, 0)); !errors.Is(err, mccdaq.ErrInvalidChannel) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrInvalidChannel, err)
+	}
+	c, err = New(map[int]Type{0: B}, Fixed(-10))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := c.Process(newFrame(0, 0, 0)); !errors.Is(err, mccdaq.ErrInvalidRange) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrInvalidRange, err)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package mccdaq
+
+import "time"
+
+// DefaultDriftInterval is how often a DriftEstimator updates its estimate if
+// its Interval isn't set.
+const DefaultDriftInterval = 10 * time.Second
+
+// Timebase relates the index of each scan since an analog input scan started
+// to time. Scans are spaced by Period, derived from the DAQ's pacer clock, and
+// the first scan is anchored to the host clock when the first scan data
+// arrives. Drift is the latest estimate of how the host clock has drifted from
+// the pacer clock since then.
+type Timebase struct {
+	Anchor time.Time     // Host time of the first scan
+	Period time.Duration // Time between scans according to the pacer clock
+	Drift  Drift         // Drift of the host clock from the pacer clock
+}
+
+// Time returns the time of the given scan according to the pacer clock.
+func (tb Timebase) Time(scan uint64) time.Time {
+	return tb.Anchor.Add(time.Duration(scan) * tb.Period)
+}
+
+// HostTime returns the time of the given scan on the host clock, which is the
+// pacer time corrected by the drift estimate.
+func (tb Timebase) HostTime(scan uint64) time.Time {
+	elapsed := time.Duration(scan) * tb.Period
+	return tb.Anchor.Add(elapsed + tb.Drift.OffsetAt(elapsed))
+}
+
+// Drift describes how far the host clock is ahead of a Timebase's pacer time.
+type Drift struct {
+	Elapsed time.Duration // Pacer time since the first scan when last estimated
+	Offset  time.Duration // Host clock ahead of the pacer time at Elapsed
+	PPM     float64       // Rate the host clock gains on the pacer clock
+}
+
+// OffsetAt returns the offset of the host clock extrapolated to the given
+// pacer time since the first scan.
+func (d Drift) OffsetAt(elapsed time.Duration) time.Duration {
+	return d.Offset + time.Duration(float64(elapsed-d.Elapsed)*d.PPM/1e6)
+}
+
+// DriftEstimator estimates the drift between a DAQ's pacer clock and the host
+// clock from how late scan data arrives at the host compared to its pacer
+// time. Transfer latency only ever delays data, so only the earliest arrival
+// in each Interval is used, and the drift rate is fitted by least squares to
+// those arrivals.
+type DriftEstimator struct {
+	// Interval is how often the estimate is updated. If zero,
+	// DefaultDriftInterval is used.
+	Interval time.Duration
+
+	start    time.Duration
+	observed bool
+	min      time.Duration
+	minAt    time.Duration
+	n        float64
+	sumX     float64
+	sumY     float64
+	sumXX    float64
+	sumXY    float64
+	drift    Drift
+}
+
+// Observe records that scan data with the given pacer time since the first
+// scan arrived offset later than its pacer time, and returns the current
+// estimate, which changes once per interval.
+func (e *DriftEstimator) Observe(elapsed, offset time.Duration) Drift {
+	if !e.observed || offset < e.min {
+		e.min, e.minAt = offset, elapsed
+		e.observed = true
+	}
+	interval := e.Interval
+	if interval <= 0 {
+		interval = DefaultDriftInterval
+	}
+	if elapsed-e.start < interval {
+		return e.drift
+	}
+	x, y := e.minAt.Seconds(), e.min.Seconds()
+	e.n++
+	e.sumX += x
+	e.sumY += y
+	e.sumXX += x * x
+	e.sumXY += x * y
+	e.drift = Drift{Elapsed: e.minAt, Offset: e.min}
+	if denom := e.n*e.sumXX - e.sumX*e.sumX; e.n >= 2 && denom != 0 {
+		slope := (e.n*e.sumXY - e.sumX*e.sumY) / denom
+		intercept := (e.sumY - slope*e.sumX) / e.n
+		e.drift.PPM = slope * 1e6
+		e.drift.Offset = time.Duration((intercept + slope*x) * float64(time.Second))
+	}
+	e.start = elapsed
+	e.observed = false
+	return e.drift
+}
+
+// Drift returns the current estimate.
+func (e *DriftEstimator) Drift() Drift {
+	return e.drift
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package mccdaq
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTimebaseTime(t *testing.T) {
+	anchor := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tb := Timebase{
+		Anchor: anchor,
+		Period: time.Millisecond,
+		Drift:  Drift{Elapsed: time.Second, Offset: time.Millisecond, PPM: 100},
+	}
+	testCases := []struct {
+		scan     uint64
+		time     time.Time
+		hostTime time.Time
+	}{
+		{0, anchor, anchor.Add(900 * time.Microsecond)},
+		{1000, anchor.Add(time.Second), anchor.Add(1001 * time.Millisecond)},
+		{11000, anchor.Add(11 * time.Second), anchor.Add(11002 * time.Millisecond)},
+	}
+	for _, tc := range testCases {
+		if computed := tb.Time(tc.scan); !computed.Equal(tc.time) {
+			t.Errorf("Scan %d: expected time %v, got %v", tc.scan, tc.time, computed)
+		}
+		if computed := tb.HostTime(tc.scan); !computed.Equal(tc.hostTime) {
+			t.Errorf("Scan %d: expected host time %v, got %v", tc.scan, tc.hostTime, computed)
+		}
+	}
+}
+
+func TestDriftEstimator(t *testing.T) {
+	// The host clock gains 50 µs a second on the pacer clock, and data arrives
+	// with at least 1 ms of latency, except halfway through each second.
+	e := DriftEstimator{Interval: time.Second}
+	var drift Drift
+	for ms := 0; ms <= 60000; ms += 10 {
+		elapsed := time.Duration(ms) * time.Millisecond
+		latency := time.Millisecond + time.Duration(ms%1000)*3*time.Microsecond
+		if ms%1000 == 500 {
+			latency = 0
+		}
+		drift = e.Observe(elapsed, elapsed/20000+latency)
+	}
+	if math.Abs(drift.PPM-50) > 0.01 {
+		t.Errorf("Expected 50 ppm, got %v", drift.PPM)
+	}
+	if drift.Elapsed != 59500*time.Millisecond {
+		t.Errorf("Expected estimate at 59.5 s, got %v", drift.Elapsed)
+	}
+	if offset := drift.OffsetAt(drift.Elapsed); offset < 2974*time.Microsecond || offset > 2976*time.Microsecond {
+		t.Errorf("Expected 2.975 ms offset, got %v", offset)
+	}
+	if e.Drift() != drift {
+		t.Errorf("Expected Drift to return %v, got %v", drift, e.Drift())
+	}
+}
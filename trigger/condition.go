@@ -0,0 +1,198 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package trigger
+
+import "time"
+
+// Condition decides when a software trigger fires, given the samples of the
+// trigger channel in order. Conditions keep state between samples, such as
+// whether they're armed, so each Trigger needs its own Condition.
+type Condition interface {
+	// Fired reports whether the trigger fires at the sample v, which was
+	// taken dt after the previous sample.
+	Fired(v float64, dt time.Duration) bool
+	// Reset forgets any previous samples, such as after a gap in the data.
+	Reset()
+}
+
+// Direction selects which way a signal must move to fire a trigger.
+type Direction int
+
+// Available directions.
+const (
+	Rising Direction = iota
+	Falling
+	Either
+)
+
+var directions = map[Direction]string{
+	Rising:  "rising",
+	Falling: "falling",
+	Either:  "either",
+}
+
+// String implements the Stringer interface for Direction.
+func (d Direction) String() string {
+	return directions[d]
+}
+
+// Edge fires when the signal crosses Level in the given Direction. Once it
+// fires, it's rearmed only after the signal has moved back past Level by more
+// than Hysteresis, so that noise around Level doesn't fire it repeatedly, and
+// a signal sitting at Level fires only once. Edge doesn't fire on the first
+// sample, since there's no crossing yet.
+type Edge struct {
+	Level      float64
+	Hysteresis float64
+	Direction  Direction
+
+	started      bool
+	armedRising  bool
+	armedFalling bool
+}
+
+// Fired implements the Condition interface for Edge.
+func (e *Edge) Fired(v float64, dt time.Duration) bool {
+	if !e.started {
+		e.started = true
+		e.armedRising = v < e.Level
+		e.armedFalling = v > e.Level
+		return false
+	}
+	if v < e.Level-e.Hysteresis {
+		e.armedRising = true
+	}
+	if v > e.Level+e.Hysteresis {
+		e.armedFalling = true
+	}
+	if e.Direction != Falling && e.armedRising && v >= e.Level {
+		e.armedRising = false
+		return true
+	}
+	if e.Direction != Rising && e.armedFalling && v <= e.Level {
+		e.armedFalling = false
+		return true
+	}
+	return false
+}
+
+// Reset implements the Condition interface for Edge.
+func (e *Edge) Reset() {
+	e.started = false
+}
+
+// Level fires whenever the signal is at or above Level, or at or below Level
+// if Below is true, including on the first sample. Once it fires, it's rearmed
+// only after the signal has been on the other side of Level by at least
+// Hysteresis.
+type Level struct {
+	Level      float64
+	Hysteresis float64
+	Below      bool
+
+	disarmed bool
+}
+
+// Fired implements the Condition interface for Level.
+func (l *Level) Fired(v float64, dt time.Duration) bool {
+	past, clear := v >= l.Level, v < l.Level-l.Hysteresis
+	if l.Below {
+		past, clear = v <= l.Level, v > l.Level+l.Hysteresis
+	}
+	if l.disarmed {
+		l.disarmed = !clear
+		return false
+	}
+	l.disarmed = past
+	return past
+}
+
+// Reset implements the Condition interface for Level.
+func (l *Level) Reset() {
+	l.disarmed = false
+}
+
+// Window fires when the signal enters the window from Low to High inclusive,
+// or leaves it if Outside is true, including on the first sample. Once it
+// fires, it's rearmed only after the signal has been back on the other side
+// of the window's edges by at least Hysteresis.
+type Window struct {
+	Low        float64
+	High       float64
+	Hysteresis float64
+	Outside    bool
+
+	disarmed bool
+}
+
+// Fired implements the Condition interface for Window.
+func (w *Window) Fired(v float64, dt time.Duration) bool {
+	inside := v >= w.Low && v <= w.High
+	farOutside := v < w.Low-w.Hysteresis || v > w.High+w.Hysteresis
+	farInside := v >= w.Low+w.Hysteresis && v <= w.High-w.Hysteresis
+	past, clear := inside, farOutside
+	if w.Outside {
+		past, clear = !inside, farInside
+	}
+	if w.disarmed {
+		w.disarmed = !clear
+		return false
+	}
+	w.disarmed = past
+	return past
+}
+
+// Reset implements the Condition interface for Window.
+func (w *Window) Reset() {
+	w.disarmed = false
+}
+
+// Slope fires when the signal changes at least as fast as Rate, in volts (or
+// the signal's units) per second, in the given Direction. Once it fires, it's
+// rearmed only after the rate of change has dropped below Rate. Slope needs the
+// time between samples, so a Trigger using it fails on frames with no scan
+// period, such as from a scan paced externally with no Frequency set.
+type Slope struct {
+	Rate      float64
+	Direction Direction
+
+	started  bool
+	prev     float64
+	disarmed bool
+}
+
+// Fired implements the Condition interface for Slope. The first sample, and
+// any sample with no time since the previous sample, never fire.
+func (s *Slope) Fired(v float64, dt time.Duration) bool {
+	prev := s.prev
+	s.prev = v
+	if !s.started || dt <= 0 {
+		s.started = true
+		return false
+	}
+	rate := (v - prev) / dt.Seconds()
+	past := false
+	switch s.Direction {
+	case Rising:
+		past = rate >= s.Rate
+	case Falling:
+		past = rate <= -s.Rate
+	default:
+		past = rate >= s.Rate || rate <= -s.Rate
+	}
+	if s.disarmed {
+		s.disarmed = past
+		return false
+	}
+	s.disarmed = past
+	return past
+}
+
+// Reset implements the Condition interface for Slope.
+func (s *Slope) Reset() {
+	s.started = false
+	s.disarmed = false
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package trigger
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fired returns the indexes of the samples at which the condition fires, with
+// the samples 1 ms apart.
+func fired(c Condition, samples []float64) []int {
+	var indexes []int
+	for i, v := range samples {
+		if c.Fired(v, time.Millisecond) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+func TestConditions(t *testing.T) {
+	// A noisy signal rising through 1 V, falling back through it, and rising
+	// again.
+	samples := []float64{0, 0.5, 0.95, 1.05, 0.98, 1.02, 1.5, 1.1, 0.97, 0.5, 0.2, 1.2}
+	testCases := []struct {
+		name      string
+		condition Condition
+		expected  []int
+	}{
+		{"rising edge", &Edge{Level: 1, Hysteresis: 0.1}, []int{3, 11}},
+		{"rising edge without hysteresis", &Edge{Level: 1}, []int{3, 5, 11}},
+		{"falling edge", &Edge{Level: 1, Hysteresis: 0.1, Direction: Falling}, []int{8}},
+		{"either edge", &Edge{Level: 1, Hysteresis: 0.1, Direction: Either}, []int{3, 8, 11}},
+		{"level above", &Level{Level: 1, Hysteresis: 0.1}, []int{3, 11}},
+		{"level below", &Level{Level: 0.6, Below: true}, []int{0, 9}},
+		{"window inside", &Window{Low: 0.9, High: 1.1, Hysteresis: 0.05}, []int{2, 7}},
+		{"window outside", &Window{Low: 0.4, High: 1.3, Outside: true}, []int{0, 6, 10}},
+		{"rising slope", &Slope{Rate: 300}, []int{1, 6, 11}},
+		{"falling slope", &Slope{Rate: 300, Direction: Falling}, []int{7, 9}},
+	}
+	for _, tc := range testCases {
+		computed := fired(tc.condition, samples)
+		if !reflect.DeepEqual(computed, tc.expected) {
+			t.Errorf("%s: expected to fire at %v, got %v", tc.name, tc.expected, computed)
+		}
+		tc.condition.Reset()
+		if computed := fired(tc.condition, samples); !reflect.DeepEqual(computed, tc.expected) {
+			t.Errorf("%s after reset: expected to fire at %v, got %v", tc.name, tc.expected, computed)
+		}
+	}
+}
+
+func TestEdgeAtLevel(t *testing.T) {
+	// A signal that rises to 1 V and sits there, dips and returns, and then
+	// peaks and falls back to 1 V.
+	samples := []float64{0, 1, 1, 1, 0.5, 1, 1, 1.5, 1, 1}
+	testCases := []struct {
+		name      string
+		condition Condition
+		expected  []int
+	}{
+		{"rising edge", &Edge{Level: 1}, []int{1, 5}},
+		{"falling edge", &Edge{Level: 1, Direction: Falling}, []int{8}},
+		{"either edge", &Edge{Level: 1, Direction: Either}, []int{1, 5, 8}},
+	}
+	for _, tc := range testCases {
+		if computed := fired(tc.condition, samples); !reflect.DeepEqual(computed, tc.expected) {
+			t.Errorf("%s: expected to fire at %v, got %v", tc.name, tc.expected, computed)
+		}
+	}
+}
+
+func TestDirectionString(t *testing.T) {
+	if Falling.String() != "falling" {
+		t.Errorf("Expected falling, got %s", Falling)
+	}
+}
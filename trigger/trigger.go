@@ -0,0 +1,213 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+// Package trigger provides software triggers evaluated on a stream of decoded
+// analog input frames, capturing a block of scans around each trigger like an
+// oscilloscope does.
+package trigger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// Capture is a block of scans captured around a trigger. Frame holds the
+// pre-trigger scans followed by the post-trigger scans, starting with the
+// trigger scan at Frame.Raw[i][TriggerOffset].
+type Capture struct {
+	Scan          uint64       // Index of the trigger scan since the scan started
+	Time          time.Time    // Pacer time of the trigger scan
+	TriggerOffset int          // Position of the trigger scan in Frame
+	Frame         mccdaq.Frame // Scans captured around the trigger
+}
+
+// Trigger evaluates a Condition on one channel of a stream of frames, and
+// captures the given number of scans before and after each time it fires. A
+// ring buffer keeps the most recent pre-trigger scans, so a capture holds
+// fewer pre-trigger scans only if the trigger fires soon after the stream
+// starts or after a gap. While a capture is collecting its post-trigger scans,
+// the Condition is still evaluated but can't start another capture.
+type Trigger struct {
+	channel     int
+	condition   Condition
+	preTrigger  int
+	postTrigger int
+
+	channels []int
+	next     uint64
+	started  bool
+	// The ring buffer holds count scans, the oldest at head, by position in
+	// channels.
+	ringRaw   [][]uint16
+	ringVolts [][]float64
+	head      int
+	count     int
+	// capture is the capture collecting its post-trigger scans, of which
+	// remaining are still to come.
+	capture   *Capture
+	remaining int
+	watcher   mccdaq.Watcher
+}
+
+// New creates a Trigger that evaluates the condition on the given channel
+// number and captures preTrigger scans before the trigger scan and
+// postTrigger scans from the trigger scan onward.
+func New(channel int, condition Condition, preTrigger, postTrigger int) (*Trigger, error) {
+	if condition == nil {
+		return nil, fmt.Errorf("trigger needs a condition")
+	}
+	if preTrigger < 0 || postTrigger < 1 {
+		return nil, fmt.Errorf("bad capture of %d pre-trigger and %d post-trigger scans",
+			preTrigger, postTrigger)
+	}
+	t := Trigger{
+		channel:     channel,
+		condition:   condition,
+		preTrigger:  preTrigger,
+		postTrigger: postTrigger,
+	}
+	return &t, nil
+}
+
+// Process evaluates the trigger on each scan of the frame, which must follow
+// on from the previous frame, and returns any captures completed by the
+// frame. If scans were lost before the frame, or the enabled channels change,
+// the pre-trigger history and any capture in progress are discarded and the
+// Condition is reset. Process fails with mccdaq.ErrInvalidChannel if the
+// trigger channel isn't in the frame, and with mccdaq.ErrNotSupported if the
+// Condition is a Slope and the frame's timebase has no scan period.
+func (t *Trigger) Process(frame mccdaq.Frame) ([]Capture, error) {
+	position := frame.Position(t.channel)
+	if position < 0 {
+		return nil, fmt.Errorf("trigger channel %d not in frame: %w", t.channel, mccdaq.ErrInvalidChannel)
+	}
+	if _, ok := t.condition.(*Slope); ok && frame.Timebase.Period <= 0 {
+		return nil, fmt.Errorf("slope trigger needs the scan period: %w", mccdaq.ErrNotSupported)
+	}
+	if !t.started || frame.Index != t.next || frame.LostScans > 0 || !frame.SameChannels(t.channels) {
+		t.reset(frame.Channels)
+	}
+	var captures []Capture
+	for scan := 0; scan < frame.NumScans(); scan++ {
+		fired := t.condition.Fired(frame.Volts[position][scan], frame.Timebase.Period)
+		if fired && t.capture == nil {
+			t.startCapture(frame, scan)
+		}
+		if t.capture != nil {
+			t.appendScan(&t.capture.Frame, frame, scan)
+			t.remaining--
+			if t.remaining == 0 {
+				captures = append(captures, *t.capture)
+				t.capture = nil
+			}
+		}
+		t.push(frame, scan)
+	}
+	t.next = frame.Index + uint64(frame.NumScans())
+	return captures, nil
+}
+
+// Watch processes each frame received from frames, such as a Stream's
+// Frames, and sends the captures on the returned channel, which is closed
+// once frames is closed, processing fails, or the context is done, after
+// which Err reports why.
+func (t *Trigger) Watch(ctx context.Context, frames <-chan mccdaq.Frame) <-chan Capture {
+	captures := make(chan Capture)
+	t.watcher.Watch(ctx, frames, func(frame mccdaq.Frame) error {
+		completed, err := t.Process(frame)
+		if err != nil {
+			return err
+		}
+		for _, capture := range completed {
+			select {
+			case captures <- capture:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	}, func() { close(captures) })
+	return captures
+}
+
+// Err returns the error that stopped Watch, if any.
+func (t *Trigger) Err() error {
+	return t.watcher.Err()
+}
+
+// reset discards the history and any capture in progress for a stream of
+// frames with the given channels.
+func (t *Trigger) reset(channels []int) {
+	t.channels = append(t.channels[:0], channels...)
+	t.ringRaw = make([][]uint16, len(channels))
+	t.ringVolts = make([][]float64, len(channels))
+	for i := range channels {
+		t.ringRaw[i] = make([]uint16, t.preTrigger)
+		t.ringVolts[i] = make([]float64, t.preTrigger)
+	}
+	t.head, t.count = 0, 0
+	t.capture = nil
+	t.condition.Reset()
+	t.started = true
+}
+
+// startCapture starts a capture triggered at the given scan of the frame,
+// beginning with the pre-trigger scans in the ring buffer.
+func (t *Trigger) startCapture(frame mccdaq.Frame, scan int) {
+	index := frame.Index + uint64(scan)
+	capture := Capture{
+		Scan:          index,
+		Time:          frame.Timebase.Time(index),
+		TriggerOffset: t.count,
+		Frame: mccdaq.Frame{
+			Index:    index - uint64(t.count),
+			Timebase: frame.Timebase,
+			Channels: append([]int{}, frame.Channels...),
+			Raw:      make([][]uint16, len(frame.Channels)),
+			Volts:    make([][]float64, len(frame.Channels)),
+		},
+	}
+	size := t.count + t.postTrigger
+	for i := range frame.Channels {
+		capture.Frame.Raw[i] = make([]uint16, 0, size)
+		capture.Frame.Volts[i] = make([]float64, 0, size)
+		for j := 0; j < t.count; j++ {
+			k := (t.head + j) % t.preTrigger
+			capture.Frame.Raw[i] = append(capture.Frame.Raw[i], t.ringRaw[i][k])
+			capture.Frame.Volts[i] = append(capture.Frame.Volts[i], t.ringVolts[i][k])
+		}
+	}
+	t.capture = &capture
+	t.remaining = t.postTrigger
+}
+
+// appendScan appends the given scan of the frame to the captured frame.
+func (t *Trigger) appendScan(captured *mccdaq.Frame, frame mccdaq.Frame, scan int) {
+	for i := range frame.Channels {
+		captured.Raw[i] = append(captured.Raw[i], frame.Raw[i][scan])
+		captured.Volts[i] = append(captured.Volts[i], frame.Volts[i][scan])
+	}
+}
+
+// push adds the given scan of the frame to the ring buffer, overwriting the
+// oldest scan once the buffer is full.
+func (t *Trigger) push(frame mccdaq.Frame, scan int) {
+	if t.preTrigger == 0 {
+		return
+	}
+	k := (t.head + t.count) % t.preTrigger
+	if t.count == t.preTrigger {
+		t.head = (t.head + 1) % t.preTrigger
+	} else {
+		t.count++
+	}
+	for i := range frame.Channels {
+		t.ringRaw[i][k] = frame.Raw[i][scan]
+		t.ringVolts[i][k] = frame.Volts[i][scan]
+	}
+}
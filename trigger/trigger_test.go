@@ -0,0 +1,160 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package trigger
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// newFrames splits scans of channels 0 and 2 into frames of the given size.
+// Channel 0 reads the scan number, and channel 2 reads 0 V except for 5 V at
+// the given step scans.
+func newFrames(scans, size int, steps ...int) []mccdaq.Frame {
+	stepped := make(map[int]bool)
+	for _, step := range steps {
+		stepped[step] = true
+	}
+	tb := mccdaq.Timebase{Anchor: time.Unix(0, 0), Period: time.Millisecond}
+	var frames []mccdaq.Frame
+	for first := 0; first < scans; first += size {
+		frame := mccdaq.Frame{
+			Index:    uint64(first),
+			Timebase: tb,
+			Channels: []int{0, 2},
+			Raw:      make([][]uint16, 2),
+			Volts:    make([][]float64, 2),
+		}
+		for scan := first; scan < first+size && scan < scans; scan++ {
+			v := 0.0
+			if stepped[scan] {
+				v = 5.0
+			}
+			frame.Raw[0] = append(frame.Raw[0], uint16(scan))
+			frame.Volts[0] = append(frame.Volts[0], float64(scan))
+			frame.Raw[1] = append(frame.Raw[1], 0)
+			frame.Volts[1] = append(frame.Volts[1], v)
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+// scanNumbers returns the scan numbers read on channel 0 of the capture.
+func scanNumbers(capture Capture) []uint16 {
+	return capture.Frame.Raw[0]
+}
+
+func TestTriggerCaptures(t *testing.T) {
+	testCases := []struct {
+		steps   []int
+		scans   []uint64
+		offsets []int
+		first   []uint16
+	}{
+		// The pre-trigger scans span two frames.
+		{[]int{25}, []uint64{25}, []int{5}, []uint16{20}},
+		// Too soon after the start for all the pre-trigger scans.
+		{[]int{2}, []uint64{2}, []int{2}, []uint16{0}},
+		// The second step is during the first capture, so it's ignored.
+		{[]int{12, 16, 40}, []uint64{12, 40}, []int{5, 5}, []uint16{7, 35}},
+	}
+	for _, tc := range testCases {
+		trig, err := New(2, &Level{Level: 2.5, Hysteresis: 1}, 5, 8)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		var captures []Capture
+		for _, frame := range newFrames(60, 10, tc.steps...) {
+			completed, err := trig.Process(frame)
+			if err != nil {
+				t.Fatalf("Process: %v", err)
+			}
+			captures = append(captures, completed...)
+		}
+		if len(captures) != len(tc.scans) {
+			t.Fatalf("Steps %v: expected %d captures, got %d", tc.steps, len(tc.scans), len(captures))
+		}
+		for i, capture := range captures {
+			if capture.Scan != tc.scans[i] || capture.TriggerOffset != tc.offsets[i] {
+				t.Errorf("Steps %v: expected trigger at scan %d offset %d, got scan %d offset %d",
+					tc.steps, tc.scans[i], tc.offsets[i], capture.Scan, capture.TriggerOffset)
+			}
+			var expected []uint16
+			for scan := tc.first[i]; scan < uint16(tc.scans[i])+8; scan++ {
+				expected = append(expected, scan)
+			}
+			if !reflect.DeepEqual(scanNumbers(capture), expected) {
+				t.Errorf("Steps %v: expected scans %v, got %v", tc.steps, expected, scanNumbers(capture))
+			}
+			if capture.Frame.Index != uint64(tc.first[i]) || capture.Frame.Volts[1][capture.TriggerOffset] != 5.0 {
+				t.Errorf("Steps %v: expected frame at scan %d with the step at the trigger offset",
+					tc.steps, tc.first[i])
+			}
+			if !capture.Time.Equal(time.Unix(0, 0).Add(time.Duration(tc.scans[i]) * time.Millisecond)) {
+				t.Errorf("Steps %v: wrong trigger time %v", tc.steps, capture.Time)
+			}
+		}
+	}
+}
+
+func TestTriggerGap(t *testing.T) {
+	trig, _ := New(2, &Level{Level: 2.5}, 5, 3)
+	frames := newFrames(40, 10, 22)
+	frames[2].LostScans = 100
+	var captures []Capture
+	for _, frame := range frames {
+		completed, _ := trig.Process(frame)
+		captures = append(captures, completed...)
+	}
+	// The history before the gap at scan 20 is discarded.
+	if len(captures) != 1 || captures[0].TriggerOffset != 2 {
+		t.Fatalf("Expected one capture with 2 pre-trigger scans, got %v", captures)
+	}
+}
+
+func TestTriggerErrors(t *testing.T) {
+	if _, err := New(0, nil, 1, 1); err == nil {
+		t.Errorf("Expected error for nil condition")
+	}
+	if _, err := New(0, &Edge{}, 1, 0); err == nil {
+		t.Errorf("Expected error for no post-trigger scans")
+	}
+	trig, _ := New(5, &Edge{}, 1, 1)
+	if _, err := trig.Process(newFrames(10, 10)[0]); !errors.Is(err, mccdaq.ErrInvalidChannel) {
+		t.Errorf("Expected ErrInvalidChannel, got %v", err)
+	}
+	trig, _ = New(2, &Slope{Rate: 1}, 1, 1)
+	frame := newFrames(10, 10)[0]
+	frame.Timebase.Period = 0
+	if _, err := trig.Process(frame); !errors.Is(err, mccdaq.ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestTriggerWatch(t *testing.T) {
+	trig, _ := New(2, &Edge{Level: 2.5}, 2, 2)
+	frames := make(chan mccdaq.Frame)
+	captures := trig.Watch(context.Background(), frames)
+	go func() {
+		for _, frame := range newFrames(30, 10, 5, 25) {
+			frames <- frame
+		}
+		close(frames)
+	}()
+	var scans []uint64
+	for capture := range captures {
+		scans = append(scans, capture.Scan)
+	}
+	if !reflect.DeepEqual(scans, []uint64{5, 25}) || trig.Err() != nil {
+		t.Errorf("Expected captures at scans [5 25], got %v and %v", scans, trig.Err())
+	}
+}
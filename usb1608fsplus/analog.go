@@ -6,28 +6,84 @@
 package usb1608fsplus
 
 import (
+	"context"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"math"
+	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/gotmc/libusb"
+	"github.com/gotmc/mccdaq"
+	"github.com/gotmc/mccdaq/alarm"
+	"github.com/gotmc/mccdaq/bridge"
+	"github.com/gotmc/mccdaq/scale"
+	"github.com/gotmc/mccdaq/sensor"
+	"github.com/gotmc/mccdaq/thermocouple"
 )
 
 // AnalogInput models an analog input for the MCC DAQ.
 type AnalogInput struct {
-	DAQ               DAQer        `json:"-"`
-	Frequency         float64      `json:"freq"`
-	TransferMode      TransferMode `json:"block_transfer"`
-	Trigger           TriggerType  `json:"trigger"`
-	UseExternalPacer  bool         `json:"ext_pacer"`
-	OutputPacerOnSync bool         `json:"output_sync"`
-	DebugMode         bool         `json:"debug_mode"`
-	Stall             Stall        `json:"stall_overrun"`
-	Channels          Channels     `json:"channels"`
+	DAQ               DAQer         `json:"-"`
+	Frequency         float64       `json:"freq"`
+	TransferMode      TransferMode  `json:"block_transfer"`
+	Trigger           TriggerType   `json:"trigger"`
+	UseExternalPacer  bool          `json:"ext_pacer"`
+	OutputPacerOnSync bool          `json:"output_sync"`
+	DebugMode         bool          `json:"debug_mode"`
+	Stall             Stall         `json:"stall_overrun"`
+	Channels          Channels      `json:"channels"`
+	OverrunPolicy     OverrunPolicy `json:"overrun_policy"`
+	DriftInterval     time.Duration `json:"-"`
+	Logger            mccdaq.Logger `json:"-"`
+	serialNumber      string
+	// reading is nonzero while a goroutine is reading the scan data.
+	reading int32
+	// pending holds scan data read from the DAQ but not yet returned by Read,
+	// and buf is the buffer it's read into.
+	pending []byte
+	buf     []byte
+	// scanSize is the number of bytes in a scan with a fixed number of scans,
+	// remaining is the number of those bytes not yet read from the DAQ, and
+	// scanDone is set once there's no more data to read from the DAQ.
+	scanSize  int
+	remaining int
+	scanDone  bool
+	// numScans is the number of scans requested when the scan was started,
+	// and scansReturned and scansLost count the scans returned by Read and
+	// those estimated lost to overruns since then.
+	numScans      int
+	scansReturned uint64
+	scansLost     uint64
+	// started is when the scan was last started on the DAQ, bytesAcquired is
+	// the number of bytes read from the DAQ since then, and overrun is set
+	// once an overrun has stopped the scan and it's waiting to be restarted.
+	started       time.Time
+	bytesAcquired uint64
+	overrun       bool
+	// timeMu guards timebase, which is anchored when the first scan data
+	// arrives after the scan is started, and reanchored when it arrives after
+	// the scan is restarted at scan firstScan. drift estimates the drift of
+	// the host clock from the pacer clock since the timebase was anchored.
+	timeMu    sync.Mutex
+	timebase  mccdaq.Timebase
+	anchored  bool
+	reanchor  bool
+	firstScan uint64
+	drift     mccdaq.DriftEstimator
+	// tableMu guards tables, which cache the voltage for every ADC code of
+	// each channel, without and with calibration.
+	tableMu sync.Mutex
+	tables  [2][numChannels]voltageTable
 }
 
+// timeNow returns the current time. It's a variable so that the tests can
+// control how many scans appear to be lost to an overrun.
+var timeNow = time.Now
+
 // Channel models a single channel of an analog input.
 type Channel struct {
 	Enabled     bool         `json:"enabled"`
@@ -35,6 +91,23 @@ type Channel struct {
 	Description string       `json:"desc"`
 	Slopes      Slopes       `json:"slopes"`
 	Intercepts  Intercepts   `json:"intercepts"`
+	// Unit names the engineering units the channel's Scale converts volts
+	// into, such as "psi" or "L/min".
+	Unit  string       `json:"unit,omitempty"`
+	Scale *scale.Scale `json:"scale,omitempty"`
+	// Thermocouple, if set, is the type of thermocouple on the channel, whose
+	// volts a thermocouple.Converter converts into °C.
+	Thermocouple *thermocouple.Type `json:"thermocouple,omitempty"`
+	// Bridge, if set, configures the bridge sensor on the channel, whose volts
+	// a bridge.Converter converts into microstrain or load.
+	Bridge *bridge.Config `json:"bridge,omitempty"`
+	// Sensor, if set, configures the thermistor, RTD, or 4–20 mA current
+	// loop on the channel, whose volts a sensor.Converter converts into °C or
+	// the engineering units of the loop.
+	Sensor *sensor.Config `json:"sensor,omitempty"`
+	// Limits, if set, are the alarm limits of the channel, in its engineering
+	// units if it has a Scale, and otherwise in volts.
+	Limits *alarm.Limits `json:"limits,omitempty"`
 }
 
 // Intercepts contains the offsets based on the voltage range.
@@ -81,7 +154,7 @@ func (vr *VoltageRange) Set(s string) error {
 	// Ensure the provided string matches one of the keys in the map
 	got, ok := InputRanges[s]
 	if !ok {
-		return fmt.Errorf("Invalid VoltageRange %q", s)
+		return fmt.Errorf("Invalid VoltageRange %q: %w", s, mccdaq.ErrInvalidRange)
 	}
 	// Set the voltage range to the value found in the map per the key
 	*vr = got
@@ -95,9 +168,15 @@ func (vr *VoltageRange) MarshalJSON() ([]byte, error) {
 
 // NewAnalogInput is used to create a new AnalogInput for the given DAQer.
 func (daq *USB1608fsplus) NewAnalogInput() (*AnalogInput, error) {
-	gainTable, err := daq.BuildGainTable()
+	return daq.NewAnalogInputContext(context.Background())
+}
+
+// NewAnalogInputContext creates a new AnalogInput like NewAnalogInput, but
+// stops reading the gain table from the DAQ once the context is done.
+func (daq *USB1608fsplus) NewAnalogInputContext(ctx context.Context) (*AnalogInput, error) {
+	gainTable, err := daq.BuildGainTableContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("Error reading gain table from DAQ: %s", err)
+		return nil, fmt.Errorf("Error reading gain table from DAQ: %w", err)
 	}
 	var channels [numChannels]Channel
 	for i := 0; i < len(channels); i++ {
@@ -120,6 +199,8 @@ func (daq *USB1608fsplus) NewAnalogInput() (*AnalogInput, error) {
 		DebugMode:         false,
 		Stall:             StallOnOverrun,
 		Channels:          channels,
+		Logger:            daq.Logger,
+		serialNumber:      daq.serialNumber,
 	}
 	return &analogInput, nil
 }
@@ -142,6 +223,139 @@ func (channels *Channels) Enabled() byte {
 	return enabledChannels
 }
 
+// enabledChannelNumbers returns the numbers of the enabled channels in the
+// order the DAQ sends them in the scan data.
+func (ai *AnalogInput) enabledChannelNumbers() []int {
+	enabled := ai.Channels.Enabled()
+	var channels []int
+	for ch := 0; ch < len(ai.Channels); ch++ {
+		if enabled&(0x1<<uint(ch)) != 0 {
+			channels = append(channels, ch)
+		}
+	}
+	return channels
+}
+
+// Descriptions returns the description of each enabled channel keyed by
+// channel number, for labeling results computed from the scan data.
+func (ai *AnalogInput) Descriptions() map[int]string {
+	descriptions := make(map[int]string)
+	for _, ch := range ai.enabledChannelNumbers() {
+		descriptions[ch] = ai.Channels[ch].Description
+	}
+	return descriptions
+}
+
+// conversions returns how many of the conversions from volts, such as a Scale
+// or a Thermocouple, the channel has, of which it may have only one.
+func (ch Channel) conversions() int {
+	n := 0
+	if ch.Scale != nil {
+		n++
+	}
+	if ch.Thermocouple != nil {
+		n++
+	}
+	if ch.Bridge != nil {
+		n++
+	}
+	if ch.Sensor != nil {
+		n++
+	}
+	return n
+}
+
+// Units returns the engineering units of each enabled channel keyed by
+// channel number, which are °C for a thermocouple, thermistor, or RTD
+// channel, µε for a strain gauge bridge, and volts for a channel without a
+// conversion, unless the channel's Unit says otherwise.
+func (ai *AnalogInput) Units() map[int]string {
+	units := make(map[int]string)
+	for _, ch := range ai.enabledChannelNumbers() {
+		channel := ai.Channels[ch]
+		switch {
+		case channel.Unit == "" && channel.Thermocouple != nil:
+			units[ch] = "°C"
+		case channel.Unit == "" && channel.Bridge != nil:
+			units[ch] = channel.Bridge.Unit()
+		case channel.Unit == "" && channel.Sensor != nil:
+			units[ch] = channel.Sensor.Unit()
+		case channel.Unit == "" && channel.conversions() == 0:
+			units[ch] = "V"
+		default:
+			units[ch] = channel.Unit
+		}
+	}
+	return units
+}
+
+// Scales returns the scale of each enabled channel that has one, for
+// converting the frames of a Stream into engineering units.
+func (ai *AnalogInput) Scales() (scale.Scales, error) {
+	scales := make(scale.Scales)
+	for _, ch := range ai.enabledChannelNumbers() {
+		if sc := ai.Channels[ch].Scale; sc != nil {
+			if ai.Channels[ch].conversions() > 1 {
+				return nil, fmt.Errorf("channel %d has a scale and another conversion", ch)
+			}
+			if err := sc.Validate(); err != nil {
+				return nil, fmt.Errorf("channel %d: %w", ch, err)
+			}
+			scales[ch] = *sc
+		}
+	}
+	return scales, nil
+}
+
+// Thermocouples returns the thermocouple type of each enabled thermocouple
+// channel keyed by channel number, for creating a thermocouple.Converter.
+func (ai *AnalogInput) Thermocouples() map[int]thermocouple.Type {
+	thermocouples := make(map[int]thermocouple.Type)
+	for _, ch := range ai.enabledChannelNumbers() {
+		if t := ai.Channels[ch].Thermocouple; t != nil {
+			thermocouples[ch] = *t
+		}
+	}
+	return thermocouples
+}
+
+// Bridges returns the bridge config of each enabled bridge channel keyed by
+// channel number, for creating a bridge.Converter.
+func (ai *AnalogInput) Bridges() map[int]bridge.Config {
+	bridges := make(map[int]bridge.Config)
+	for _, ch := range ai.enabledChannelNumbers() {
+		if b := ai.Channels[ch].Bridge; b != nil {
+			bridges[ch] = *b
+		}
+	}
+	return bridges
+}
+
+// Sensors returns the sensor config of each enabled thermistor, RTD, or
+// current loop channel keyed by channel number, for creating a
+// sensor.Converter.
+func (ai *AnalogInput) Sensors() map[int]sensor.Config {
+	sensors := make(map[int]sensor.Config)
+	for _, ch := range ai.enabledChannelNumbers() {
+		if s := ai.Channels[ch].Sensor; s != nil {
+			sensors[ch] = *s
+		}
+	}
+	return sensors
+}
+
+// Limits returns the alarm limits of each enabled channel that has them keyed
+// by channel number, for creating an alarm.Engine.
+func (ai *AnalogInput) Limits() map[int]alarm.Limits {
+	limits := make(map[int]alarm.Limits)
+	for _, ch := range ai.enabledChannelNumbers() {
+		if l := ai.Channels[ch].Limits; l != nil {
+			limits[ch] = *l
+		}
+	}
+	return limits
+}
+
 // Options returns the analog input scan options byte containing the following
 // bit fields:
 //
@@ -224,6 +438,36 @@ func (ai *AnalogInput) Options() byte {
    before further scan can be performed.
 */
 func (ai *AnalogInput) StartScan(numScans int) error {
+	return ai.StartScanContext(context.Background(), numScans)
+}
+
+// StartScanContext starts an analog input scan like StartScan, but doesn't
+// send any further commands to the DAQ once the context is done. The scan
+// configuration is first checked using Plan, and a scan that the DAQ can't
+// carry out isn't started.
+func (ai *AnalogInput) StartScanContext(ctx context.Context, numScans int) error {
+	plan, err := ai.Plan(numScans)
+	if err != nil {
+		return err
+	}
+	if err := ai.startScan(ctx, numScans); err != nil {
+		return err
+	}
+	ai.logger().Debug("Started analog input scan",
+		"serial_number", ai.serialNumber, "scans", numScans,
+		"requested_hz", plan.Requested, "rate_hz", plan.Rate)
+	ai.numScans = numScans
+	ai.scansReturned = 0
+	ai.scansLost = 0
+	ai.timeMu.Lock()
+	ai.anchored = false
+	ai.timeMu.Unlock()
+	ai.resetTimebase(0)
+	return nil
+}
+
+// startScan starts the scan on the DAQ and resets the state used to read it.
+func (ai *AnalogInput) startScan(ctx context.Context, numScans int) error {
 	freq := ai.Frequency
 	if ai.UseExternalPacer {
 		freq = 0
@@ -232,18 +476,34 @@ func (ai *AnalogInput) StartScan(numScans int) error {
 	if len(data) != 10 {
 		return fmt.Errorf("scan data length is %d bytes; expected 10 bytes", len(data))
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	err := ai.StopScan()
 	if err != nil {
-		return fmt.Errorf("error stopping analog scan prior to starting a new scan: %s", err)
+		return fmt.Errorf("error stopping analog scan prior to starting a new scan: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 	err = ai.ClearScanBuffer()
 	if err != nil {
-		return fmt.Errorf("error clearing buffer prior to starting a new scan %s", err)
+		return fmt.Errorf("error clearing buffer prior to starting a new scan %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 	_, err = ai.DAQ.SendCommandToDevice(commandAnalogStartScan, data)
 	if err != nil {
-		return fmt.Errorf("error starting analog input scan %s", err)
+		return fmt.Errorf("error starting analog input scan %w", err)
 	}
+	ai.pending = nil
+	ai.scanSize = numScans * ai.NumEnabledChannels() * bytesPerWord
+	ai.remaining = ai.scanSize
+	ai.scanDone = false
+	ai.started = timeNow()
+	ai.bytesAcquired = 0
+	ai.overrun = false
 	return nil
 }
 
@@ -263,60 +523,416 @@ func (ai *AnalogInput) NumEnabledChannels() int {
 // of the given byte slice. This function replaces the old ReadScan(numScans int)
 // ([]byte, error), since that put pressure on the garabage collector by requiring
 // an allocation every time the function was called, since it returned a byte slice.
+//
+// Read only ever reads whole scans, so n is a multiple of the scan size (two
+// bytes per enabled channel) and any bytes in p past the last whole scan are
+// left untouched. The bulk transfers from the DAQ are buffered internally, so
+// p may be any size that holds at least one scan. Once all the scans of a
+// scan with a fixed number of scans have been read, Read returns io.EOF.
+//
+// If the scan overruns the DAQ's FIFO buffer, Read follows the OverrunPolicy.
+// When restarting, the scan is stopped, the FIFO buffer and the bulk endpoint
+// halt are cleared, and the scan is restarted with the same configuration;
+// a scan with a fixed number of scans is restarted for the scans not yet
+// read. Any partial scan read before the overrun is dropped.
 func (ai *AnalogInput) Read(p []byte) (n int, err error) {
-	bytesToRead := len(p)
-	wordsToRead := bytesToRead / bytesPerWord
-	if (bytesToRead % maxBulkTransferPacketSize) != 0 {
-		return n, fmt.Errorf("%d bytes to read is not a multiple of maxBulkTransferPacketSize",
-			bytesToRead)
+	return ai.ReadContext(context.Background(), p)
+}
+
+// ReadContext reads the analog input data like Read, but gives up once the
+// context is done. If the context is done before p has been filled, the scan
+// is stopped and the scan FIFO buffer cleared, so that the DAQ isn't left
+// acquiring data that nobody is going to read, and the context's error is
+// returned. Only one goroutine at a time may read the scan data; any other
+// concurrent read fails with mccdaq.ErrBusy.
+func (ai *AnalogInput) ReadContext(ctx context.Context, p []byte) (n int, err error) {
+	if !atomic.CompareAndSwapInt32(&ai.reading, 0, 1) {
+		return 0, mccdaq.ErrBusy
+	}
+	defer atomic.StoreInt32(&ai.reading, 0)
+	scanBytes := ai.NumEnabledChannels() * bytesPerWord
+	if scanBytes == 0 {
+		return 0, fmt.Errorf("no channels enabled to read: %w", mccdaq.ErrInvalidChannel)
+	}
+	defer func() { ai.scansReturned += uint64(n / scanBytes) }()
+	bytesToRead := len(p) / scanBytes * scanBytes
+	if bytesToRead == 0 {
+		return 0, fmt.Errorf("%d byte buffer can't hold a %d byte scan: %w",
+			len(p), scanBytes, io.ErrShortBuffer)
+	}
+	for n < bytesToRead {
+		if len(ai.pending) > 0 {
+			copied := copy(p[n:bytesToRead], ai.pending)
+			ai.pending = ai.pending[copied:]
+			n += copied
+			continue
+		}
+		if ai.overrun {
+			// The rest of the partial scan was lost to the overrun.
+			n -= n % scanBytes
+			gap, err := ai.restartAfterOverrun(ctx, uint64(n/scanBytes))
+			if err != nil {
+				return n, err
+			}
+			if gap != nil && ai.OverrunPolicy == OverrunRestartWithGaps {
+				gap.BytesRead = n
+				return n, gap
+			}
+			continue
+		}
+		if ai.scanDone {
+			break
+		}
+		if err := ai.fill(ctx, bytesToRead-n); err != nil {
+			// Return the data read before the error, but keep any partial scan
+			// for the next read, so that the scans returned stay aligned to
+			// the channels. Once the scan is done, the partial scan can never
+			// be completed, so it's dropped.
+			copied := copy(p[n:bytesToRead], ai.pending)
+			ai.pending = ai.pending[copied:]
+			n += copied
+			partial := n % scanBytes
+			if !ai.scanDone {
+				ai.pending = append(append([]byte{}, p[n-partial:n]...), ai.pending...)
+			}
+			n -= partial
+			var overrun *mccdaq.OverrunError
+			if errors.As(err, &overrun) {
+				overrun.BytesRead = n
+			}
+			return n, err
+		}
+	}
+	if n == 0 && ai.scanDone {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// fill reads at least one bulk transfer's worth of scan data from the DAQ into
+// the pending buffer. In block transfer mode, the number of bytes requested is
+// rounded up to a whole number of bulk packets, but never past the end of a
+// scan with a fixed number of scans, whose final packet may be short.
+func (ai *AnalogInput) fill(ctx context.Context, need int) error {
+	size := need
+	if ai.TransferMode == BlockTransfer {
+		size = roundUpToPacket(need)
+		if ai.remaining > 0 && size > roundUpToPacket(ai.remaining) {
+			size = roundUpToPacket(ai.remaining)
+		}
+	}
+	if cap(ai.buf) < size {
+		ai.buf = make([]byte, size)
+	}
+	got, err := ai.readTransfers(ctx, ai.buf[:size])
+	ai.pending = ai.buf[:got]
+	ai.bytesAcquired += uint64(got)
+	if got > 0 {
+		ai.observeArrival(timeNow())
+	}
+	if ai.remaining > 0 {
+		ai.remaining -= got
+		if ai.remaining <= 0 {
+			ai.remaining = 0
+			ai.scanDone = true
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if ai.scanDone && ai.TransferMode == BlockTransfer &&
+		ai.scanSize%maxBulkTransferPacketSize == 0 {
+		// A scan that ends on a packet boundary is terminated by a zero-length
+		// packet, which needs to be read before the next scan.
+		var zlp [maxBulkTransferPacketSize]byte
+		_, _ = ai.readData(ctx, zlp[:], true)
+	}
+	status, err := ai.DAQ.Status()
+	if err != nil {
+		return fmt.Errorf("error getting status during analog bulk read %w", err)
+	}
+	if status&byte(scanOverrun) != 0 {
+		return ai.stopAfterOverrun(got)
+	}
+	return nil
+}
+
+// stopAfterOverrun stops the scan, clears the scan FIFO buffer, and clears the
+// halt on the bulk endpoint after an overrun, and drops the partial scan at
+// the end of the pending data. Unless the scan is to be restarted, the scan is
+// done and a *mccdaq.OverrunError is returned.
+func (ai *AnalogInput) stopAfterOverrun(bytes int) error {
+	ai.logger().Warn("Analog input scan overrun",
+		"serial_number", ai.serialNumber, "bytes", bytes, "policy", ai.OverrunPolicy)
+	scanBytes := uint64(ai.NumEnabledChannels() * bytesPerWord)
+	partial := int(ai.bytesAcquired % scanBytes)
+	if partial > len(ai.pending) {
+		partial = len(ai.pending)
+	}
+	ai.pending = ai.pending[:len(ai.pending)-partial]
+	err := ai.StopScan()
+	if e := ai.ClearScanBuffer(); err == nil {
+		err = e
+	}
+	if e := ai.DAQ.ClearHalt(); err == nil {
+		err = e
+	}
+	if err != nil {
+		ai.scanDone = true
+		return fmt.Errorf("error stopping analog input scan after %s: %w", mccdaq.ErrOverrun, err)
+	}
+	if ai.OverrunPolicy == OverrunFail {
+		ai.scanDone = true
+		return &mccdaq.OverrunError{}
+	}
+	ai.overrun = true
+	return nil
+}
+
+// restartAfterOverrun rewrites the scan ranges and restarts the scan stopped
+// by an overrun, given the number of scans returned so far by the current
+// read. It returns an OverrunError marking the gap, with the number of lost
+// scans estimated by restartScan. If a scan with a fixed number of scans has
+// no scans left to read, the scan is done and the returned OverrunError is
+// nil.
+func (ai *AnalogInput) restartAfterOverrun(
+	ctx context.Context, scansRead uint64,
+) (*mccdaq.OverrunError, error) {
+	scan := ai.scansReturned + scansRead + ai.scansLost
+	lost, restarted, err := ai.restartScan(ctx, scansRead)
+	if err != nil {
+		return nil, fmt.Errorf("error restarting analog input scan after %s: %w", mccdaq.ErrOverrun, err)
+	}
+	if !restarted {
+		return nil, nil
+	}
+	ai.logger().Warn("Restarted analog input scan after overrun",
+		"serial_number", ai.serialNumber, "scan", scan, "lost_scans", lost)
+	return &mccdaq.OverrunError{
+		Restarted: true,
+		Scan:      scan,
+		LostScans: lost,
+	}, nil
+}
+
+// restartScan rewrites the scan ranges and restarts a scan that stopped, given
+// the number of scans returned so far by the current read. A scan with a fixed
+// number of scans is restarted for the scans not yet read; if none are left,
+// the scan is done and restartScan returns false. The scans lost are estimated
+// from the time between starting and restarting the scan less the scans read
+// in between, and are added to scansLost, so that the timebase carries on from
+// the first scan after the gap.
+func (ai *AnalogInput) restartScan(
+	ctx context.Context, scansRead uint64,
+) (lost uint64, restarted bool, err error) {
+	returned := ai.scansReturned + scansRead
+	numScans := 0
+	if ai.numScans > 0 {
+		numScans = ai.numScans - int(returned)
+		if numScans <= 0 {
+			ai.overrun = false
+			ai.scanDone = true
+			return 0, false, nil
+		}
+	}
+	started := ai.started
+	acquired := ai.bytesAcquired / uint64(ai.NumEnabledChannels()*bytesPerWord)
+	if err := ai.SetScanRanges(); err != nil {
+		ai.scanDone = true
+		return 0, false, err
 	}
-	var data = make([]byte, bytesToRead)
+	if err := ai.startScan(ctx, numScans); err != nil {
+		ai.scanDone = true
+		return 0, false, err
+	}
+	expected := ai.started.Sub(started).Seconds() * ai.Frequency
+	if expected > float64(acquired) {
+		lost = uint64(math.Round(expected - float64(acquired)))
+	}
+	ai.resetTimebase(returned + ai.scansLost + lost)
+	ai.scansLost += lost
+	return lost, true, nil
+}
+
+// Timebase returns the timebase of the scan, which gives the time of each scan
+// from its index since the scan started. The scan period is derived from the
+// pacer period actually programmed into the DAQ, or from Frequency when using
+// an external pacer. The first scan is anchored to the host clock when the
+// first scan data arrives, by counting back from its arrival the scans it
+// holds. The drift estimate is updated every DriftInterval, which defaults to
+// mccdaq.DefaultDriftInterval. After an overrun restarts the scan, the
+// timebase is reanchored when the data after the gap arrives. Timebase
+// returns false until the first scan data has arrived.
+func (ai *AnalogInput) Timebase() (mccdaq.Timebase, bool) {
+	ai.timeMu.Lock()
+	defer ai.timeMu.Unlock()
+	return ai.timebase, ai.anchored
+}
+
+// resetTimebase sets up the timebase for a scan that was just started or
+// restarted at the given scan, so that it's anchored when scan data arrives.
+func (ai *AnalogInput) resetTimebase(firstScan uint64) {
+	ai.timeMu.Lock()
+	defer ai.timeMu.Unlock()
+	ai.firstScan = firstScan
+	ai.reanchor = true
+	ai.timebase.Period = ai.scanPeriod()
+	ai.timebase.Drift = mccdaq.Drift{}
+	ai.drift = mccdaq.DriftEstimator{Interval: ai.DriftInterval}
+}
+
+// observeArrival anchors the timebase if needed when scan data arrives at the
+// given time, and otherwise updates the drift estimate with how late the scans
+// acquired so far arrived compared to their pacer time.
+func (ai *AnalogInput) observeArrival(arrived time.Time) {
+	scanBytes := uint64(ai.NumEnabledChannels() * bytesPerWord)
+	scan := ai.firstScan + ai.bytesAcquired/scanBytes
+	ai.timeMu.Lock()
+	defer ai.timeMu.Unlock()
+	elapsed := time.Duration(scan) * ai.timebase.Period
+	if ai.reanchor {
+		ai.timebase.Anchor = arrived.Add(-elapsed)
+		ai.anchored = true
+		ai.reanchor = false
+		return
+	}
+	ai.timebase.Drift = ai.drift.Observe(elapsed, arrived.Sub(ai.timebase.Time(scan)))
+}
+
+// scanPeriod returns the time between scans for the pacer period sent to the
+// DAQ, or for Frequency when using an external pacer.
+func (ai *AnalogInput) scanPeriod() time.Duration {
+	if ai.Frequency <= 0 {
+		return 0
+	}
+	if ai.UseExternalPacer {
+		return time.Duration(float64(time.Second) / ai.Frequency)
+	}
+	return time.Duration(calculatePacerPeriod(ai.Frequency)+1) * pacerClockPeriod
+}
+
+// readTransfers fills p with scan data from the DAQ using the transfer mode,
+// stopping early at a short packet, which ends a scan with a fixed number of
+// scans. It returns the number of bytes read.
+func (ai *AnalogInput) readTransfers(ctx context.Context, p []byte) (n int, err error) {
 	switch ai.TransferMode {
 	case ImmediateTransfer:
-		for i := 0; i < wordsToRead; i++ {
-			var word = make([]byte, bytesPerWord)
-			bytesReceived, err := ai.DAQ.Read(word)
+		for n < len(p) {
+			bytesReceived, err := ai.readData(ctx, p[n:n+bytesPerWord], n > 0)
+			n += bytesReceived
 			if err != nil {
-				return n, fmt.Errorf("immediate scan error: %s", err)
+				if ctx.Err() != nil {
+					return n, ai.abortScan(ctx)
+				}
+				return n, fmt.Errorf("immediate scan error: %w", err)
 			}
-			n += bytesReceived
 			if bytesReceived != bytesPerWord {
-				return n, fmt.Errorf("immediate transfer of %d bytes instead of %d: %s",
-					bytesReceived, bytesPerWord, err)
+				return n, fmt.Errorf("immediate transfer of %d bytes instead of %d",
+					bytesReceived, bytesPerWord)
 			}
-			data[i] = word[0]
-			data[i+1] = word[1]
 		}
 	case BlockTransfer:
-		bytesReceived, err := ai.DAQ.Read(data)
-		if err != nil {
-			return n, fmt.Errorf("Problem with bulk scan %s", err)
-		}
-		n += bytesReceived
-		if bytesReceived != bytesToRead {
-			return n, fmt.Errorf("Didn't transfer %d bytes: %s", bytesToRead, err)
+		// Without a cancelable context, read everything in one bulk transfer.
+		// Otherwise, read in transfers that can be abandoned without losing
+		// data when the context is done.
+		for n < len(p) {
+			bytesInChunk := len(p) - n
+			if chunkSize := ai.transferSize(n > 0); ctx.Done() != nil && bytesInChunk > chunkSize {
+				bytesInChunk = chunkSize
+			}
+			bytesReceived, err := ai.readData(ctx, p[n:n+bytesInChunk], n > 0)
+			n += bytesReceived
+			if err != nil {
+				if ctx.Err() != nil {
+					return n, ai.abortScan(ctx)
+				}
+				return n, fmt.Errorf("Problem with bulk scan %w", err)
+			}
+			if bytesReceived < bytesInChunk {
+				break
+			}
 		}
 	default:
-		return n, fmt.Errorf("bad transfer mode")
+		return n, fmt.Errorf("bad transfer mode %d: %w", ai.TransferMode, mccdaq.ErrNotSupported)
 	}
-	status, err := ai.DAQ.Status()
-	if err != nil {
-		return n, fmt.Errorf("error getting status during analog bulk read %s", err)
+	return n, nil
+}
+
+// roundUpToPacket rounds the number of bytes up to a whole number of bulk
+// packets.
+func roundUpToPacket(bytes int) int {
+	return (bytes + maxBulkTransferPacketSize - 1) / maxBulkTransferPacketSize *
+		maxBulkTransferPacketSize
+}
+
+// logger returns the Logger for the analog input or the default logger if
+// none is set.
+func (ai *AnalogInput) logger() mccdaq.Logger {
+	if ai.Logger == nil {
+		return defaultLogger
 	}
-	// If bytesToRead is a multiple of wMaxPacketSize the device will send a zero
-	// byte packet.
-	if (bytesToRead%maxBulkTransferPacketSize) == 0 && (status&byte(scanRunning) == 0) {
-		data := make([]byte, bytesPerWord)
-		_, _ = ai.DAQ.Read(data)
+	return ai.Logger
+}
+
+// transferSize returns the number of bytes, a multiple of
+// maxBulkTransferPacketSize, to request in each bulk transfer of a read with a
+// cancelable context, given whether scan data has already arrived in the
+// read. Only a single packet can be polled for without losing data, so that's
+// all that's requested unless the DAQ's own pacer is sure to produce more in
+// half of contextPollTimeout. With an external pacer, or a trigger before the
+// first data arrives, the data may start partway through any transfer.
+func (ai *AnalogInput) transferSize(flowing bool) int {
+	if !ai.pacedInternally(flowing) {
+		return maxBulkTransferPacketSize
 	}
-	if status&byte(scanOverrun) != 0 {
-		log.Printf("Analog AIn scan overrun.\n")
-		ai.StopScan()
-		ai.ClearScanBuffer()
+	bytesPerSecond := ai.Frequency * float64(ai.NumEnabledChannels()*bytesPerWord)
+	packets := int(bytesPerSecond * contextPollTimeout / 2000 / maxBulkTransferPacketSize)
+	if packets < 1 {
+		packets = 1
+	}
+	return packets * maxBulkTransferPacketSize
+}
+
+// pacedInternally reports whether the scan data is known to be arriving at the
+// rate of the DAQ's own pacer, given whether scan data has already arrived in
+// the current read.
+func (ai *AnalogInput) pacedInternally(flowing bool) bool {
+	if ai.UseExternalPacer || ai.Frequency <= 0 {
+		return false
+	}
+	return flowing || ai.bytesAcquired > 0 || ai.Trigger == NoExternalTrigger
+}
+
+// readData reads p from the DAQ with the context. When the scan is paced
+// internally, the read fails with mccdaq.ErrTimeout once the pacer should
+// have produced the data with defaultTimeout to spare, so that a slow scan
+// isn't cut short but a stalled one doesn't wait forever. Otherwise, the
+// read waits for as long as the context allows.
+func (ai *AnalogInput) readData(ctx context.Context, p []byte, flowing bool) (int, error) {
+	if ctx.Done() == nil || !ai.pacedInternally(flowing) {
+		return ai.DAQ.ReadContext(ctx, p)
+	}
+	bytesPerSecond := ai.Frequency * float64(ai.NumEnabledChannels()*bytesPerWord)
+	wait := time.Duration(float64(len(p))/bytesPerSecond*float64(time.Second)) +
+		defaultTimeout*time.Millisecond
+	readCtx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	n, err := ai.DAQ.ReadContext(readCtx, p)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return n, fmt.Errorf("no scan data for %s: %w", wait, mccdaq.ErrTimeout)
 	}
 	return n, err
 }
 
+// abortScan stops the scan and clears the scan FIFO buffer after the context
+// is done partway through a read, and then returns the context's error.
+func (ai *AnalogInput) abortScan(ctx context.Context) error {
+	ai.StopScan()
+	ai.ClearScanBuffer()
+	ai.scanDone = true
+	return ctx.Err()
+}
+
 // Close stops the analog input scan if running.
 func (ai *AnalogInput) Close() error {
 	return ai.StopScan()
@@ -326,7 +942,7 @@ func (ai *AnalogInput) Close() error {
 func (ai *AnalogInput) StopScan() error {
 	_, err := ai.DAQ.SendCommandToDevice(commandAnalogStopScan, nil)
 	if err != nil {
-		return fmt.Errorf("Error stopping analog input scan %s", err)
+		return fmt.Errorf("Error stopping analog input scan %w", err)
 	}
 	return nil
 }
@@ -335,12 +951,14 @@ func (ai *AnalogInput) StopScan() error {
 func (ai *AnalogInput) ClearScanBuffer() error {
 	_, err := ai.DAQ.SendCommandToDevice(commandAnalogClearBuffer, nil)
 	if err != nil {
-		return fmt.Errorf("Error clearing analog input scan FIFO buffer %s", err)
+		return fmt.Errorf("Error clearing analog input scan FIFO buffer %w", err)
 	}
 	return nil
 }
 
-// SetScanRanges writes the scan ranges to the USB-1608FS-Plus
+// SetScanRanges writes the scan ranges to the USB-1608FS-Plus. Writing the
+// ranges while a scan is running would stall the bus, so it fails with
+// mccdaq.ErrScanRunning instead.
 func (ai *AnalogInput) SetScanRanges() error {
 	ranges := make([]byte, 8)
 	for i, channel := range ai.Channels {
@@ -351,7 +969,7 @@ func (ai *AnalogInput) SetScanRanges() error {
 	}
 	_, err := ai.DAQ.SendCommandToDevice(commandAnalogConfig, ranges)
 	if err != nil {
-		return fmt.Errorf("Error writing Ain config %s", err)
+		return fmt.Errorf("Error writing Ain config %w", err)
 	}
 	return nil
 }
@@ -363,10 +981,10 @@ func (ai *AnalogInput) ScanRanges() ([]byte, error) {
 	var ranges = make([]byte, bytesInRange)
 	bytesRead, err := ai.DAQ.ReadCommandFromDevice(commandAnalogConfig, ranges)
 	if err != nil {
-		return ranges, fmt.Errorf("Error reading Ain config: %s", err)
+		return ranges, fmt.Errorf("Error reading Ain config: %w", err)
 	}
 	if bytesRead != bytesInRange {
-		return ranges, fmt.Errorf("Wrong number of ranges: %s", err)
+		return ranges, fmt.Errorf("Wrong number of ranges: %d", bytesRead)
 	}
 	return ranges, nil
 }
@@ -399,6 +1017,9 @@ func packScanData(numScans int, frequency float64, channels byte, options byte)
 	}
 }
 
+// pacerClockPeriod is the period of the DAQ's 40 MHz pacer clock.
+const pacerClockPeriod = 25 * time.Nanosecond
+
 func calculatePacerPeriod(frequency float64) int {
 	if frequency > maxFrequency {
 		frequency = maxFrequency
@@ -417,15 +1038,13 @@ func round(f float64) int {
 }
 
 // ReadAnalogInput reads the value of an analog input channel. This command
-// will result in a bus stall if an AInScan is currenty running.
+// would result in a bus stall if an AInScan is currenty running, so it fails
+// with mccdaq.ErrScanRunning instead.
 func (daq *USB1608fsplus) ReadAnalogInput(channel int, rng VoltageRange) (uint, error) {
-	requestType := libusb.BitmapRequestType(
-		libusb.DeviceToHost, libusb.Vendor, libusb.DeviceRecipient)
 	data := make([]byte, 2)
-	_, err := daq.DeviceHandle.ControlTransfer(
-		requestType, byte(commandAnalogInput), uint16(channel), uint16(rng), data, len(data), daq.Timeout)
+	_, err := daq.controlTransferIn(commandAnalogInput, uint16(channel), uint16(rng), data)
 	if err != nil {
-		return 0, fmt.Errorf("Error reading analog input %s", err)
+		return 0, fmt.Errorf("Error reading analog input %w", err)
 	}
 	value := DecodeWord(data)
 	return uint(value), nil
@@ -457,7 +1076,7 @@ func (ai *AnalogInput) ConfigureChannel(
 	// Return error if the voltage range is invalid
 	inputRange, ok := InputRanges[voltage]
 	if !ok {
-		return fmt.Errorf("voltage input range `%s` is invalid", inputRange)
+		return fmt.Errorf("voltage input range `%s` is invalid: %w", voltage, mccdaq.ErrInvalidRange)
 	}
 	return ai.configureChannel(ch, enabled, inputRange, description)
 }
@@ -470,7 +1089,7 @@ func (ai *AnalogInput) configureChannel(
 
 	// Return error if the channel is invalid
 	if ch < 0 || ch >= len(ai.Channels) {
-		return fmt.Errorf("channel %d outside valid range", ch)
+		return fmt.Errorf("channel %d outside valid range: %w", ch, mccdaq.ErrInvalidChannel)
 	}
 
 	// Configure the channel
@@ -481,66 +1100,73 @@ func (ai *AnalogInput) configureChannel(
 	return nil
 }
 
-// RawVoltages converts the given binary data into a 2D slice of float64s. The
-// binary data is two byte raw integer values by channel and then by scan. The
-// 2D slice has dimensions of channel first and then number of scans.
+// RawVoltages converts the given binary scan data into voltages without
+// adjusting for the DAQ's gain and offset. The DAQ only sends the enabled
+// channels, so the binary data is a two byte word for each enabled channel, in
+// channel order, for each scan. The returned 2D slice is indexed first by
+// channel number and then by scan; the slices for disabled channels are nil.
 func (ai *AnalogInput) RawVoltages(data []byte) ([][]float64, error) {
-	// Check that the given data is a multiple of 2 bytes (1 word) by the number
-	// of channels (8).
-	if len(data)%(bytesPerWord*len(ai.Channels)) != 0 {
-		return nil, fmt.Errorf("data len must be multiple of 2 bytes x 8 channels")
-	}
-	scans := len(data) / (bytesPerWord * len(ai.Channels))
-	rawVoltages := make([][]float64, len(ai.Channels))
-	for i := range ai.Channels {
-		rawVoltages[i] = make([]float64, scans)
-	}
-	word := 0
-	for scan := 0; scan < scans; scan++ {
-		for i, ch := range ai.Channels {
-			firstByte := word * bytesPerWord
-			raw, err := RawVoltsFromWord(data[firstByte:firstByte+bytesPerWord], ch.Range)
-			if err != nil {
-				return rawVoltages, err
-			}
-			rawVoltages[i][scan] = raw
-			word++
-		}
-	}
-	return rawVoltages, nil
+	return ai.decodeVoltages(data, false)
 }
 
-// Voltages calculates the actual voltage reading given the raw binary data,
-// which is converted into a 2D slice by channel and scan taking into account
-// the MCC DAQ's gain, offset, and range for each channel.
+// Voltages calculates the actual voltage readings from the given binary scan
+// data taking into account the MCC DAQ's gain, offset, and range for each
+// channel. Like RawVoltages, the binary data only contains the enabled
+// channels, and the returned 2D slice is indexed first by channel number and
+// then by scan with nil slices for the disabled channels.
 func (ai *AnalogInput) Voltages(data []byte) ([][]float64, error) {
-	// Check that the given data is a multiple of 2 bytes (1 word) by the number
-	// of channels (8).
-	if len(data)%(bytesPerWord*len(ai.Channels)) != 0 {
-		return nil, fmt.Errorf("data len must be multiple of 2 bytes x 8 channels")
+	return ai.decodeVoltages(data, true)
+}
+
+// EngineeringValues calculates the calibrated voltages from the given binary
+// scan data like Voltages does, and converts those of each channel with a
+// Scale into its engineering units.
+func (ai *AnalogInput) EngineeringValues(data []byte) ([][]float64, error) {
+	scales, err := ai.Scales()
+	if err != nil {
+		return nil, err
+	}
+	values, err := ai.Voltages(data)
+	if err != nil {
+		return values, err
+	}
+	for ch, sc := range scales {
+		values[ch] = sc.Values(values[ch])
 	}
-	scans := len(data) / (bytesPerWord * len(ai.Channels))
+	return values, nil
+}
+
+// decodeVoltages maps each word of the binary scan data onto the enabled
+// channels and converts it into a voltage, which is adjusted for the gain and
+// offset if calibrated is true.
+func (ai *AnalogInput) decodeVoltages(data []byte, calibrated bool) ([][]float64, error) {
+	channels := ai.enabledChannelNumbers()
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("no channels enabled to decode: %w", mccdaq.ErrInvalidChannel)
+	}
+	scanBytes := bytesPerWord * len(channels)
+	if len(data)%scanBytes != 0 {
+		return nil, fmt.Errorf("data len must be multiple of %d bytes x %d enabled channels",
+			bytesPerWord, len(channels))
+	}
+	scans := len(data) / scanBytes
 	voltages := make([][]float64, len(ai.Channels))
-	for i := range ai.Channels {
-		voltages[i] = make([]float64, scans)
-	}
-	word := 0
-	slopes := make([]float64, len(ai.Channels))
-	offsets := make([]float64, len(ai.Channels))
-	for i, ch := range ai.Channels {
-		slopes[i] = ch.Slopes[ch.Range]
-		offsets[i] = ch.Intercepts[ch.Range]
-	}
-	for scan := 0; scan < scans; scan++ {
-		for i, ch := range ai.Channels {
-			firstByte := word * bytesPerWord
+	for i, ch := range channels {
+		channel := ai.Channels[ch]
+		slope, offset := 1.0, 0.0
+		if calibrated {
+			slope = channel.Slopes[channel.Range]
+			offset = channel.Intercepts[channel.Range]
+		}
+		voltages[ch] = make([]float64, scans)
+		for scan := 0; scan < scans; scan++ {
+			firstByte := (scan*len(channels) + i) * bytesPerWord
 			voltage, err := VoltsFromWord(
-				data[firstByte:firstByte+bytesPerWord], ch.Range, slopes[i], offsets[i])
+				data[firstByte:firstByte+bytesPerWord], channel.Range, slope, offset)
 			if err != nil {
 				return voltages, err
 			}
-			voltages[i][scan] = voltage
-			word++
+			voltages[ch][scan] = voltage
 		}
 	}
 	return voltages, nil
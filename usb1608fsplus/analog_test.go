@@ -6,19 +6,50 @@
 package usb1608fsplus
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"reflect"
 	"testing"
+	"time"
 
+	"github.com/gotmc/mccdaq"
+	"github.com/gotmc/mccdaq/alarm"
+	"github.com/gotmc/mccdaq/bridge"
+	"github.com/gotmc/mccdaq/scale"
+	"github.com/gotmc/mccdaq/sensor"
+	"github.com/gotmc/mccdaq/thermocouple"
 	c "github.com/smartystreets/goconvey/convey"
 )
 
 type FakeDAQer struct {
-	Ranges [8]byte
+	Ranges     [8]byte
+	Commands   []command
+	Data       []byte
+	ReadSizes  []int
+	StatusByte byte
+	// Overruns is the number of times Status reports an overrun before
+	// reporting StatusByte alone.
+	Overruns int
+	Halts    int
+	// Records, if any, are each loaded into Data in turn when a scan starts,
+	// to mimic the data of a triggered scan.
+	Records [][]byte
 }
 
 func (f *FakeDAQer) SendCommandToDevice(cmd command, data []byte) (int, error) {
+	f.Commands = append(f.Commands, cmd)
+	if cmd == commandAnalogStartScan && len(f.Records) > 0 {
+		f.Data, f.Records = f.Records[0], f.Records[1:]
+	}
+	switch cmd {
+	case commandAnalogStartScan, commandAnalogStopScan, commandAnalogClearBuffer:
+		return len(data), nil
+	}
 	if cmd == commandAnalogConfig {
 		if len(data) != len(f.Ranges) {
 			return 0, fmt.Errorf("data is wrong length %d", len(data))
@@ -45,11 +76,33 @@ func (f *FakeDAQer) ReadCommandFromDevice(cmd command, data []byte) (int, error)
 }
 
 func (f *FakeDAQer) Read(p []byte) (n int, err error) {
-	return 0, nil
+	f.ReadSizes = append(f.ReadSizes, len(p))
+	n = copy(p, f.Data)
+	f.Data = f.Data[n:]
+	return n, nil
+}
+
+// ReadContext blocks until the context is done once there's no more data, to
+// mimic a DAQ waiting on a trigger that never arrives.
+func (f *FakeDAQer) ReadContext(ctx context.Context, p []byte) (n int, err error) {
+	if ctx.Done() == nil || len(f.Data) > 0 {
+		return f.Read(p)
+	}
+	<-ctx.Done()
+	return 0, ctx.Err()
 }
 
 func (f *FakeDAQer) Status() (byte, error) {
-	return 0x0, nil
+	if f.Overruns > 0 {
+		f.Overruns--
+		return f.StatusByte | byte(scanOverrun), nil
+	}
+	return f.StatusByte, nil
+}
+
+func (f *FakeDAQer) ClearHalt() error {
+	f.Halts++
+	return nil
 }
 
 func TestSetScanRanges(t *testing.T) {
@@ -79,6 +132,462 @@ func TestSetScanRanges(t *testing.T) {
 	})
 }
 
+func TestReadContextCanceled(t *testing.T) {
+	f := FakeDAQer{}
+	ai := AnalogInput{
+		DAQ:          &f,
+		Frequency:    1000,
+		TransferMode: BlockTransfer,
+	}
+	ai.EnableChannel(0)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	n, err := ai.ReadContext(ctx, make([]byte, 4*maxBulkTransferPacketSize))
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected error %v, got %v", context.DeadlineExceeded, err)
+	}
+	if n != 0 {
+		t.Errorf("Expected 0 bytes read, got %d", n)
+	}
+	want := []command{commandAnalogStopScan, commandAnalogClearBuffer}
+	if !reflect.DeepEqual(f.Commands, want) {
+		t.Errorf("Expected commands %v, got %v", want, f.Commands)
+	}
+}
+
+func TestReadOverrun(t *testing.T) {
+	f := FakeDAQer{
+		Data:       make([]byte, 2*maxBulkTransferPacketSize),
+		StatusByte: byte(scanRunning | scanOverrun),
+	}
+	var buf bytes.Buffer
+	ai := AnalogInput{
+		DAQ:          &f,
+		Frequency:    1000,
+		TransferMode: BlockTransfer,
+		Logger:       mccdaq.NewStdLogger(log.New(&buf, "", 0), mccdaq.LevelWarn),
+		serialNumber: "01AF3FAE",
+	}
+	ai.EnableChannel(0)
+	n, err := ai.Read(make([]byte, 2*maxBulkTransferPacketSize))
+	if !errors.Is(err, mccdaq.ErrOverrun) {
+		t.Fatalf("Expected ErrOverrun, got %v", err)
+	}
+	var overrun *mccdaq.OverrunError
+	if !errors.As(err, &overrun) || overrun.BytesRead != n {
+		t.Errorf("Expected OverrunError with %d bytes read, got %v", n, err)
+	}
+	want := []command{commandAnalogStopScan, commandAnalogClearBuffer}
+	if !reflect.DeepEqual(f.Commands, want) {
+		t.Errorf("Expected commands %v, got %v", want, f.Commands)
+	}
+	if f.Halts != 1 {
+		t.Errorf("Expected bulk endpoint halt cleared once, got %d", f.Halts)
+	}
+	wantLog := "level=WARN msg=\"Analog input scan overrun\" serial_number=01AF3FAE bytes=128 policy=fail\n"
+	if buf.String() != wantLog {
+		t.Errorf("Expected log `%s`, got `%s`", wantLog, buf.String())
+	}
+}
+
+// fakeClock returns a function to use for timeNow that advances by step each
+// time it's called.
+func fakeClock(step time.Duration) func() time.Time {
+	now := time.Unix(0, 0)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func TestReadOverrunRestart(t *testing.T) {
+	defer func() { timeNow = time.Now }()
+	testCases := []struct {
+		policy OverrunPolicy
+		gap    bool
+	}{
+		{OverrunRestart, false},
+		{OverrunRestartWithGaps, true},
+	}
+	for _, tc := range testCases {
+		// The clock is read at the start, when the data arrives, and at the
+		// restart 100 ms after the start, so at 1 kHz, 100 scans are
+		// expected, of which 10 whole 3-channel scans were read.
+		timeNow = fakeClock(50 * time.Millisecond)
+		f := FakeDAQer{Data: newScanData(96), StatusByte: byte(scanRunning), Overruns: 1}
+		ai := AnalogInput{
+			DAQ:           &f,
+			Frequency:     1000,
+			TransferMode:  BlockTransfer,
+			OverrunPolicy: tc.policy,
+			Logger:        mccdaq.NopLogger,
+		}
+		for ch := 0; ch < 3; ch++ {
+			ai.EnableChannel(ch)
+		}
+		if err := ai.StartScan(0); err != nil {
+			t.Fatalf("StartScan: %v", err)
+		}
+		expected := newScanData(96)
+		p := make([]byte, 60)
+		n, err := ai.Read(p)
+		if err != nil || n != 60 || !reflect.DeepEqual(p, expected[:60]) {
+			t.Fatalf("%s: expected the 10 scans before the overrun, got %d bytes and %v",
+				tc.policy, n, err)
+		}
+		if tc.gap {
+			n, err = ai.Read(p)
+			var gap *mccdaq.OverrunError
+			if !errors.As(err, &gap) || !gap.Restarted || n != 0 {
+				t.Fatalf("%s: expected restarted OverrunError, got %d bytes and %v",
+					tc.policy, n, err)
+			}
+			if gap.Scan != 10 || gap.LostScans != 90 {
+				t.Errorf("%s: expected 90 scans lost at scan 10, got %d lost at scan %d",
+					tc.policy, gap.LostScans, gap.Scan)
+			}
+		}
+		// The partial scan at the end of the first packet was dropped, so the
+		// data continues from the second packet.
+		n, err = ai.Read(p)
+		if err != nil || n != 60 || !reflect.DeepEqual(p, expected[64:124]) {
+			t.Errorf("%s: expected the 10 scans after the overrun, got %d bytes and %v",
+				tc.policy, n, err)
+		}
+		if f.Halts != 1 {
+			t.Errorf("%s: expected bulk endpoint halt cleared once, got %d", tc.policy, f.Halts)
+		}
+		want := []command{
+			commandAnalogStopScan, commandAnalogClearBuffer, commandAnalogStartScan,
+			commandAnalogStopScan, commandAnalogClearBuffer,
+			commandAnalogConfig,
+			commandAnalogStopScan, commandAnalogClearBuffer, commandAnalogStartScan,
+		}
+		if !reflect.DeepEqual(f.Commands, want) {
+			t.Errorf("%s: expected commands %v, got %v", tc.policy, want, f.Commands)
+		}
+	}
+}
+
+func TestReadTimebase(t *testing.T) {
+	defer func() { timeNow = time.Now }()
+	timeNow = fakeClock(10 * time.Millisecond)
+	f := FakeDAQer{Data: newScanData(64), StatusByte: byte(scanRunning)}
+	ai := AnalogInput{
+		DAQ:           &f,
+		Frequency:     3000,
+		TransferMode:  BlockTransfer,
+		DriftInterval: time.Nanosecond,
+	}
+	ai.EnableChannel(0)
+	if err := ai.StartScan(0); err != nil {
+		t.Fatalf("StartScan: %v", err)
+	}
+	if _, ok := ai.Timebase(); ok {
+		t.Errorf("Expected no timebase before the first scan data arrives")
+	}
+	// The 3 kHz scan rate gives a pacer period of 13332, or 13333 ticks of the
+	// 40 MHz pacer clock.
+	period := 13333 * 25 * time.Nanosecond
+	p := make([]byte, maxBulkTransferPacketSize)
+	if _, err := ai.Read(p); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	// The first 32 scans arrived at 20 ms, so the first scan was acquired 32
+	// scan periods earlier.
+	tb, ok := ai.Timebase()
+	anchor := time.Unix(0, 0).Add(20*time.Millisecond - 32*period)
+	if !ok || tb.Period != period || !tb.Anchor.Equal(anchor) {
+		t.Errorf("Expected period %v and anchor %v, got %v and %v", period, anchor, tb.Period, tb.Anchor)
+	}
+	// The next 32 scans arrived at 30 ms, before their pacer time.
+	if _, err := ai.Read(p); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	tb, _ = ai.Timebase()
+	expected := mccdaq.Drift{
+		Elapsed: 64 * period,
+		Offset:  30*time.Millisecond - (20*time.Millisecond + 32*period),
+	}
+	if tb.Drift != expected {
+		t.Errorf("Expected drift %v, got %v", expected, tb.Drift)
+	}
+}
+
+func TestOverrunPolicyJSON(t *testing.T) {
+	for policy, s := range OverrunPolicyStrings {
+		b, err := json.Marshal(&policy)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		if string(b) != `"`+s+`"` {
+			t.Errorf("Expected %q, got %s", s, b)
+		}
+		var got OverrunPolicy
+		if err := json.Unmarshal(b, &got); err != nil || got != policy {
+			t.Errorf("Expected %s, got %s and %v", policy, got, err)
+		}
+	}
+	var got OverrunPolicy
+	if err := json.Unmarshal([]byte(`"retry"`), &got); err == nil {
+		t.Errorf("Expected error for invalid overrun policy")
+	}
+}
+
+func TestConfigureChannelErrors(t *testing.T) {
+	testCases := []struct {
+		ch       int
+		voltage  string
+		expected error
+	}{
+		{0, "10V", nil},
+		{8, "10V", mccdaq.ErrInvalidChannel},
+		{-1, "5V", mccdaq.ErrInvalidChannel},
+		{0, "3V", mccdaq.ErrInvalidRange},
+	}
+	for _, tc := range testCases {
+		ai := AnalogInput{}
+		err := ai.ConfigureChannel(tc.ch, true, tc.voltage, "test")
+		if !errors.Is(err, tc.expected) {
+			t.Errorf("Channel %d at %s: expected %v, got %v", tc.ch, tc.voltage, tc.expected, err)
+		}
+	}
+}
+
+func TestDescriptions(t *testing.T) {
+	ai := AnalogInput{}
+	if err := ai.ConfigureEnableChannel(1, "10V", "Pressure"); err != nil {
+		t.Fatalf("ConfigureEnableChannel: %v", err)
+	}
+	if err := ai.ConfigureEnableChannel(6, "5V", "Temperature"); err != nil {
+		t.Fatalf("ConfigureEnableChannel: %v", err)
+	}
+	if err := ai.ConfigureChannel(3, false, "10V", "Unused"); err != nil {
+		t.Fatalf("ConfigureChannel: %v", err)
+	}
+	want := map[int]string{1: "Pressure", 6: "Temperature"}
+	if got := ai.Descriptions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	config := []byte(`{
+		"channels": [
+			{"enabled": true, "range": "10V", "limits": {"high": 4.5, "deadband": 0.1, "latch": true}},
+			{"enabled": true, "range": "10V"},
+			{"enabled": false, "range": "10V", "limits": {"low": 0}}
+		]
+	}`)
+	var ai AnalogInput
+	if err := json.Unmarshal(config, &ai); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	high := 4.5
+	want := map[int]alarm.Limits{0: {High: &high, Deadband: 0.1, Latch: true}}
+	if got := ai.Limits(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestThermocouples(t *testing.T) {
+	config := []byte(`{
+		"channels": [
+			{"enabled": true, "range": "1V", "thermocouple": "K"},
+			{"enabled": true, "range": "1V", "thermocouple": "J", "unit": "degC"},
+			{"enabled": false, "range": "1V", "thermocouple": "T"},
+			{"enabled": true, "range": "10V", "desc": "LM35"}
+		]
+	}`)
+	var ai AnalogInput
+	if err := json.Unmarshal(config, &ai); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	want := map[int]thermocouple.Type{0: thermocouple.K, 1: thermocouple.J}
+	if got := ai.Thermocouples(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	wantUnits := map[int]string{0: "°C", 1: "degC", 3: "V"}
+	if got := ai.Units(); !reflect.DeepEqual(got, wantUnits) {
+		t.Errorf("Expected units %v, got %v", wantUnits, got)
+	}
+	ai.Channels[0].Scale = &scale.Scale{Type: scale.Linear, Slope: 1}
+	if _, err := ai.Scales(); err == nil {
+		t.Error("Expected error for a thermocouple channel with a scale")
+	}
+}
+
+func TestBridges(t *testing.T) {
+	config := []byte(`{
+		"channels": [
+			{"enabled": true, "range": "1V",
+			 "bridge": {"type": "quarter", "gauge_factor": 2, "excitation_channel": 7}},
+			{"enabled": true, "range": "1V", "unit": "lbf",
+			 "bridge": {"type": "full", "rated_output": 2, "capacity": 500, "excitation_channel": 7}},
+			{"enabled": false, "range": "10V"},
+			{"enabled": false, "range": "10V"},
+			{"enabled": false, "range": "10V"},
+			{"enabled": false, "range": "10V"},
+			{"enabled": false, "range": "10V"},
+			{"enabled": true, "range": "10V", "desc": "Excitation"}
+		]
+	}`)
+	var ai AnalogInput
+	if err := json.Unmarshal(config, &ai); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	bridges := ai.Bridges()
+	if len(bridges) != 2 || bridges[0].Type != bridge.QuarterBridge || bridges[1].Capacity != 500 {
+		t.Errorf("Expected quarter bridge and load cell, got %+v", bridges)
+	}
+	wantUnits := map[int]string{0: "µε", 1: "lbf", 7: "V"}
+	if got := ai.Units(); !reflect.DeepEqual(got, wantUnits) {
+		t.Errorf("Expected units %v, got %v", wantUnits, got)
+	}
+	ai.Channels[0].Scale = &scale.Scale{Type: scale.Linear, Slope: 1}
+	if _, err := ai.Scales(); err == nil {
+		t.Error("Expected error for a bridge channel with a scale")
+	}
+}
+
+func TestSensors(t *testing.T) {
+	config := []byte(`{
+		"channels": [
+			{"enabled": true, "range": "5V",
+			 "sensor": {"type": "thermistor", "r0": 10000, "beta": 3950, "excitation": 5, "reference": 10000}},
+			{"enabled": true, "range": "1V",
+			 "sensor": {"type": "rtd", "r0": 100, "circuit": "current_source", "excitation": 0.001}},
+			{"enabled": true, "range": "5V", "unit": "psi",
+			 "sensor": {"type": "current_loop", "shunt": 250, "low": 0, "high": 100}},
+			{"enabled": false, "range": "5V",
+			 "sensor": {"type": "current_loop", "shunt": 250, "low": 0, "high": 100}},
+			{"enabled": false, "range": "10V"},
+			{"enabled": false, "range": "10V"},
+			{"enabled": false, "range": "10V"},
+			{"enabled": false, "range": "10V"}
+		]
+	}`)
+	var ai AnalogInput
+	if err := json.Unmarshal(config, &ai); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	sensors := ai.Sensors()
+	if len(sensors) != 3 || sensors[0].Type != sensor.Thermistor || sensors[1].Type != sensor.RTD ||
+		sensors[2].Shunt != 250 {
+		t.Errorf("Expected a thermistor, RTD, and current loop, got %+v", sensors)
+	}
+	wantUnits := map[int]string{0: "°C", 1: "°C", 2: "psi"}
+	if got := ai.Units(); !reflect.DeepEqual(got, wantUnits) {
+		t.Errorf("Expected units %v, got %v", wantUnits, got)
+	}
+	ai.Channels[2].Scale = &scale.Scale{Type: scale.Linear, Slope: 1}
+	if _, err := ai.Scales(); err == nil {
+		t.Error("Expected error for a sensor channel with a scale")
+	}
+}
+
+func TestStartScanContextCanceled(t *testing.T) {
+	f := FakeDAQer{}
+	ai := AnalogInput{DAQ: &f, Frequency: 1000}
+	ai.EnableChannel(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := ai.StartScanContext(ctx, 0); err != context.Canceled {
+		t.Errorf("Expected error %v, got %v", context.Canceled, err)
+	}
+	if len(f.Commands) != 0 {
+		t.Errorf("Expected no commands sent to the DAQ, got %v", f.Commands)
+	}
+}
+
+func TestTransferSize(t *testing.T) {
+	testCases := []struct {
+		frequency   float64
+		numChannels int
+		externally  bool
+		trigger     TriggerType
+		flowing     bool
+		expected    int
+	}{
+		{1.0, 1, false, NoExternalTrigger, false, 64},
+		{10000.0, 1, false, NoExternalTrigger, false, 960},
+		{10000.0, 8, false, NoExternalTrigger, false, 8000},
+		{100000.0, 8, false, NoExternalTrigger, false, 80000},
+		{100000.0, 8, true, NoExternalTrigger, true, 64},
+		{100000.0, 8, false, RisingEdgeTrigger, false, 64},
+		{100000.0, 8, false, RisingEdgeTrigger, true, 80000},
+	}
+	for _, tc := range testCases {
+		ai := AnalogInput{Frequency: tc.frequency, UseExternalPacer: tc.externally, Trigger: tc.trigger}
+		for i := 0; i < tc.numChannels; i++ {
+			ai.EnableChannel(i)
+		}
+		computed := ai.transferSize(tc.flowing)
+		if computed != tc.expected {
+			t.Errorf("%g Hz with %d channels: expected %d bytes, got %d",
+				tc.frequency, tc.numChannels, tc.expected, computed)
+		}
+	}
+}
+
+// slowDAQer is a FakeDAQer whose bulk endpoint delivers one packet of Data at
+// a time after a delay, polled like a USB1608fsplus.
+type slowDAQer struct {
+	FakeDAQer
+	delay time.Duration
+	sizes []int
+}
+
+func (f *slowDAQer) ReadContext(ctx context.Context, p []byte) (int, error) {
+	f.sizes = append(f.sizes, len(p))
+	if len(p) > maxBulkTransferPacketSize {
+		return 0, fmt.Errorf("%d byte transfer could lose data", len(p))
+	}
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+	return f.Read(p)
+}
+
+func TestReadContextSlowScan(t *testing.T) {
+	testCases := []struct {
+		name        string
+		frequency   float64
+		externally  bool
+		trigger     TriggerType
+		delay       time.Duration
+		expectedErr error
+	}{
+		{"external pacer", 1000, true, NoExternalTrigger, 50 * time.Millisecond, nil},
+		{"awaiting trigger", 1000, false, RisingEdgeTrigger, 50 * time.Millisecond, nil},
+		// At 10 Hz a packet takes 3.2 s, so 1.6 s is on time.
+		{"slow pacer", 10, false, NoExternalTrigger, 1600 * time.Millisecond, nil},
+		{"stalled pacer", 1000, false, NoExternalTrigger, 2500 * time.Millisecond, mccdaq.ErrTimeout},
+	}
+	for _, tc := range testCases {
+		f := slowDAQer{
+			FakeDAQer: FakeDAQer{Data: make([]byte, maxBulkTransferPacketSize)},
+			delay:     tc.delay,
+		}
+		ai := AnalogInput{
+			DAQ:              &f,
+			Frequency:        tc.frequency,
+			TransferMode:     BlockTransfer,
+			UseExternalPacer: tc.externally,
+			Trigger:          tc.trigger,
+		}
+		ai.EnableChannel(0)
+		ctx, cancel := context.WithCancel(context.Background())
+		_, err := ai.ReadContext(ctx, make([]byte, maxBulkTransferPacketSize))
+		cancel()
+		if !errors.Is(err, tc.expectedErr) || (tc.expectedErr == nil && err != nil) {
+			t.Errorf("%s: expected error %v, got %v", tc.name, tc.expectedErr, err)
+		}
+	}
+}
+
 func TestPackScanData(t *testing.T) {
 	testCases := []struct {
 		numScans  int
@@ -405,3 +914,175 @@ func TestChannelMarshalJSON(t *testing.T) {
 // })
 // })
 // }
+
+func TestVoltagesEnabledChannels(t *testing.T) {
+	ai := AnalogInput{}
+	ai.Channels[1] = Channel{
+		Enabled:    true,
+		Range:      Range10V,
+		Slopes:     Slopes{Range10V: 1.0},
+		Intercepts: Intercepts{Range10V: 0.0},
+	}
+	ai.Channels[5] = Channel{
+		Enabled:    true,
+		Range:      Range5V,
+		Slopes:     Slopes{Range5V: 1.0},
+		Intercepts: Intercepts{Range5V: 0.0},
+	}
+	// Two scans of channels 1 and 5.
+	data := []byte{0x00, 0x80, 0x00, 0xc0, 0x00, 0x40, 0x00, 0x80}
+	expected := [][]float64{nil, {0.0, -5.0}, nil, nil, nil, {2.5, 0.0}, nil, nil}
+	decoders := map[string]func([]byte) ([][]float64, error){
+		"RawVoltages": ai.RawVoltages,
+		"Voltages":    ai.Voltages,
+	}
+	for name, decode := range decoders {
+		computed, err := decode(data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(computed, expected) {
+			t.Errorf("%s: expected %v, got %v", name, expected, computed)
+		}
+		if _, err := decode(data[:6]); err == nil {
+			t.Errorf("%s: expected error for partial scan", name)
+		}
+	}
+	if _, err := (&AnalogInput{}).Voltages(data); !errors.Is(err, mccdaq.ErrInvalidChannel) {
+		t.Errorf("Expected ErrInvalidChannel with no channels enabled, got %v", err)
+	}
+}
+
+func TestEngineeringValues(t *testing.T) {
+	config := []byte(`{
+		"channels": [
+			{"enabled": false, "range": "10V"},
+			{"enabled": true, "range": "10V", "unit": "psi",
+			 "scale": {"type": "linear", "slope": 10, "offset": 5}},
+			{"enabled": false, "range": "10V"},
+			{"enabled": false, "range": "10V"},
+			{"enabled": false, "range": "10V"},
+			{"enabled": true, "range": "5V"}
+		]
+	}`)
+	var ai AnalogInput
+	if err := json.Unmarshal(config, &ai); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	for _, ch := range []int{1, 5} {
+		ai.Channels[ch].Slopes = Slopes{ai.Channels[ch].Range: 1.0}
+		ai.Channels[ch].Intercepts = Intercepts{ai.Channels[ch].Range: 0.0}
+	}
+	// Two scans of channels 1 and 5.
+	data := []byte{0x00, 0x80, 0x00, 0xc0, 0x00, 0x40, 0x00, 0x80}
+	expected := [][]float64{nil, {5.0, -45.0}, nil, nil, nil, {2.5, 0.0}, nil, nil}
+	computed, err := ai.EngineeringValues(data)
+	if err != nil {
+		t.Fatalf("EngineeringValues: %v", err)
+	}
+	if !reflect.DeepEqual(computed, expected) {
+		t.Errorf("Expected %v, got %v", expected, computed)
+	}
+	wantUnits := map[int]string{1: "psi", 5: "V"}
+	if got := ai.Units(); !reflect.DeepEqual(got, wantUnits) {
+		t.Errorf("Expected units %v, got %v", wantUnits, got)
+	}
+	ai.Channels[1].Scale.Slope = 0
+	if _, err := ai.EngineeringValues(data); err == nil {
+		t.Error("Expected error for an invalid scale")
+	}
+	bad := []byte(`{"channels": [{"enabled": true, "range": "10V", "scale": {"type": "table"}}]}`)
+	if err := json.Unmarshal(bad, &ai); err == nil {
+		t.Error("Expected error for a table scale without points")
+	}
+}
+
+// newScanData returns the given number of words of scan data, where each word
+// is its index.
+func newScanData(words int) []byte {
+	var data []byte
+	for word := 0; word < words; word++ {
+		data = append(data, EncodeWord(uint16(word))...)
+	}
+	return data
+}
+
+func TestReadArbitrarySize(t *testing.T) {
+	// Ten scans of five channels.
+	f := FakeDAQer{Data: newScanData(50), StatusByte: byte(scanRunning)}
+	ai := AnalogInput{DAQ: &f, Frequency: 1000, TransferMode: BlockTransfer}
+	for ch := 0; ch < 5; ch++ {
+		ai.EnableChannel(ch)
+	}
+	if err := ai.StartScan(0); err != nil {
+		t.Fatalf("StartScan: %v", err)
+	}
+	expected := newScanData(50)
+	read := 0
+	for i := 0; i < 3; i++ {
+		// A 32-byte buffer only holds three whole 10-byte scans.
+		p := make([]byte, 32)
+		n, err := ai.Read(p)
+		if err != nil || n != 30 {
+			t.Fatalf("Read %d: expected 30 bytes, got %d and %v", i, n, err)
+		}
+		if !reflect.DeepEqual(p[:n], expected[read:read+n]) {
+			t.Errorf("Read %d: expected %v, got %v", i, expected[read:read+n], p[:n])
+		}
+		read += n
+	}
+	if _, err := ai.Read(make([]byte, 8)); !errors.Is(err, io.ErrShortBuffer) {
+		t.Errorf("Expected io.ErrShortBuffer for buffer smaller than a scan, got %v", err)
+	}
+}
+
+func TestReadFiniteScan(t *testing.T) {
+	testCases := []struct {
+		numScans    int
+		numChannels int
+		bufferSize  int
+		reads       []int
+		readSizes   []int
+	}{
+		// 70 bytes end with a short 6-byte packet.
+		{7, 5, 30, []int{30, 30, 10}, []int{64, 64}},
+		// 64 bytes end with a zero-length packet.
+		{32, 1, 64, []int{64}, []int{64, 64}},
+		{32, 1, 1000, []int{64}, []int{64, 64}},
+	}
+	for _, tc := range testCases {
+		words := tc.numScans * tc.numChannels
+		f := FakeDAQer{Data: newScanData(words)}
+		ai := AnalogInput{DAQ: &f, Frequency: 1000, TransferMode: BlockTransfer}
+		for ch := 0; ch < tc.numChannels; ch++ {
+			ai.EnableChannel(ch)
+		}
+		if err := ai.StartScan(tc.numScans); err != nil {
+			t.Fatalf("StartScan: %v", err)
+		}
+		var reads []int
+		var data []byte
+		for {
+			p := make([]byte, tc.bufferSize)
+			n, err := ai.Read(p)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%d scans: unexpected error %v", tc.numScans, err)
+			}
+			reads = append(reads, n)
+			data = append(data, p[:n]...)
+		}
+		if !reflect.DeepEqual(reads, tc.reads) {
+			t.Errorf("%d scans: expected reads of %v bytes, got %v", tc.numScans, tc.reads, reads)
+		}
+		if !reflect.DeepEqual(data, newScanData(words)) {
+			t.Errorf("%d scans: data doesn't match", tc.numScans)
+		}
+		if !reflect.DeepEqual(f.ReadSizes, tc.readSizes) {
+			t.Errorf("%d scans: expected bulk reads of %v bytes, got %v",
+				tc.numScans, tc.readSizes, f.ReadSizes)
+		}
+	}
+}
@@ -179,6 +179,70 @@ func (t *TriggerType) MarshalJSON() ([]byte, error) {
 	return json.Marshal(TriggerTypeStrings[*t])
 }
 
+// OverrunPolicy determines what an AnalogInput does when its scan overruns the
+// DAQ's FIFO buffer.
+type OverrunPolicy byte
+
+// Available overrun policies.
+const (
+	// OverrunFail ends the scan, and Read returns a *mccdaq.OverrunError.
+	OverrunFail OverrunPolicy = iota
+	// OverrunRestart restarts the scan and keeps reading. The gap in the data
+	// is only logged.
+	OverrunRestart
+	// OverrunRestartWithGaps restarts the scan, and Read marks the gap in the
+	// data by returning the scans read before the gap along with a
+	// *mccdaq.OverrunError whose Restarted field is true. The next Read
+	// continues with the data after the gap.
+	OverrunRestartWithGaps
+)
+
+// OverrunPolicies maps a string to the actual OverrunPolicy.
+var OverrunPolicies = map[string]OverrunPolicy{
+	"fail":              OverrunFail,
+	"restart":           OverrunRestart,
+	"restart_with_gaps": OverrunRestartWithGaps,
+}
+
+// OverrunPolicyStrings maps an OverrunPolicy to a string representation for
+// use by Stringer.
+var OverrunPolicyStrings = map[OverrunPolicy]string{
+	OverrunFail:            "fail",
+	OverrunRestart:         "restart",
+	OverrunRestartWithGaps: "restart_with_gaps",
+}
+
+// String implements the Stringer interface for OverrunPolicy.
+func (p OverrunPolicy) String() string {
+	return OverrunPolicyStrings[p]
+}
+
+// UnmarshalJSON implements the Unmarshaler interface for OverrunPolicy by
+// taking a string that matches a key in the OverrunPolicies map and finding
+// the appropriate OverrunPolicy value.
+func (p *OverrunPolicy) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("overrun policy should be a string, got %s", data)
+	}
+	return p.Set(s)
+}
+
+// Set sets the overrun policy using a string.
+func (p *OverrunPolicy) Set(s string) error {
+	got, ok := OverrunPolicies[s]
+	if !ok {
+		return fmt.Errorf("invalid string %q for OverrunPolicy", s)
+	}
+	*p = got
+	return nil
+}
+
+// MarshalJSON implements the Marshaler interface for OverrunPolicy.
+func (p *OverrunPolicy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(OverrunPolicyStrings[*p])
+}
+
 type analogInputSetup byte
 
 // Analog input setup
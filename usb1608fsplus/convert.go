@@ -0,0 +1,183 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package usb1608fsplus
+
+import (
+	"fmt"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// numCodes is the number of distinct 16-bit ADC codes.
+const numCodes = 1 << 16
+
+// voltageTable holds the voltage for every ADC code of a channel, computed
+// exactly as VoltsFromWord does for the range, slope, and offset it was built
+// for. The float64 and float32 tables are each built when first needed.
+type voltageTable struct {
+	rng    VoltageRange
+	slope  float64
+	offset float64
+	f64    *[numCodes]float64
+	f32    *[numCodes]float32
+}
+
+// VoltagesInto converts the binary scan data into voltages like Voltages, but
+// stores them in dst, reusing the slice for each channel if it has enough
+// capacity, and returns the updated dst. dst is indexed by channel number, and
+// the slices for disabled channels are emptied. Each sample is converted by
+// looking up its ADC code in a table of voltages built for the channel's range
+// and calibration the first time it's needed, so once dst has grown large
+// enough, VoltagesInto doesn't allocate.
+func (ai *AnalogInput) VoltagesInto(dst [][]float64, data []byte) ([][]float64, error) {
+	return ai.decodeVoltagesInto(dst, data, true)
+}
+
+// RawVoltagesInto converts the binary scan data into voltages like
+// RawVoltages, but stores them in dst like VoltagesInto.
+func (ai *AnalogInput) RawVoltagesInto(dst [][]float64, data []byte) ([][]float64, error) {
+	return ai.decodeVoltagesInto(dst, data, false)
+}
+
+// Voltages32Into converts the binary scan data into float32 voltages like
+// VoltagesInto.
+func (ai *AnalogInput) Voltages32Into(dst [][]float32, data []byte) ([][]float32, error) {
+	return ai.decodeVoltages32Into(dst, data, true)
+}
+
+// RawVoltages32Into converts the binary scan data into float32 voltages like
+// RawVoltagesInto.
+func (ai *AnalogInput) RawVoltages32Into(dst [][]float32, data []byte) ([][]float32, error) {
+	return ai.decodeVoltages32Into(dst, data, false)
+}
+
+func (ai *AnalogInput) decodeVoltagesInto(
+	dst [][]float64, data []byte, calibrated bool,
+) ([][]float64, error) {
+	numEnabled, scans, err := ai.scanCount(data)
+	if err != nil {
+		return dst, err
+	}
+	if len(dst) < len(ai.Channels) {
+		dst = append(dst, make([][]float64, len(ai.Channels)-len(dst))...)
+	}
+	scanBytes := numEnabled * bytesPerWord
+	i := 0
+	for ch := range ai.Channels {
+		if !ai.Channels[ch].Enabled {
+			dst[ch] = dst[ch][:0]
+			continue
+		}
+		if cap(dst[ch]) < scans {
+			dst[ch] = make([]float64, scans)
+		}
+		out := dst[ch][:scans]
+		table := ai.voltageTable64(ch, calibrated)
+		for scan, b := 0, i*bytesPerWord; scan < scans; scan, b = scan+1, b+scanBytes {
+			out[scan] = table[uint16(data[b])|uint16(data[b+1])<<8]
+		}
+		dst[ch] = out
+		i++
+	}
+	return dst, nil
+}
+
+func (ai *AnalogInput) decodeVoltages32Into(
+	dst [][]float32, data []byte, calibrated bool,
+) ([][]float32, error) {
+	numEnabled, scans, err := ai.scanCount(data)
+	if err != nil {
+		return dst, err
+	}
+	if len(dst) < len(ai.Channels) {
+		dst = append(dst, make([][]float32, len(ai.Channels)-len(dst))...)
+	}
+	scanBytes := numEnabled * bytesPerWord
+	i := 0
+	for ch := range ai.Channels {
+		if !ai.Channels[ch].Enabled {
+			dst[ch] = dst[ch][:0]
+			continue
+		}
+		if cap(dst[ch]) < scans {
+			dst[ch] = make([]float32, scans)
+		}
+		out := dst[ch][:scans]
+		table := ai.voltageTable32(ch, calibrated)
+		for scan, b := 0, i*bytesPerWord; scan < scans; scan, b = scan+1, b+scanBytes {
+			out[scan] = table[uint16(data[b])|uint16(data[b+1])<<8]
+		}
+		dst[ch] = out
+		i++
+	}
+	return dst, nil
+}
+
+// scanCount returns the number of enabled channels and the number of whole
+// scans in the binary scan data, which must not hold a partial scan.
+func (ai *AnalogInput) scanCount(data []byte) (numEnabled, scans int, err error) {
+	numEnabled = ai.NumEnabledChannels()
+	if numEnabled == 0 {
+		return 0, 0, fmt.Errorf("no channels enabled to decode: %w", mccdaq.ErrInvalidChannel)
+	}
+	scanBytes := bytesPerWord * numEnabled
+	if len(data)%scanBytes != 0 {
+		return 0, 0, fmt.Errorf("data len must be multiple of %d bytes x %d enabled channels",
+			bytesPerWord, numEnabled)
+	}
+	return numEnabled, len(data) / scanBytes, nil
+}
+
+// voltageTable64 returns the float64 voltage table for the channel's current
+// range and, if calibrated is true, its slope and offset for that range.
+func (ai *AnalogInput) voltageTable64(ch int, calibrated bool) *[numCodes]float64 {
+	ai.tableMu.Lock()
+	defer ai.tableMu.Unlock()
+	table := ai.voltageTable(ch, calibrated)
+	if table.f64 == nil {
+		table.f64 = new([numCodes]float64)
+		for code := range table.f64 {
+			table.f64[code] = Volts(adjustRawValue(uint16(code), table.slope, table.offset), table.rng)
+		}
+	}
+	return table.f64
+}
+
+// voltageTable32 returns the float32 voltage table like voltageTable64.
+func (ai *AnalogInput) voltageTable32(ch int, calibrated bool) *[numCodes]float32 {
+	ai.tableMu.Lock()
+	defer ai.tableMu.Unlock()
+	table := ai.voltageTable(ch, calibrated)
+	if table.f32 == nil {
+		table.f32 = new([numCodes]float32)
+		for code := range table.f32 {
+			table.f32[code] = float32(
+				Volts(adjustRawValue(uint16(code), table.slope, table.offset), table.rng))
+		}
+	}
+	return table.f32
+}
+
+// voltageTable returns the cached voltage tables for the channel, discarding
+// them if the channel's range or calibration has changed since they were
+// built. The caller must hold tableMu.
+func (ai *AnalogInput) voltageTable(ch int, calibrated bool) *voltageTable {
+	channel := ai.Channels[ch]
+	slope, offset := 1.0, 0.0
+	if calibrated {
+		slope = channel.Slopes[channel.Range]
+		offset = channel.Intercepts[channel.Range]
+	}
+	kind := 0
+	if calibrated {
+		kind = 1
+	}
+	table := &ai.tables[kind][ch]
+	if table.rng != channel.Range || table.slope != slope || table.offset != offset {
+		*table = voltageTable{rng: channel.Range, slope: slope, offset: offset}
+	}
+	return table
+}
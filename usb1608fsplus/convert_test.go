@@ -0,0 +1,182 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package usb1608fsplus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// newConvertTestInput returns an analog input with channels 0, 3, and 6
+// enabled, each with its own range and calibration.
+func newConvertTestInput() *AnalogInput {
+	ai := AnalogInput{}
+	ai.Channels[0] = Channel{
+		Enabled:    true,
+		Range:      Range10V,
+		Slopes:     Slopes{Range10V: 1.0012},
+		Intercepts: Intercepts{Range10V: -7.5},
+	}
+	ai.Channels[3] = Channel{
+		Enabled:    true,
+		Range:      Range5V,
+		Slopes:     Slopes{Range5V: 0.9987},
+		Intercepts: Intercepts{Range5V: 3.25},
+	}
+	ai.Channels[6] = Channel{
+		Enabled:    true,
+		Range:      Range1V,
+		Slopes:     Slopes{Range1V: 1.0},
+		Intercepts: Intercepts{Range1V: 0.0},
+	}
+	return &ai
+}
+
+// allCodesData returns scan data for three channels that covers every ADC
+// code on each channel.
+func allCodesData() []byte {
+	data := make([]byte, 0, numCodes*3*bytesPerWord)
+	for code := 0; code < numCodes; code++ {
+		for ch := 0; ch < 3; ch++ {
+			data = append(data, EncodeWord(uint16(code+ch*1000))...)
+		}
+	}
+	return data
+}
+
+func TestVoltagesIntoMatchesVoltages(t *testing.T) {
+	ai := newConvertTestInput()
+	data := allCodesData()
+	decoders := []struct {
+		name   string
+		decode func([]byte) ([][]float64, error)
+		into   func([][]float64, []byte) ([][]float64, error)
+		into32 func([][]float32, []byte) ([][]float32, error)
+	}{
+		{"Voltages", ai.Voltages, ai.VoltagesInto, ai.Voltages32Into},
+		{"RawVoltages", ai.RawVoltages, ai.RawVoltagesInto, ai.RawVoltages32Into},
+	}
+	for _, d := range decoders {
+		expected, err := d.decode(data)
+		if err != nil {
+			t.Fatalf("%s: %v", d.name, err)
+		}
+		computed, err := d.into(nil, data)
+		if err != nil {
+			t.Fatalf("%s into: %v", d.name, err)
+		}
+		computed32, err := d.into32(nil, data)
+		if err != nil {
+			t.Fatalf("%s float32 into: %v", d.name, err)
+		}
+		for ch := range expected {
+			if len(computed[ch]) != len(expected[ch]) || len(computed32[ch]) != len(expected[ch]) {
+				t.Fatalf("%s channel %d: expected %d scans, got %d and %d", d.name, ch,
+					len(expected[ch]), len(computed[ch]), len(computed32[ch]))
+			}
+			for scan := range expected[ch] {
+				if computed[ch][scan] != expected[ch][scan] ||
+					computed32[ch][scan] != float32(expected[ch][scan]) {
+					t.Fatalf("%s channel %d scan %d: expected %v, got %v and %v", d.name, ch, scan,
+						expected[ch][scan], computed[ch][scan], computed32[ch][scan])
+				}
+			}
+		}
+	}
+}
+
+func TestVoltagesIntoReusesBuffers(t *testing.T) {
+	ai := newConvertTestInput()
+	data := allCodesData()[:300*3*bytesPerWord]
+	dst, err := ai.VoltagesInto(nil, data)
+	if err != nil {
+		t.Fatalf("VoltagesInto: %v", err)
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		dst, _ = ai.VoltagesInto(dst, data[:100*3*bytesPerWord])
+	})
+	if allocs != 0 {
+		t.Errorf("Expected no allocations, got %v", allocs)
+	}
+	if len(dst[0]) != 100 || len(dst[1]) != 0 {
+		t.Errorf("Expected 100 scans on channel 0 and none on channel 1, got %d and %d",
+			len(dst[0]), len(dst[1]))
+	}
+	// Changing the range rebuilds the table.
+	ai.Channels[6].Range = Range2V
+	ai.Channels[6].Slopes[Range2V] = 1.0
+	ai.Channels[6].Intercepts[Range2V] = 0.0
+	dst, err = ai.VoltagesInto(dst, []byte{0, 0x80, 0, 0x80, 0, 0})
+	if err != nil || dst[6][0] != -2.0 {
+		t.Errorf("Expected -2 V on the 2 V range, got %v and %v", dst[6], err)
+	}
+}
+
+func TestVoltagesIntoErrors(t *testing.T) {
+	ai := newConvertTestInput()
+	if _, err := ai.VoltagesInto(nil, make([]byte, 4)); err == nil {
+		t.Errorf("Expected error for partial scan")
+	}
+	if _, err := (&AnalogInput{}).Voltages32Into(nil, make([]byte, 6)); !errors.Is(err, mccdaq.ErrInvalidChannel) {
+		t.Errorf("Expected ErrInvalidChannel with no channels enabled, got %v", err)
+	}
+}
+
+// benchmarkData returns a tenth of a second of eight-channel scan data at
+// 62.5 kHz, the fastest continuous rate for eight channels.
+func benchmarkData() (*AnalogInput, []byte) {
+	ai := AnalogInput{}
+	for ch := range ai.Channels {
+		ai.Channels[ch] = Channel{
+			Enabled:    true,
+			Range:      Range10V,
+			Slopes:     Slopes{Range10V: 1.0012},
+			Intercepts: Intercepts{Range10V: -7.5},
+		}
+	}
+	return &ai, newScanData(6250 * numChannels)
+}
+
+func BenchmarkVoltages(b *testing.B) {
+	ai, data := benchmarkData()
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ai.Voltages(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVoltagesInto(b *testing.B) {
+	ai, data := benchmarkData()
+	dst, _ := ai.VoltagesInto(nil, data)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var err error
+		if dst, err = ai.VoltagesInto(dst, data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVoltages32Into(b *testing.B) {
+	ai, data := benchmarkData()
+	dst, _ := ai.Voltages32Into(nil, data)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var err error
+		if dst, err = ai.Voltages32Into(dst, data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -6,11 +6,16 @@
 package usb1608fsplus
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gotmc/libusb"
+	"github.com/gotmc/mccdaq"
+	"github.com/gotmc/mccdaq/internal/usberr"
 )
 
 const (
@@ -18,6 +23,10 @@ const (
 	productID      = 0x00ea
 	defaultTimeout = 2000
 	msSleepTime    = 500
+	// contextPollTimeout is the longest, in milliseconds, that a bulk transfer
+	// polling for a single packet blocks when reading with a cancelable
+	// context.
+	contextPollTimeout = 100
 )
 
 // DAQer defines the interface required for a DAQ.
@@ -25,10 +34,15 @@ type DAQer interface {
 	SendCommandToDevice(cmd command, data []byte) (int, error)
 	ReadCommandFromDevice(cmd command, data []byte) (int, error)
 	Read(p []byte) (n int, err error)
+	ReadContext(ctx context.Context, p []byte) (n int, err error)
 	Status() (byte, error)
+	ClearHalt() error
 }
 
-// USB1608fsplus models the USB-1608FS-Plus DAQ.
+// USB1608fsplus models the USB-1608FS-Plus DAQ. Its methods are safe for
+// concurrent use: control transfers are serialized, and only one goroutine at a
+// time may read from the bulk endpoint. While an analog input scan is running,
+// commands that would stall the bus fail with mccdaq.ErrScanRunning.
 type USB1608fsplus struct {
 	Timeout          int
 	Device           *libusb.Device
@@ -36,6 +50,58 @@ type USB1608fsplus struct {
 	DeviceHandle     *libusb.DeviceHandle
 	ConfigDescriptor *libusb.ConfigDescriptor
 	BulkEndpoint     *libusb.EndpointDescriptor
+	Logger           mccdaq.Logger
+	serialNumber     string
+	// handle, if set, is used instead of DeviceHandle to talk to the DAQ.
+	handle usbHandle
+	// controlMu serializes control transfers and guards scanning.
+	controlMu sync.Mutex
+	// scanning is set while the DAQ is believed to be running an analog input
+	// scan, based on the commands sent and the last status read.
+	scanning bool
+	// bulkOwned is nonzero while a goroutine is reading the bulk endpoint.
+	bulkOwned int32
+	// bulkMu is held throughout a read of the bulk endpoint, so that closing
+	// the DAQ can wait for the read to finish.
+	bulkMu sync.Mutex
+	// closed is nonzero once Close has been called, after which commands and
+	// reads fail with mccdaq.ErrClosed.
+	closed int32
+}
+
+// Option configures a USB1608fsplus when it is created.
+type Option func(*USB1608fsplus)
+
+// WithLogger sets the Logger used by the USB1608fsplus and any AnalogInput
+// created from it. By default, events at mccdaq.LevelInfo and above are
+// logged using the log package's standard logger. Use mccdaq.NopLogger to
+// disable logging.
+func WithLogger(logger mccdaq.Logger) Option {
+	return func(daq *USB1608fsplus) {
+		daq.Logger = logger
+	}
+}
+
+// defaultLogger is used when a USB1608fsplus or AnalogInput doesn't have a
+// Logger.
+var defaultLogger mccdaq.Logger = &mccdaq.StdLogger{}
+
+// logger returns the Logger for the DAQ or the default logger if none is set.
+func (daq *USB1608fsplus) logger() mccdaq.Logger {
+	if daq.Logger == nil {
+		return defaultLogger
+	}
+	return daq.Logger
+}
+
+// newDevice returns a USB1608fsplus with the default settings and the given
+// options applied.
+func newDevice(opts []Option) *USB1608fsplus {
+	daq := USB1608fsplus{Timeout: defaultTimeout}
+	for _, opt := range opts {
+		opt(&daq)
+	}
+	return &daq
 }
 
 // Init intializes a new libusb session/context by creating a new Context and
@@ -46,17 +112,28 @@ func Init() (*libusb.Context, error) {
 
 // NewViaSN creates a new daq instance by searching through the list of USB
 // devices for the given serial number.
-func NewViaSN(ctx *libusb.Context, sn string) (*USB1608fsplus, error) {
-	var daq USB1608fsplus
-	usbDevices, err := ctx.GetDeviceList()
+func NewViaSN(ctx *libusb.Context, sn string, opts ...Option) (*USB1608fsplus, error) {
+	return NewViaSNContext(context.Background(), ctx, sn, opts...)
+}
+
+// NewViaSNContext creates a new daq instance like NewViaSN, but stops
+// searching through the list of USB devices once the context is done.
+func NewViaSNContext(
+	ctx context.Context, usbCtx *libusb.Context, sn string, opts ...Option,
+) (*USB1608fsplus, error) {
+	daq := newDevice(opts)
+	usbDevices, err := usbCtx.GetDeviceList()
 	if err != nil {
-		return &daq, fmt.Errorf("Error getting USB device list: %s", err)
+		return daq, fmt.Errorf("Error getting USB device list: %w", err)
 	}
 	// Search through the USB devices looking for serial number
 	for _, usbDevice := range usbDevices {
+		if err := ctx.Err(); err != nil {
+			return daq, err
+		}
 		usbDeviceDescriptor, err := usbDevice.GetDeviceDescriptor()
 		if err != nil {
-			return &daq, fmt.Errorf("Error getting device descriptor: %s", err)
+			return daq, fmt.Errorf("Error getting device descriptor: %w", err)
 		}
 		// Check the VendorID and Product ID. If those don't equate to MCC and
 		// USB-1608FS-Plus, then there's no reason to open the device and read its
@@ -66,84 +143,150 @@ func NewViaSN(ctx *libusb.Context, sn string) (*USB1608fsplus, error) {
 			// Found a USB-1608FS-Plus
 			usbDeviceHandle, err := usbDevice.Open()
 			if err != nil {
-				return &daq, fmt.Errorf("Error getting device handle: %s", err)
+				return daq, fmt.Errorf("Error getting device handle: %w", usberr.Wrap(err))
 			}
 			serialNum, err := usbDeviceHandle.GetStringDescriptorASCII(
 				usbDeviceDescriptor.SerialNumberIndex)
 			if err != nil {
-				return &daq, fmt.Errorf("Error reading S/N: %s", err)
+				return daq, fmt.Errorf("Error reading S/N: %w", usberr.Wrap(err))
 			}
 			if serialNum == sn {
-				log.Printf("Found S/N %s. Creating device", sn)
-				return create(usbDevice, usbDeviceHandle)
+				daq.logger().Info("Found S/N. Creating device", "serial_number", sn)
+				return create(daq, usbDevice, usbDeviceHandle)
 			}
 			usbDeviceHandle.Close()
 		}
 	}
 	// Close the list of devices
-	return &daq, fmt.Errorf("couldn't find daq %s", sn)
+	return daq, fmt.Errorf("couldn't find daq %s: %w", sn, mccdaq.ErrDeviceNotFound)
 }
 
 // GetFirstDevice creates a new instance of a daq using the first
-// USB-1608FS-Plus found in the USB context.
-func GetFirstDevice(ctx *libusb.Context) (*USB1608fsplus, error) {
-	var daq USB1608fsplus
-	dev, dh, err := ctx.OpenDeviceWithVendorProduct(vendorID, productID)
+// USB-1608FS-Plus found in the USB context. The error matches
+// mccdaq.ErrDeviceNotFound only if there's no USB-1608FS-Plus; if one is
+// found but can't be opened, the error wraps the cause, such as the
+// libusb.ErrorCode for access being denied.
+func GetFirstDevice(ctx *libusb.Context, opts ...Option) (*USB1608fsplus, error) {
+	daq := newDevice(opts)
+	usbDevices, err := ctx.GetDeviceList()
 	if err != nil {
-		return &daq, fmt.Errorf("error opening the daq, %s", err)
+		return daq, fmt.Errorf("Error getting USB device list: %w", usberr.Wrap(err))
+	}
+	for _, usbDevice := range usbDevices {
+		usbDeviceDescriptor, err := usbDevice.GetDeviceDescriptor()
+		if err != nil {
+			return daq, fmt.Errorf("Error getting device descriptor: %w", usberr.Wrap(err))
+		}
+		if usbDeviceDescriptor.VendorID != vendorID || usbDeviceDescriptor.ProductID != productID {
+			continue
+		}
+		// Failing to open the first USB-1608FS-Plus, such as for lack of
+		// permission, isn't the same as not finding one.
+		usbDeviceHandle, err := usbDevice.Open()
+		if err != nil {
+			return daq, fmt.Errorf("error opening the daq: %w", usberr.Wrap(err))
+		}
+		return create(daq, usbDevice, usbDeviceHandle)
 	}
-	return create(dev, dh)
+	return daq, fmt.Errorf("couldn't find a USB-1608FS-Plus: %w", mccdaq.ErrDeviceNotFound)
 }
 
-func create(dev *libusb.Device, dh *libusb.DeviceHandle) (*USB1608fsplus, error) {
-	var daq USB1608fsplus
+func create(
+	daq *USB1608fsplus, dev *libusb.Device, dh *libusb.DeviceHandle,
+) (*USB1608fsplus, error) {
 	err := dh.ClaimInterface(0)
 	if err != nil {
-		return &daq, fmt.Errorf("Error claiming the bulk interface %s", err)
+		return daq, fmt.Errorf("Error claiming the bulk interface %w", usberr.Wrap(err))
 	}
-	daq.Timeout = defaultTimeout
 	daq.Device = dev
 	daq.DeviceHandle = dh
 	deviceDescriptor, err := daq.Device.GetDeviceDescriptor()
 	if err != nil {
-		return &daq, fmt.Errorf("Error getting device descriptor %s", err)
+		return daq, fmt.Errorf("Error getting device descriptor %w", err)
 	}
 	daq.DeviceDescriptor = deviceDescriptor
+	// The serial number is only used to identify the DAQ in log events, so
+	// failing to read it isn't fatal.
+	if sn, err := dh.GetStringDescriptorASCII(deviceDescriptor.SerialNumberIndex); err == nil {
+		daq.serialNumber = sn
+	}
 	configDescriptor, err := daq.Device.GetActiveConfigDescriptor()
 	if err != nil {
-		return &daq, fmt.Errorf("Error getting active config descriptor. %s", err)
+		return daq, fmt.Errorf("Error getting active config descriptor. %w", err)
 	}
 	daq.ConfigDescriptor = configDescriptor
 	firstDescriptor := configDescriptor.SupportedInterfaces[0].InterfaceDescriptors[0]
 	daq.BulkEndpoint = firstDescriptor.EndpointDescriptors[0]
-	return &daq, nil
+	daq.logger().Debug("Claimed interface", "serial_number", daq.serialNumber)
+	return daq, nil
 }
 
 // Close implements the Closer interface for USB1608fsplus
 func (daq *USB1608fsplus) Close() error {
+	return daq.CloseContext(context.Background())
+}
+
+// CloseContext closes the USB1608fsplus like Close, but stops waiting for the
+// device to settle once the context is done. Commands and reads fail with
+// mccdaq.ErrClosed once Close has been called, and Close waits for a read in
+// progress to return, which takes at most daq.Timeout, and for any command in
+// progress before releasing the interface and closing the device handle. The
+// device handle is always closed, even if the context is done before the
+// device is reset.
+func (daq *USB1608fsplus) CloseContext(ctx context.Context) error {
+	if err := daq.shutdown(); err != nil {
+		return err
+	}
+	defer daq.controlMu.Unlock()
+	defer daq.usb().close()
+	daq.logger().Debug("Closing device", "serial_number", daq.serialNumber)
 	// Release the interface and close up shop
-	err := daq.DeviceHandle.ReleaseInterface(0)
+	err := daq.usb().releaseInterface()
 	if err != nil {
-		return fmt.Errorf("Error releasing interface %s", err)
+		return fmt.Errorf("Error releasing interface %w", usberr.Wrap(err))
+	}
+	if err := sleep(ctx, msSleepTime*time.Millisecond); err != nil {
+		return err
 	}
-	time.Sleep(msSleepTime * time.Millisecond)
-	_, err = daq.Reset()
+	_, err = daq.usb().controlTransfer(false, byte(commandReset), 0x0, 0x0, []byte{0x00}, daq.Timeout)
 	if err != nil {
-		return fmt.Errorf("Error reseting USB-1608FS-Plus %s", err)
+		return fmt.Errorf("Error reseting USB-1608FS-Plus %w", usberr.Wrap(err))
+	}
+	daq.scanning = false
+	return sleep(ctx, msSleepTime*time.Millisecond)
+}
+
+// shutdown marks the DAQ closed, waits for any read in progress to finish so
+// that no transfer is left running on the closed handle, and locks controlMu,
+// which the caller must unlock. A read that starts later sees that the DAQ is
+// closed once it holds bulkMu, before it submits a transfer.
+func (daq *USB1608fsplus) shutdown() error {
+	if !atomic.CompareAndSwapInt32(&daq.closed, 0, 1) {
+		return fmt.Errorf("can't close the daq twice: %w", mccdaq.ErrClosed)
 	}
-	time.Sleep(msSleepTime * time.Millisecond)
-	daq.DeviceHandle.Close()
+	daq.bulkMu.Lock()
+	daq.bulkMu.Unlock()
+	daq.controlMu.Lock()
 	return nil
 }
 
+// abandon closes the handle of a DAQ that disconnected, without releasing the
+// interface or resetting the DAQ, which would only fail. It does nothing if
+// the DAQ is already closed.
+func (daq *USB1608fsplus) abandon() {
+	if daq.shutdown() != nil {
+		return
+	}
+	defer daq.controlMu.Unlock()
+	daq.scanning = false
+	daq.usb().close()
+}
+
 // Reset resets the device.
 func (daq *USB1608fsplus) Reset() (int, error) {
-	requestType := libusb.BitmapRequestType(
-		libusb.HostToDevice, libusb.Vendor, libusb.DeviceRecipient)
-	ret, err := daq.DeviceHandle.ControlTransfer(
-		requestType, byte(commandReset), 0x0, 0x0, []byte{0x00}, 1, daq.Timeout)
+	ret, err := daq.controlTransferOut(commandReset, 0x0, 0x0, []byte{0x00})
 	if err != nil {
-		return ret, fmt.Errorf("Error resetting devices %s", err)
+		return ret, fmt.Errorf("Error resetting devices %w", err)
 	}
 	return ret, nil
 }
@@ -155,13 +298,12 @@ func (daq *USB1608fsplus) SendCommandToDevice(cmd command, data []byte) (int, er
 	if data == nil {
 		data = []byte{0}
 	}
-	requestType := libusb.BitmapRequestType(
-		libusb.HostToDevice, libusb.Vendor, libusb.DeviceRecipient)
-	bytesReceived, err := daq.DeviceHandle.ControlTransfer(
-		requestType, byte(cmd), 0x0, 0x0, data, len(data), daq.Timeout)
+	bytesReceived, err := daq.controlTransferOut(cmd, 0x0, 0x0, data)
 	if err != nil {
-		return bytesReceived, fmt.Errorf("error sending command '%s' to device: %s", cmd, err)
+		return bytesReceived, fmt.Errorf("error sending command '%s' to device: %w", cmd, err)
 	}
+	daq.logger().Debug("Sent command",
+		"serial_number", daq.serialNumber, "command", cmd, "bytes", bytesReceived)
 	return bytesReceived, nil
 }
 
@@ -171,22 +313,175 @@ func (daq *USB1608fsplus) ReadCommandFromDevice(cmd command, data []byte) (int,
 	if data == nil {
 		data = []byte{0}
 	}
-	requestType := libusb.BitmapRequestType(
-		libusb.DeviceToHost, libusb.Vendor, libusb.DeviceRecipient)
-	bytesReceived, err := daq.DeviceHandle.ControlTransfer(
-		requestType, byte(cmd), 0x0, 0x0, data, len(data), daq.Timeout)
+	bytesReceived, err := daq.controlTransferIn(cmd, 0x0, 0x0, data)
 	if err != nil {
-		return bytesReceived, fmt.Errorf("error reading command '%s' from device: %s", cmd, err)
+		return bytesReceived, fmt.Errorf("error reading command '%s' from device: %w", cmd, err)
 	}
+	daq.logger().Debug("Read command",
+		"serial_number", daq.serialNumber, "command", cmd, "bytes", bytesReceived)
 	return bytesReceived, nil
 }
 
-// Read reads the data using a bulk USB transfer.
+// controlTransferOut performs a host-to-device vendor control transfer of the
+// given command using the length of data as the transfer length. Any libusb
+// error code is wrapped so that it matches the mccdaq sentinel errors.
+func (daq *USB1608fsplus) controlTransferOut(
+	cmd command, value, index uint16, data []byte,
+) (int, error) {
+	daq.controlMu.Lock()
+	defer daq.controlMu.Unlock()
+	if atomic.LoadInt32(&daq.closed) != 0 {
+		return 0, fmt.Errorf("can't send command '%s': %w", cmd, mccdaq.ErrClosed)
+	}
+	if err := daq.checkScanIdle(cmd); err != nil {
+		return 0, err
+	}
+	ret, err := daq.usb().controlTransfer(false, byte(cmd), value, index, data, daq.Timeout)
+	if err != nil {
+		return ret, usberr.Wrap(err)
+	}
+	switch cmd {
+	case commandAnalogStartScan:
+		daq.scanning = true
+	case commandAnalogStopScan, commandReset:
+		daq.scanning = false
+	}
+	return ret, nil
+}
+
+// controlTransferIn performs a device-to-host vendor control transfer of the
+// given command, reading up to len(data) bytes into data. Any libusb error
+// code is wrapped so that it matches the mccdaq sentinel errors.
+func (daq *USB1608fsplus) controlTransferIn(
+	cmd command, value, index uint16, data []byte,
+) (int, error) {
+	daq.controlMu.Lock()
+	defer daq.controlMu.Unlock()
+	if atomic.LoadInt32(&daq.closed) != 0 {
+		return 0, fmt.Errorf("can't send command '%s': %w", cmd, mccdaq.ErrClosed)
+	}
+	if err := daq.checkScanIdle(cmd); err != nil {
+		return 0, err
+	}
+	ret, err := daq.usb().controlTransfer(true, byte(cmd), value, index, data, daq.Timeout)
+	if err != nil {
+		return ret, usberr.Wrap(err)
+	}
+	if cmd == commandGetStatus && ret > 0 {
+		daq.scanning = data[0]&byte(scanRunning) != 0
+	}
+	return ret, nil
+}
+
+// checkScanIdle returns mccdaq.ErrScanRunning if the command would stall the
+// bus because an analog input scan is running. A scan with a fixed number of
+// scans stops on its own, so the status is read to confirm that the scan is
+// still running before failing. The caller must hold controlMu.
+func (daq *USB1608fsplus) checkScanIdle(cmd command) error {
+	if !daq.scanning || (cmd != commandAnalogInput && cmd != commandAnalogConfig) {
+		return nil
+	}
+	data := make([]byte, 2)
+	_, err := daq.usb().controlTransfer(true, byte(commandGetStatus), 0x0, 0x0, data, daq.Timeout)
+	if err != nil {
+		return fmt.Errorf("error reading status before '%s': %w", cmd, usberr.Wrap(err))
+	}
+	daq.scanning = data[0]&byte(scanRunning) != 0
+	if daq.scanning {
+		return fmt.Errorf("can't send command '%s': %w", cmd, mccdaq.ErrScanRunning)
+	}
+	return nil
+}
+
+// ClearHalt clears the halt condition on the bulk endpoint, which the DAQ
+// stalls when an analog input scan overruns its FIFO buffer.
+func (daq *USB1608fsplus) ClearHalt() error {
+	daq.controlMu.Lock()
+	defer daq.controlMu.Unlock()
+	if atomic.LoadInt32(&daq.closed) != 0 {
+		return fmt.Errorf("can't clear bulk endpoint halt: %w", mccdaq.ErrClosed)
+	}
+	daq.logger().Debug("Clearing bulk endpoint halt", "serial_number", daq.serialNumber)
+	if err := daq.usb().clearHalt(daq.Timeout); err != nil {
+		return fmt.Errorf("error clearing bulk endpoint halt: %w", usberr.Wrap(err))
+	}
+	return nil
+}
+
+// Read reads the data using a bulk USB transfer. Only one goroutine at a time
+// may read from the bulk endpoint; any other concurrent read fails with
+// mccdaq.ErrBusy.
 func (daq *USB1608fsplus) Read(p []byte) (n int, err error) {
-	return daq.DeviceHandle.BulkTransfer(
-		daq.BulkEndpoint.EndpointAddress,
-		p,
-		len(p),
-		daq.Timeout,
-	)
+	return daq.ReadContext(context.Background(), p)
+}
+
+// ReadContext reads the data using a bulk USB transfer like Read, but gives up
+// once the context is done. A libusb bulk transfer can't be interrupted once
+// submitted, and one that times out discards any packets it had received, so
+// only a read of at most one packet is polled: it's retried with a timeout of
+// contextPollTimeout until a packet arrives or the context is done, however
+// long that takes. A longer read is a single transfer with a timeout of
+// daq.Timeout, so it should only be used for data that's sure to arrive well
+// within daq.Timeout, and the context is only checked before it's submitted.
+func (daq *USB1608fsplus) ReadContext(ctx context.Context, p []byte) (n int, err error) {
+	if atomic.LoadInt32(&daq.closed) != 0 {
+		return 0, fmt.Errorf("can't read: %w", mccdaq.ErrClosed)
+	}
+	if !atomic.CompareAndSwapInt32(&daq.bulkOwned, 0, 1) {
+		return 0, mccdaq.ErrBusy
+	}
+	defer atomic.StoreInt32(&daq.bulkOwned, 0)
+	daq.bulkMu.Lock()
+	defer daq.bulkMu.Unlock()
+	if atomic.LoadInt32(&daq.closed) != 0 {
+		return 0, fmt.Errorf("can't read: %w", mccdaq.ErrClosed)
+	}
+	if ctx.Done() == nil {
+		return daq.bulkTransfer(p, daq.Timeout)
+	}
+	if len(p) > maxBulkTransferPacketSize {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		return daq.bulkTransfer(p, daq.Timeout)
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if atomic.LoadInt32(&daq.closed) != 0 {
+			return 0, fmt.Errorf("can't read: %w", mccdaq.ErrClosed)
+		}
+		n, err = daq.bulkTransfer(p, contextPollTimeout)
+		if !errors.Is(err, mccdaq.ErrTimeout) {
+			return n, err
+		}
+	}
+}
+
+func (daq *USB1608fsplus) bulkTransfer(p []byte, timeout int) (int, error) {
+	n, err := daq.usb().bulkTransfer(p, timeout)
+	return n, usberr.Wrap(err)
+}
+
+// usb returns the handle used to talk to the DAQ.
+func (daq *USB1608fsplus) usb() usbHandle {
+	if daq.handle != nil {
+		return daq.handle
+	}
+	return libusbHandle{dh: daq.DeviceHandle, endpoint: daq.BulkEndpoint}
+}
+
+// sleep pauses the current goroutine for the duration d or until the context
+// is done, whichever happens first. The context's error is returned if the
+// context is done before d has elapsed.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
@@ -0,0 +1,279 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package usb1608fsplus
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+	"github.com/gotmc/mccdaq/internal/usberr"
+)
+
+// fakeHandle is a usbHandle that records the commands sent and any
+// overlapping control transfers, and can fail or block bulk transfers.
+type fakeHandle struct {
+	inFlight int32
+	overlaps int32
+	status   int32
+	halts    int32
+	commands []command
+	bulkErr  error
+	// timeouts is the number of bulk transfers that time out before the rest
+	// succeed, and transfers counts the bulk transfers.
+	timeouts  int32
+	transfers int32
+	// bulkStarted, if not nil, receives a value when a bulk transfer starts.
+	bulkStarted chan struct{}
+	// bulkRelease, if not nil, blocks bulk transfers until it's closed.
+	bulkRelease chan struct{}
+	// inBulk is nonzero during a bulk transfer, and closes and
+	// closesInBulk count the times the handle is closed, and closed during
+	// a bulk transfer.
+	inBulk       int32
+	closes       int32
+	closesInBulk int32
+}
+
+func (h *fakeHandle) controlTransfer(
+	in bool, request byte, value, index uint16, data []byte, timeout int,
+) (int, error) {
+	if atomic.AddInt32(&h.inFlight, 1) > 1 {
+		atomic.AddInt32(&h.overlaps, 1)
+	}
+	defer atomic.AddInt32(&h.inFlight, -1)
+	runtime.Gosched()
+	if !in {
+		h.commands = append(h.commands, command(request))
+	}
+	if in && command(request) == commandGetStatus {
+		data[0] = byte(atomic.LoadInt32(&h.status))
+	}
+	return len(data), nil
+}
+
+func (h *fakeHandle) bulkTransfer(data []byte, timeout int) (int, error) {
+	atomic.StoreInt32(&h.inBulk, 1)
+	defer atomic.StoreInt32(&h.inBulk, 0)
+	if h.bulkStarted != nil {
+		h.bulkStarted <- struct{}{}
+	}
+	if h.bulkRelease != nil {
+		<-h.bulkRelease
+	}
+	atomic.AddInt32(&h.transfers, 1)
+	if h.bulkErr != nil {
+		return 0, h.bulkErr
+	}
+	if atomic.AddInt32(&h.timeouts, -1) >= 0 {
+		return 0, usberr.Timeout
+	}
+	return len(data), nil
+}
+
+func (h *fakeHandle) releaseInterface() error {
+	return nil
+}
+
+func (h *fakeHandle) close() error {
+	atomic.AddInt32(&h.closes, 1)
+	if atomic.LoadInt32(&h.inBulk) != 0 {
+		atomic.AddInt32(&h.closesInBulk, 1)
+	}
+	return nil
+}
+
+func (h *fakeHandle) clearHalt(timeout int) error {
+	atomic.AddInt32(&h.halts, 1)
+	return nil
+}
+
+func TestControlTransfersSerialized(t *testing.T) {
+	h := fakeHandle{}
+	daq := USB1608fsplus{handle: &h, Logger: mccdaq.NopLogger}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if _, err := daq.BlinkLED(1); err != nil {
+					t.Errorf("BlinkLED: %v", err)
+				}
+				if _, err := daq.Status(); err != nil {
+					t.Errorf("Status: %v", err)
+				}
+				if _, err := daq.SerialNumber(); err != nil {
+					t.Errorf("SerialNumber: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if h.overlaps != 0 {
+		t.Errorf("Expected no overlapping control transfers, got %d", h.overlaps)
+	}
+}
+
+func TestBulkEndpointSingleOwner(t *testing.T) {
+	h := fakeHandle{
+		bulkStarted: make(chan struct{}, 1),
+		bulkRelease: make(chan struct{}),
+	}
+	daq := USB1608fsplus{handle: &h, Logger: mccdaq.NopLogger}
+	done := make(chan error)
+	go func() {
+		_, err := daq.Read(make([]byte, maxBulkTransferPacketSize))
+		done <- err
+	}()
+	<-h.bulkStarted
+	if _, err := daq.Read(make([]byte, maxBulkTransferPacketSize)); !errors.Is(err, mccdaq.ErrBusy) {
+		t.Errorf("Expected ErrBusy, got %v", err)
+	}
+	close(h.bulkRelease)
+	if err := <-done; err != nil {
+		t.Errorf("Expected first read to succeed, got %v", err)
+	}
+	h.bulkStarted = nil
+	if _, err := daq.Read(make([]byte, maxBulkTransferPacketSize)); err != nil {
+		t.Errorf("Expected read after release to succeed, got %v", err)
+	}
+}
+
+func TestReadContextPolling(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// A single packet is polled for past daq.Timeout.
+	h := fakeHandle{timeouts: 30}
+	daq := USB1608fsplus{handle: &h, Logger: mccdaq.NopLogger, Timeout: 1000}
+	n, err := daq.ReadContext(ctx, make([]byte, maxBulkTransferPacketSize))
+	if err != nil || n != maxBulkTransferPacketSize {
+		t.Errorf("Expected a packet after polling, got %d bytes and %v", n, err)
+	}
+	if h.transfers != 31 {
+		t.Errorf("Expected 31 transfers, got %d", h.transfers)
+	}
+	// A longer transfer would lose data if retried.
+	h = fakeHandle{timeouts: 1}
+	daq = USB1608fsplus{handle: &h, Logger: mccdaq.NopLogger, Timeout: 1000}
+	if _, err := daq.ReadContext(ctx, make([]byte, 2*maxBulkTransferPacketSize)); !errors.Is(err, mccdaq.ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got %v", err)
+	}
+	if h.transfers != 1 {
+		t.Errorf("Expected 1 transfer, got %d", h.transfers)
+	}
+	cancel()
+	if _, err := daq.ReadContext(ctx, make([]byte, maxBulkTransferPacketSize)); err != context.Canceled {
+		t.Errorf("Expected error %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestAnalogInputReadSingleOwner(t *testing.T) {
+	ai := AnalogInput{DAQ: &FakeDAQer{}, reading: 1}
+	if _, err := ai.Read(make([]byte, maxBulkTransferPacketSize)); !errors.Is(err, mccdaq.ErrBusy) {
+		t.Errorf("Expected ErrBusy, got %v", err)
+	}
+}
+
+func TestStallingCommandsDuringScan(t *testing.T) {
+	h := fakeHandle{}
+	daq := USB1608fsplus{handle: &h, Logger: mccdaq.NopLogger}
+	ai := AnalogInput{DAQ: &daq, Frequency: 1000}
+	ai.EnableChannel(0)
+	if err := ai.StartScan(0); err != nil {
+		t.Fatalf("StartScan: %v", err)
+	}
+	atomic.StoreInt32(&h.status, int32(scanRunning))
+	if _, err := daq.ReadAnalogInput(0, Range10V); !errors.Is(err, mccdaq.ErrScanRunning) {
+		t.Errorf("Expected ErrScanRunning from ReadAnalogInput, got %v", err)
+	}
+	if err := ai.SetScanRanges(); !errors.Is(err, mccdaq.ErrScanRunning) {
+		t.Errorf("Expected ErrScanRunning from SetScanRanges, got %v", err)
+	}
+	if _, err := daq.BlinkLED(1); err != nil {
+		t.Errorf("Expected BlinkLED to succeed during scan, got %v", err)
+	}
+	// A finite scan stops on its own, which shows up in the status.
+	atomic.StoreInt32(&h.status, 0)
+	if _, err := daq.ReadAnalogInput(0, Range10V); err != nil {
+		t.Errorf("Expected ReadAnalogInput to succeed after scan finished, got %v", err)
+	}
+	atomic.StoreInt32(&h.status, int32(scanRunning))
+	if err := ai.StartScan(0); err != nil {
+		t.Fatalf("StartScan: %v", err)
+	}
+	if err := ai.StopScan(); err != nil {
+		t.Fatalf("StopScan: %v", err)
+	}
+	if _, err := daq.ReadAnalogInput(0, Range10V); err != nil {
+		t.Errorf("Expected ReadAnalogInput to succeed after StopScan, got %v", err)
+	}
+}
+
+func TestClearHalt(t *testing.T) {
+	h := fakeHandle{}
+	daq := USB1608fsplus{handle: &h, Logger: mccdaq.NopLogger}
+	if err := daq.ClearHalt(); err != nil {
+		t.Fatalf("ClearHalt: %v", err)
+	}
+	if h.halts != 1 {
+		t.Errorf("Expected bulk endpoint halt cleared once, got %d", h.halts)
+	}
+}
+
+func TestCloseDuringRead(t *testing.T) {
+	h := fakeHandle{
+		bulkStarted: make(chan struct{}, 1),
+		bulkRelease: make(chan struct{}),
+	}
+	daq := USB1608fsplus{handle: &h, Logger: mccdaq.NopLogger, Timeout: defaultTimeout}
+	read := make(chan error)
+	go func() {
+		_, err := daq.Read(make([]byte, maxBulkTransferPacketSize))
+		read <- err
+	}()
+	<-h.bulkStarted
+	closed := make(chan error)
+	go func() {
+		closed <- daq.Close()
+	}()
+	for atomic.LoadInt32(&daq.closed) == 0 {
+		runtime.Gosched()
+	}
+	select {
+	case err := <-closed:
+		t.Fatalf("Expected Close to wait for the read, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, err := daq.Read(make([]byte, maxBulkTransferPacketSize)); !errors.Is(err, mccdaq.ErrClosed) {
+		t.Errorf("Expected ErrClosed from a read while closing, got %v", err)
+	}
+	close(h.bulkRelease)
+	if err := <-read; err != nil {
+		t.Errorf("Expected the read in progress to succeed, got %v", err)
+	}
+	if err := <-closed; err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	if h.closes != 1 || h.closesInBulk != 0 {
+		t.Errorf("Expected the handle closed once outside a transfer, got %d and %d",
+			h.closes, h.closesInBulk)
+	}
+	if _, err := daq.Status(); !errors.Is(err, mccdaq.ErrClosed) {
+		t.Errorf("Expected ErrClosed from a command, got %v", err)
+	}
+	if _, err := daq.Read(make([]byte, maxBulkTransferPacketSize)); !errors.Is(err, mccdaq.ErrClosed) {
+		t.Errorf("Expected ErrClosed from a read, got %v", err)
+	}
+	if err := daq.Close(); !errors.Is(err, mccdaq.ErrClosed) {
+		t.Errorf("Expected ErrClosed closing twice, got %v", err)
+	}
+}
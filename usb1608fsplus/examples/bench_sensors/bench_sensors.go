@@ -0,0 +1,105 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"os/signal"
+
+	"github.com/gotmc/mccdaq/sensor"
+	"github.com/gotmc/mccdaq/usb1608fsplus"
+)
+
+func main() {
+	usbCtx, err := usb1608fsplus.Init()
+	if err != nil {
+		log.Fatal("Couldn't create USB context. Ending now.")
+	}
+	defer usbCtx.Close()
+
+	daq, err := usb1608fsplus.GetFirstDevice(usbCtx)
+	if err != nil {
+		log.Fatalf("Couldn't find a USB-1608FS-Plus: %s", err)
+	}
+	defer daq.Close()
+
+	ai, err := daq.NewAnalogInput()
+	if err != nil {
+		log.Fatalf("Error creating analog input: %s", err)
+	}
+	configData, err := ioutil.ReadFile("./sensor_config.json")
+	if err != nil {
+		log.Fatalf("Error reading the USB-1608FS-Plus JSON config file")
+	}
+	var configJSON = struct {
+		*usb1608fsplus.AnalogInput `json:"analog_input"`
+	}{
+		ai,
+	}
+	if err := json.NewDecoder(bytes.NewReader(configData)).Decode(&configJSON); err != nil {
+		log.Fatalf("parse USB-1608FS-Plus: %v", err)
+	}
+
+	// Stop on Ctrl-C.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		cancel()
+	}()
+
+	sensors := ai.Sensors()
+	descriptions := ai.Descriptions()
+	units := ai.Units()
+	converter, err := sensor.New(sensors, sensor.WithHandler(func(f sensor.Fault) {
+		if f.Condition == sensor.OK {
+			log.Printf("%s recovered at scan %d", descriptions[f.Channel], f.Scan)
+			return
+		}
+		log.Printf("%s is %s at scan %d (signal %g)", descriptions[f.Channel], f.Condition, f.Scan, f.Signal)
+	}))
+	if err != nil {
+		log.Fatalf("Error creating sensor converter: %s", err)
+	}
+	stream, err := ai.StartStream(ctx)
+	if err != nil {
+		log.Fatalf("Error starting stream: %s", err)
+	}
+	for frame := range converter.Watch(ctx, stream.Frames()) {
+		for i, ch := range frame.Channels {
+			if _, ok := sensors[ch]; !ok {
+				continue
+			}
+			// Average the scans that weren't faulted.
+			sum, n := 0.0, 0
+			for _, v := range frame.Volts[i] {
+				if !math.IsNaN(v) && !math.IsInf(v, 0) {
+					sum += v
+					n++
+				}
+			}
+			if n == 0 {
+				log.Printf("%s: faulted", descriptions[ch])
+				continue
+			}
+			log.Printf("%s: %.2f %s", descriptions[ch], sum/float64(n), units[ch])
+		}
+	}
+	if err := converter.Err(); err != nil {
+		log.Printf("Sensor conversion failed: %s", err)
+	}
+	if err := stream.Stop(); err != nil {
+		log.Printf("Stream ended with error: %s", err)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/gotmc/mccdaq/filter"
+	"github.com/gotmc/mccdaq/usb1608fsplus"
+)
+
+const framesToRead = 50
+
+func main() {
+	usbCtx, err := usb1608fsplus.Init()
+	if err != nil {
+		log.Fatal("Couldn't create USB context. Ending now.")
+	}
+	defer usbCtx.Close()
+
+	daq, err := usb1608fsplus.GetFirstDevice(usbCtx)
+	if err != nil {
+		log.Fatalf("Couldn't find a USB-1608FS-Plus: %s", err)
+	}
+	defer daq.Close()
+
+	ai, err := daq.NewAnalogInput()
+	if err != nil {
+		log.Fatalf("Error creating analog input: %s", err)
+	}
+	ai.Frequency = 5000.0
+	if err := ai.ConfigureEnableChannel(0, "10V", "Channel 0"); err != nil {
+		log.Fatalf("Error configuring channel 0: %s", err)
+	}
+	if err := ai.ConfigureEnableChannel(1, "10V", "Channel 1"); err != nil {
+		log.Fatalf("Error configuring channel 1: %s", err)
+	}
+
+	// Design the filters for the scan rate the pacer actually achieves.
+	plan, err := ai.Plan(0)
+	if err != nil {
+		log.Fatalf("Error planning scan: %s", err)
+	}
+	lowpass, err := filter.Lowpass(4, 200, plan.Rate)
+	if err != nil {
+		log.Fatalf("Error designing lowpass filter: %s", err)
+	}
+	notch, err := filter.Notch(60, 10, plan.Rate)
+	if err != nil {
+		log.Fatalf("Error designing notch filter: %s", err)
+	}
+	median, err := filter.NewMedian(5)
+	if err != nil {
+		log.Fatalf("Error creating median filter: %s", err)
+	}
+	bank := filter.NewBank()
+	if err := bank.Set(0, notch, lowpass); err != nil {
+		log.Fatalf("Error attaching channel 0 filters: %s", err)
+	}
+	if err := bank.Set(1, median); err != nil {
+		log.Fatalf("Error attaching channel 1 filter: %s", err)
+	}
+
+	// Stop on Ctrl-C.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		cancel()
+	}()
+
+	stream, err := ai.StartStream(ctx)
+	if err != nil {
+		log.Fatalf("Error starting stream: %s", err)
+	}
+	numFrames := 0
+	for frame := range bank.Watch(ctx, stream.Frames()) {
+		log.Printf("Scan %d: ch0 = %.4f V, ch1 = %.4f V", frame.Index,
+			frame.Volts[0][0], frame.Volts[1][0])
+		numFrames++
+		if numFrames == framesToRead {
+			break
+		}
+	}
+	if err := stream.Stop(); err != nil {
+		log.Printf("Stream ended with error: %s", err)
+	}
+}
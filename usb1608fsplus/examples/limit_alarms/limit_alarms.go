@@ -0,0 +1,104 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/gotmc/mccdaq/alarm"
+	"github.com/gotmc/mccdaq/usb1608fsplus"
+)
+
+func main() {
+	usbCtx, err := usb1608fsplus.Init()
+	if err != nil {
+		log.Fatal("Couldn't create USB context. Ending now.")
+	}
+	defer usbCtx.Close()
+
+	daq, err := usb1608fsplus.GetFirstDevice(usbCtx)
+	if err != nil {
+		log.Fatalf("Couldn't find a USB-1608FS-Plus: %s", err)
+	}
+	defer daq.Close()
+
+	ai, err := daq.NewAnalogInput()
+	if err != nil {
+		log.Fatalf("Error creating analog input: %s", err)
+	}
+
+	// Configure the channels and their alarm limits.
+	configData, err := ioutil.ReadFile("./alarm_config.json")
+	if err != nil {
+		log.Fatalf("Error reading the USB-1608FS-Plus JSON config file")
+	}
+	var configJSON = struct {
+		*usb1608fsplus.AnalogInput `json:"analog_input"`
+	}{
+		ai,
+	}
+	if err := json.NewDecoder(bytes.NewReader(configData)).Decode(&configJSON); err != nil {
+		log.Fatalf("parse USB-1608FS-Plus: %v", err)
+	}
+
+	// Stop monitoring on Ctrl-C, or once a latched alarm is raised.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		cancel()
+	}()
+
+	// Apply the limits in each channel's engineering units.
+	scales, err := ai.Scales()
+	if err != nil {
+		log.Fatalf("Invalid channel scale: %s", err)
+	}
+	descriptions := ai.Descriptions()
+	units := ai.Units()
+	engine, err := alarm.New(ai.Limits(), alarm.WithHandler(func(e alarm.Event) {
+		if e.Raised && ai.Channels[e.Channel].Limits.Latch {
+			// Replace with the action that makes the test stand safe, such
+			// as cutting its power.
+			log.Printf("Shutting down on %s alarm on %s", e.Kind, descriptions[e.Channel])
+			cancel()
+		}
+	}))
+	if err != nil {
+		log.Fatalf("Error creating alarm engine: %s", err)
+	}
+	stream, err := ai.StartStream(ctx)
+	if err != nil {
+		log.Fatalf("Error starting stream: %s", err)
+	}
+	for e := range engine.Watch(ctx, scales.Watch(ctx, stream.Frames())) {
+		state := "cleared"
+		if e.Raised {
+			state = "raised"
+		}
+		unit := units[e.Channel]
+		if e.Kind == alarm.RateOfChange {
+			unit += "/s"
+		}
+		log.Printf("%s %s %s alarm %s: %.4f %s (limit %.4f %s)",
+			e.Time.Format("15:04:05.000"), descriptions[e.Channel], e.Kind, state,
+			e.Value, unit, e.Limit, unit)
+	}
+	if err := engine.Err(); err != nil {
+		log.Printf("Alarm engine failed: %s", err)
+	}
+	if err := stream.Stop(); err != nil {
+		log.Printf("Stream ended with error: %s", err)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/gotmc/mccdaq/bridge"
+	"github.com/gotmc/mccdaq/usb1608fsplus"
+)
+
+func main() {
+	usbCtx, err := usb1608fsplus.Init()
+	if err != nil {
+		log.Fatal("Couldn't create USB context. Ending now.")
+	}
+	defer usbCtx.Close()
+
+	daq, err := usb1608fsplus.GetFirstDevice(usbCtx)
+	if err != nil {
+		log.Fatalf("Couldn't find a USB-1608FS-Plus: %s", err)
+	}
+	defer daq.Close()
+
+	ai, err := daq.NewAnalogInput()
+	if err != nil {
+		log.Fatalf("Error creating analog input: %s", err)
+	}
+	configData, err := ioutil.ReadFile("./bridge_config.json")
+	if err != nil {
+		log.Fatalf("Error reading the USB-1608FS-Plus JSON config file")
+	}
+	var configJSON = struct {
+		*usb1608fsplus.AnalogInput `json:"analog_input"`
+	}{
+		ai,
+	}
+	if err := json.NewDecoder(bytes.NewReader(configData)).Decode(&configJSON); err != nil {
+		log.Fatalf("parse USB-1608FS-Plus: %v", err)
+	}
+
+	// Stop on Ctrl-C.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		cancel()
+	}()
+
+	bridges := ai.Bridges()
+	converter, err := bridge.New(bridges)
+	if err != nil {
+		log.Fatalf("Error creating bridge converter: %s", err)
+	}
+	descriptions := ai.Descriptions()
+	units := ai.Units()
+	stream, err := ai.StartStream(ctx)
+	if err != nil {
+		log.Fatalf("Error starting stream: %s", err)
+	}
+
+	// Zero the bridges on the first frame, which must be taken unloaded.
+	first, ok := <-stream.Frames()
+	if !ok {
+		log.Printf("Stream ended before the first frame: %v", stream.Stop())
+		return
+	}
+	if err := converter.Zero(first); err != nil {
+		log.Printf("Error zeroing bridges: %s", err)
+		stream.Stop()
+		return
+	}
+	for frame := range converter.Watch(ctx, stream.Frames()) {
+		for i, ch := range frame.Channels {
+			if _, ok := bridges[ch]; !ok {
+				continue
+			}
+			sum := 0.0
+			for _, v := range frame.Volts[i] {
+				sum += v
+			}
+			log.Printf("%s: %.2f %s", descriptions[ch], sum/float64(frame.NumScans()), units[ch])
+		}
+	}
+	if err := converter.Err(); err != nil {
+		log.Printf("Bridge conversion failed: %s", err)
+	}
+	if err := stream.Stop(); err != nil {
+		log.Printf("Stream ended with error: %s", err)
+	}
+}
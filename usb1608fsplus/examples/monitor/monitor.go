@@ -0,0 +1,71 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/gotmc/mccdaq/stats"
+	"github.com/gotmc/mccdaq/usb1608fsplus"
+)
+
+func main() {
+	usbCtx, err := usb1608fsplus.Init()
+	if err != nil {
+		log.Fatal("Couldn't create USB context. Ending now.")
+	}
+	defer usbCtx.Close()
+
+	daq, err := usb1608fsplus.GetFirstDevice(usbCtx)
+	if err != nil {
+		log.Fatalf("Couldn't find a USB-1608FS-Plus: %s", err)
+	}
+	defer daq.Close()
+
+	ai, err := daq.NewAnalogInput()
+	if err != nil {
+		log.Fatalf("Error creating analog input: %s", err)
+	}
+	ai.Frequency = 1000.0
+	if err := ai.ConfigureEnableChannel(0, "10V", "Supply voltage"); err != nil {
+		log.Fatalf("Error configuring channel 0: %s", err)
+	}
+	if err := ai.ConfigureEnableChannel(1, "5V", "Load current"); err != nil {
+		log.Fatalf("Error configuring channel 1: %s", err)
+	}
+
+	// Stop monitoring on Ctrl-C.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		cancel()
+	}()
+
+	// Summarize each channel once a second.
+	scansPerSecond := int(ai.Frequency)
+	summarizer, err := stats.New(scansPerSecond, scansPerSecond, stats.WithLabels(ai.Descriptions()))
+	if err != nil {
+		log.Fatalf("Error creating summarizer: %s", err)
+	}
+	stream, err := ai.StartStream(ctx)
+	if err != nil {
+		log.Fatalf("Error starting stream: %s", err)
+	}
+	for s := range summarizer.Watch(ctx, stream.Frames()) {
+		log.Printf("%s %s: mean %.4f V, RMS %.4f V, std dev %.4f V, min %.4f V, max %.4f V, p-p %.4f V (%d samples)",
+			s.Start.Format("15:04:05"), s.Label, s.Mean, s.RMS, s.StdDev, s.Min, s.Max,
+			s.PeakToPeak, s.Count)
+	}
+	if err := stream.Stop(); err != nil {
+		log.Printf("Stream ended with error: %s", err)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/gotmc/mccdaq/decimate"
+	"github.com/gotmc/mccdaq/usb1608fsplus"
+)
+
+const samplesToRead = 200
+
+func main() {
+	usbCtx, err := usb1608fsplus.Init()
+	if err != nil {
+		log.Fatal("Couldn't create USB context. Ending now.")
+	}
+	defer usbCtx.Close()
+
+	daq, err := usb1608fsplus.GetFirstDevice(usbCtx)
+	if err != nil {
+		log.Fatalf("Couldn't find a USB-1608FS-Plus: %s", err)
+	}
+	defer daq.Close()
+
+	ai, err := daq.NewAnalogInput()
+	if err != nil {
+		log.Fatalf("Error creating analog input: %s", err)
+	}
+	ai.Frequency = 50000.0
+	if err := ai.ConfigureEnableChannel(0, "10V", "Channel 0"); err != nil {
+		log.Fatalf("Error configuring channel 0: %s", err)
+	}
+	if err := ai.ConfigureEnableChannel(1, "10V", "Channel 1"); err != nil {
+		log.Fatalf("Error configuring channel 1: %s", err)
+	}
+
+	// Stop on Ctrl-C.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		cancel()
+	}()
+
+	// Store channel 0 at 100 Hz through a CIC filter, and channel 1 at 1 kHz
+	// by averaging.
+	configs := []decimate.Config{
+		{Channel: 0, Factor: 500, Method: decimate.CIC},
+		{Channel: 1, Factor: 50, Method: decimate.Average},
+	}
+	for _, c := range configs {
+		bits, err := c.BitsGained()
+		if err != nil {
+			log.Fatalf("Error configuring decimation: %s", err)
+		}
+		log.Printf("Channel %d: %.0f Hz by %s, gaining %.1f bits",
+			c.Channel, ai.Frequency/float64(c.Factor), c.Method, bits)
+	}
+	d, err := decimate.New(configs...)
+	if err != nil {
+		log.Fatalf("Error creating decimator: %s", err)
+	}
+	stream, err := ai.StartStream(ctx)
+	if err != nil {
+		log.Fatalf("Error starting stream: %s", err)
+	}
+	numSamples := 0
+	for samples := range d.Watch(ctx, stream.Frames()) {
+		if samples.Channel == 0 {
+			for i, v := range samples.Volts {
+				log.Printf("ch0 at %s = %.6f V", samples.Time(i).Format("15:04:05.000"), v)
+			}
+			numSamples += len(samples.Volts)
+		}
+		if numSamples >= samplesToRead {
+			break
+		}
+	}
+	if err := stream.Stop(); err != nil {
+		log.Printf("Stream ended with error: %s", err)
+	}
+}
@@ -74,6 +74,12 @@ func main() {
 	log.Printf("ai = %v", ai)
 	log.Printf("ai.Frequency = %f Hz", ai.Frequency)
 	log.Printf("ai.Channels[7].Range= %v", ai.Channels[7].Range)
+	plan, err := ai.Plan(0)
+	if err != nil {
+		log.Fatalf("Invalid scan configuration: %s", err)
+	}
+	log.Printf("Scanning at %f Hz (requested %f Hz); FIFO fills in %s; read %d bytes at a time",
+		plan.Rate, plan.Requested, plan.FIFOFillTime, plan.ReadSize)
 	ai.SetScanRanges()
 	log.Printf("Frequency = %f Hz", ai.Frequency)
 
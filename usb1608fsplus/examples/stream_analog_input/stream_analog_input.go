@@ -0,0 +1,75 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/gotmc/mccdaq/usb1608fsplus"
+)
+
+const framesToRead = 50
+
+func main() {
+	usbCtx, err := usb1608fsplus.Init()
+	if err != nil {
+		log.Fatal("Couldn't create USB context. Ending now.")
+	}
+	defer usbCtx.Close()
+
+	daq, err := usb1608fsplus.GetFirstDevice(usbCtx)
+	if err != nil {
+		log.Fatalf("Couldn't find a USB-1608FS-Plus: %s", err)
+	}
+	defer daq.Close()
+
+	ai, err := daq.NewAnalogInput()
+	if err != nil {
+		log.Fatalf("Error creating analog input: %s", err)
+	}
+	ai.Frequency = 10000.0
+	if err := ai.ConfigureEnableChannel(0, "10V", "Channel 0"); err != nil {
+		log.Fatalf("Error configuring channel 0: %s", err)
+	}
+	if err := ai.ConfigureEnableChannel(1, "5V", "Channel 1"); err != nil {
+		log.Fatalf("Error configuring channel 1: %s", err)
+	}
+
+	// Stop streaming on Ctrl-C.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		cancel()
+	}()
+
+	stream, err := ai.StartStream(ctx, usb1608fsplus.WithBufferDepth(8),
+		usb1608fsplus.WithBackpressure(usb1608fsplus.DropOldest))
+	if err != nil {
+		log.Fatalf("Error starting stream: %s", err)
+	}
+	numFrames := 0
+	for frame := range stream.Frames() {
+		log.Printf("Scan %d at %s: ch%d = %.4f V, ch%d = %.4f V (%d scans, drift %.1f ppm)",
+			frame.Index, frame.Time(0).Format("15:04:05.000000"),
+			frame.Channels[0], frame.Volts[0][0],
+			frame.Channels[1], frame.Volts[1][0], frame.NumScans(),
+			frame.Timebase.Drift.PPM)
+		numFrames++
+		if numFrames == framesToRead {
+			break
+		}
+	}
+	if err := stream.Stop(); err != nil {
+		log.Printf("Stream ended with error: %s", err)
+	}
+	log.Printf("Dropped %d frames", stream.Dropped())
+}
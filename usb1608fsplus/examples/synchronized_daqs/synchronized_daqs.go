@@ -0,0 +1,97 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/gotmc/mccdaq/usb1608fsplus"
+)
+
+const framesToRead = 50
+
+var (
+	masterSN string
+	slaveSN  string
+)
+
+func init() {
+	flag.StringVar(&masterSN, "master", "01AF3FAE", "S/N of the DAQ driving the pacer on SYNC")
+	flag.StringVar(&slaveSN, "slave", "01AF3FBA", "S/N of the DAQ paced from SYNC")
+}
+
+func main() {
+	flag.Parse()
+	if masterSN == slaveSN {
+		log.Fatalf("S/Ns cannot be the same %s.", masterSN)
+	}
+
+	usbCtx, err := usb1608fsplus.Init()
+	if err != nil {
+		log.Fatal("Couldn't create USB context. Ending now.")
+	}
+	defer usbCtx.Close()
+
+	// Open both DAQs and enable all eight channels on each for a 16 channel
+	// recording. The SYNC pins of the two DAQs must be wired together.
+	var inputs []*usb1608fsplus.AnalogInput
+	for _, sn := range []string{masterSN, slaveSN} {
+		daq, err := usb1608fsplus.NewViaSN(usbCtx, sn)
+		if err != nil {
+			log.Fatalf("Couldn't open S/N %s: %s", sn, err)
+		}
+		defer daq.Close()
+		ai, err := daq.NewAnalogInput()
+		if err != nil {
+			log.Fatalf("Error creating analog input for S/N %s: %s", sn, err)
+		}
+		ai.Frequency = 10000.0
+		for ch := 0; ch < 8; ch++ {
+			if err := ai.ConfigureEnableChannel(ch, "10V", ""); err != nil {
+				log.Fatalf("Error configuring channel %d of S/N %s: %s", ch, sn, err)
+			}
+		}
+		inputs = append(inputs, ai)
+	}
+
+	group, err := usb1608fsplus.NewGroup(inputs[0], inputs[1:]...)
+	if err != nil {
+		log.Fatalf("Error creating group: %s", err)
+	}
+
+	// Stop streaming on Ctrl-C.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		cancel()
+	}()
+
+	if err := group.Start(ctx); err != nil {
+		log.Fatalf("Error starting group: %s", err)
+	}
+	numFrames := 0
+	for frame := range group.Frames() {
+		log.Printf("Scan %d at %s: ch%d = %.4f V, ch%d = %.4f V (%d channels, %d scans)",
+			frame.Index, frame.Time(0).Format("15:04:05.000000"),
+			frame.Channels[0], frame.Volts[0][0],
+			frame.Channels[8], frame.Volts[8][0],
+			len(frame.Channels), frame.NumScans())
+		numFrames++
+		if numFrames == framesToRead {
+			break
+		}
+	}
+	if err := group.Stop(); err != nil {
+		log.Printf("Group ended with error: %s", err)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/gotmc/mccdaq/alarm"
+	"github.com/gotmc/mccdaq/thermocouple"
+	"github.com/gotmc/mccdaq/usb1608fsplus"
+)
+
+// coldJunctionChannel has an LM35 on the terminal block, which gives 10 mV
+// per °C.
+const coldJunctionChannel = 7
+
+func main() {
+	usbCtx, err := usb1608fsplus.Init()
+	if err != nil {
+		log.Fatal("Couldn't create USB context. Ending now.")
+	}
+	defer usbCtx.Close()
+
+	daq, err := usb1608fsplus.GetFirstDevice(usbCtx)
+	if err != nil {
+		log.Fatalf("Couldn't find a USB-1608FS-Plus: %s", err)
+	}
+	defer daq.Close()
+
+	ai, err := daq.NewAnalogInput()
+	if err != nil {
+		log.Fatalf("Error creating analog input: %s", err)
+	}
+	configData, err := ioutil.ReadFile("./thermocouple_config.json")
+	if err != nil {
+		log.Fatalf("Error reading the USB-1608FS-Plus JSON config file")
+	}
+	var configJSON = struct {
+		*usb1608fsplus.AnalogInput `json:"analog_input"`
+	}{
+		ai,
+	}
+	if err := json.NewDecoder(bytes.NewReader(configData)).Decode(&configJSON); err != nil {
+		log.Fatalf("parse USB-1608FS-Plus: %v", err)
+	}
+
+	// Stop on Ctrl-C, or once a thermocouple exceeds its limit.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		cancel()
+	}()
+
+	lm35 := ai.Channels[coldJunctionChannel].Scale
+	converter, err := thermocouple.New(ai.Thermocouples(),
+		thermocouple.Channel(coldJunctionChannel, lm35.Value))
+	if err != nil {
+		log.Fatalf("Error creating thermocouple converter: %s", err)
+	}
+	descriptions := ai.Descriptions()
+	engine, err := alarm.New(ai.Limits(), alarm.WithHandler(func(e alarm.Event) {
+		if e.Raised {
+			// Replace with the action that makes the test stand safe, such
+			// as cutting its power.
+			log.Printf("Shutting down: %s at %.1f °C exceeds %.1f °C",
+				descriptions[e.Channel], e.Value, e.Limit)
+			cancel()
+		}
+	}))
+	if err != nil {
+		log.Fatalf("Error creating alarm engine: %s", err)
+	}
+	stream, err := ai.StartStream(ctx)
+	if err != nil {
+		log.Fatalf("Error starting stream: %s", err)
+	}
+	for e := range engine.Watch(ctx, converter.Watch(ctx, stream.Frames())) {
+		log.Printf("%s %s %s alarm raised %v at %.1f °C",
+			e.Time.Format("15:04:05.000"), descriptions[e.Channel], e.Kind, e.Raised, e.Value)
+	}
+	if err := converter.Err(); err != nil {
+		log.Printf("Thermocouple conversion failed: %s", err)
+	}
+	if err := engine.Err(); err != nil {
+		log.Printf("Alarm engine failed: %s", err)
+	}
+	if err := stream.Stop(); err != nil {
+		log.Printf("Stream ended with error: %s", err)
+	}
+}
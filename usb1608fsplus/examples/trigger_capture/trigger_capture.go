@@ -0,0 +1,78 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/gotmc/mccdaq/trigger"
+	"github.com/gotmc/mccdaq/usb1608fsplus"
+)
+
+const capturesToRead = 5
+
+func main() {
+	usbCtx, err := usb1608fsplus.Init()
+	if err != nil {
+		log.Fatal("Couldn't create USB context. Ending now.")
+	}
+	defer usbCtx.Close()
+
+	daq, err := usb1608fsplus.GetFirstDevice(usbCtx)
+	if err != nil {
+		log.Fatalf("Couldn't find a USB-1608FS-Plus: %s", err)
+	}
+	defer daq.Close()
+
+	ai, err := daq.NewAnalogInput()
+	if err != nil {
+		log.Fatalf("Error creating analog input: %s", err)
+	}
+	ai.Frequency = 10000.0
+	if err := ai.ConfigureEnableChannel(0, "10V", "Channel 0"); err != nil {
+		log.Fatalf("Error configuring channel 0: %s", err)
+	}
+	if err := ai.ConfigureEnableChannel(1, "10V", "Channel 1"); err != nil {
+		log.Fatalf("Error configuring channel 1: %s", err)
+	}
+
+	// Stop on Ctrl-C.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		cancel()
+	}()
+
+	// Capture 10 ms before and 40 ms after channel 0 rises through 2.5 V.
+	edge := trigger.Edge{Level: 2.5, Hysteresis: 0.1, Direction: trigger.Rising}
+	trig, err := trigger.New(0, &edge, 100, 400)
+	if err != nil {
+		log.Fatalf("Error creating trigger: %s", err)
+	}
+	stream, err := ai.StartStream(ctx)
+	if err != nil {
+		log.Fatalf("Error starting stream: %s", err)
+	}
+	numCaptures := 0
+	for capture := range trig.Watch(ctx, stream.Frames()) {
+		log.Printf("Triggered at scan %d (%s): %d scans, ch1 = %.4f V at the trigger",
+			capture.Scan, capture.Time.Format("15:04:05.000000"),
+			capture.Frame.NumScans(), capture.Frame.Volts[1][capture.TriggerOffset])
+		numCaptures++
+		if numCaptures == capturesToRead {
+			break
+		}
+	}
+	if err := stream.Stop(); err != nil {
+		log.Printf("Stream ended with error: %s", err)
+	}
+}
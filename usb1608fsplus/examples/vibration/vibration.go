@@ -0,0 +1,87 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/gotmc/mccdaq/spectral"
+	"github.com/gotmc/mccdaq/usb1608fsplus"
+)
+
+func main() {
+	usbCtx, err := usb1608fsplus.Init()
+	if err != nil {
+		log.Fatal("Couldn't create USB context. Ending now.")
+	}
+	defer usbCtx.Close()
+
+	daq, err := usb1608fsplus.GetFirstDevice(usbCtx)
+	if err != nil {
+		log.Fatalf("Couldn't find a USB-1608FS-Plus: %s", err)
+	}
+	defer daq.Close()
+
+	ai, err := daq.NewAnalogInput()
+	if err != nil {
+		log.Fatalf("Error creating analog input: %s", err)
+	}
+	ai.Frequency = 10000.0
+	if err := ai.ConfigureEnableChannel(0, "5V", "Accelerometer"); err != nil {
+		log.Fatalf("Error configuring channel 0: %s", err)
+	}
+
+	// Stop analyzing on Ctrl-C.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		cancel()
+	}()
+
+	// Analyze blocks of 8192 samples, about 0.8 s each.
+	const blockSize = 8192
+	stream, err := ai.StartStream(ctx)
+	if err != nil {
+		log.Fatalf("Error starting stream: %s", err)
+	}
+	var block []float64
+	for frame := range stream.Frames() {
+		volts, rate, err := spectral.Samples(frame, 0)
+		if err != nil {
+			log.Printf("Error reading channel 0: %s", err)
+			break
+		}
+		if frame.LostScans > 0 {
+			block = block[:0]
+		}
+		block = append(block, volts...)
+		if len(block) < blockSize {
+			continue
+		}
+		d, err := spectral.Analyze(block[:blockSize], rate, spectral.BlackmanHarris, 5)
+		if err != nil {
+			log.Printf("Error analyzing block: %s", err)
+			break
+		}
+		psd, err := spectral.Welch(block[:blockSize], rate, 1024, 0.5, spectral.Hann)
+		if err != nil {
+			log.Printf("Error estimating PSD: %s", err)
+			break
+		}
+		log.Printf("%.2f Hz at %.4f V: SNR %.1f dB, THD %.1f dB, SINAD %.1f dB, 1–5 kHz power %.3g V²",
+			d.Fundamental, d.Amplitude, d.SNR, d.THD, d.SINAD, psd.BandPower(1000, 5000))
+		block = append(block[:0], block[blockSize:]...)
+	}
+	if err := stream.Stop(); err != nil {
+		log.Printf("Stream ended with error: %s", err)
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package usb1608fsplus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// Group acquires from several DAQs in lockstep. The master DAQ's internal
+// pacer paces its own scan and is output on its SYNC pin, which must be wired
+// to the SYNC pin of each slave DAQ, so that every DAQ takes a scan on the
+// same pacer pulse. The group streams from every DAQ and merges their frames
+// into frames with a combined channel list, in which channel ch of the DAQ at
+// position k, counting from zero for the master, is numbered k*8+ch. So two
+// stacked eight-channel DAQs record channels 0 to 15.
+type Group struct {
+	inputs  []*AnalogInput // Master first, then the slaves
+	streams []*Stream
+	frames  chan mccdaq.Frame
+	cancel  context.CancelFunc
+	done    chan struct{}
+	stopped sync.Once
+	err     error
+}
+
+// NewGroup creates a group from the master analog input and the slave analog
+// inputs. NewGroup sets the master to output its pacer on SYNC, and sets each
+// slave to use an external pacer at the master's frequency. Since a slave
+// can't be restarted in step with the master, every analog input must use the
+// OverrunFail policy, and NewGroup fails with mccdaq.ErrNotSupported
+// otherwise, as it does if the master uses an external pacer.
+func NewGroup(master *AnalogInput, slaves ...*AnalogInput) (*Group, error) {
+	if len(slaves) == 0 {
+		return nil, fmt.Errorf("group needs at least one slave")
+	}
+	if master.UseExternalPacer {
+		return nil, fmt.Errorf("group master can't use an external pacer: %w", mccdaq.ErrNotSupported)
+	}
+	inputs := append([]*AnalogInput{master}, slaves...)
+	for i, ai := range inputs {
+		if ai.NumEnabledChannels() == 0 {
+			return nil, fmt.Errorf("no channels enabled on group DAQ %d: %w", i, mccdaq.ErrInvalidChannel)
+		}
+		if ai.OverrunPolicy != OverrunFail {
+			return nil, fmt.Errorf("group DAQ %d uses overrun policy %s: %w",
+				i, ai.OverrunPolicy, mccdaq.ErrNotSupported)
+		}
+	}
+	master.OutputPacerOnSync = true
+	for _, ai := range slaves {
+		ai.UseExternalPacer = true
+		ai.OutputPacerOnSync = false
+		ai.Frequency = master.Frequency
+	}
+	g := Group{inputs: inputs}
+	return &g, nil
+}
+
+// Start arms each slave by starting its stream, so that it waits for pacer
+// pulses on SYNC, and then starts the master's stream, which starts the
+// pacer. The stream options apply to every DAQ, except that the number of
+// scans per frame is rounded up to suit every DAQ, so that their frames line
+// up. Keep the default Block backpressure, since a frame dropped by one DAQ's
+// stream leaves the frames out of step, which ends the group's streams. If any
+// stream fails to start, the streams already started are stopped.
+func (g *Group) Start(ctx context.Context, opts ...StreamOption) error {
+	var probe Stream
+	for _, opt := range opts {
+		opt(&probe)
+	}
+	scansPerFrame := probe.scansPerFrame
+	if scansPerFrame <= 0 {
+		scansPerFrame = int(g.inputs[0].Frequency / defaultFramesPerSecond)
+	}
+	// Each DAQ's packet alignment is a power of two scans, so rounding up for
+	// each in turn suits them all.
+	for _, ai := range g.inputs {
+		scansPerFrame = packetAlignedScans(scansPerFrame, ai.NumEnabledChannels())
+	}
+	opts = append(opts, WithScansPerFrame(scansPerFrame))
+	g.streams = make([]*Stream, len(g.inputs))
+	for i := len(g.inputs) - 1; i >= 0; i-- {
+		s, err := g.inputs[i].StartStream(ctx, opts...)
+		if err != nil {
+			for _, started := range g.streams[i+1:] {
+				started.Stop()
+			}
+			return fmt.Errorf("error starting group DAQ %d: %w", i, err)
+		}
+		g.streams[i] = s
+	}
+	ctx, g.cancel = context.WithCancel(ctx)
+	g.frames = make(chan mccdaq.Frame, defaultStreamBufferDepth)
+	g.done = make(chan struct{})
+	go g.run(ctx)
+	return nil
+}
+
+// Frames returns the channel on which the merged frames are delivered. The
+// channel is closed once the group's streams end, after which Err reports
+// why.
+func (g *Group) Frames() <-chan mccdaq.Frame {
+	return g.frames
+}
+
+// Err returns the error that ended the group's streams, if any, once the
+// frames channel has been closed.
+func (g *Group) Err() error {
+	select {
+	case <-g.done:
+		return g.err
+	default:
+		return nil
+	}
+}
+
+// Stop stops the master's stream, which stops the pacer, and then the slaves'
+// streams, and waits for the merging goroutine to exit. Stop returns the
+// error that ended the group's streams, if any, and may be called more than
+// once. Stop does nothing if the group wasn't started, such as when Start
+// failed, since Start stops any streams it started before failing.
+func (g *Group) Stop() error {
+	if g.done == nil {
+		return nil
+	}
+	g.stopped.Do(func() {
+		g.cancel()
+		g.stopStreams()
+	})
+	<-g.done
+	return g.err
+}
+
+// stopStreams stops every stream, master first, and returns the first error
+// that ended any of them.
+func (g *Group) stopStreams() error {
+	var first error
+	for i, s := range g.streams {
+		if err := s.Stop(); err != nil && first == nil {
+			first = fmt.Errorf("group DAQ %d: %w", i, err)
+		}
+	}
+	return first
+}
+
+// run merges a frame from each stream in turn until a stream ends or the
+// frames no longer line up.
+func (g *Group) run(ctx context.Context) {
+	defer close(g.done)
+	defer close(g.frames)
+	parts := make([]mccdaq.Frame, len(g.streams))
+	for {
+		for i, s := range g.streams {
+			frame, ok := <-s.Frames()
+			if !ok {
+				g.err = g.stopStreams()
+				return
+			}
+			parts[i] = frame
+		}
+		merged, err := mergeFrames(parts)
+		if err != nil {
+			g.stopStreams()
+			g.err = err
+			return
+		}
+		select {
+		case g.frames <- merged:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// mergeFrames merges frames of the same scans from the DAQs of a group,
+// master first, into a single frame with the combined channel list and the
+// master's timebase.
+func mergeFrames(parts []mccdaq.Frame) (mccdaq.Frame, error) {
+	master := parts[0]
+	merged := mccdaq.Frame{
+		Index:    master.Index,
+		Timebase: master.Timebase,
+	}
+	for k, part := range parts {
+		if part.Index != master.Index || part.NumScans() != master.NumScans() {
+			return merged, fmt.Errorf(
+				"group DAQ %d frame of %d scans at scan %d doesn't line up with master frame of %d scans at scan %d",
+				k, part.NumScans(), part.Index, master.NumScans(), master.Index)
+		}
+		for _, ch := range part.Channels {
+			merged.Channels = append(merged.Channels, k*numChannels+ch)
+		}
+		merged.Raw = append(merged.Raw, part.Raw...)
+		merged.Volts = append(merged.Volts, part.Volts...)
+	}
+	return merged, nil
+}
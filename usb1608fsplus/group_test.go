@@ -0,0 +1,161 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package usb1608fsplus
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// orderedDAQer records the order in which the scans of several fake DAQs are
+// started.
+type orderedDAQer struct {
+	*FakeDAQer
+	name   string
+	mu     *sync.Mutex
+	starts *[]string
+}
+
+func (d orderedDAQer) SendCommandToDevice(cmd command, data []byte) (int, error) {
+	if cmd == commandAnalogStartScan {
+		d.mu.Lock()
+		*d.starts = append(*d.starts, d.name)
+		d.mu.Unlock()
+	}
+	return d.FakeDAQer.SendCommandToDevice(cmd, data)
+}
+
+// newGroupTestInput returns an analog input with the given channels enabled
+// and the given number of scans of data, where each sample reads the scan
+// number plus 1000 times the channel number.
+func newGroupTestInput(name string, channels []int, scans int,
+	mu *sync.Mutex, starts *[]string) *AnalogInput {
+	f := FakeDAQer{StatusByte: byte(scanRunning)}
+	for scan := 0; scan < scans; scan++ {
+		for _, ch := range channels {
+			f.Data = append(f.Data, EncodeWord(uint16(scan+1000*ch))...)
+		}
+	}
+	ai := AnalogInput{
+		DAQ:          orderedDAQer{&f, name, mu, starts},
+		Frequency:    1000,
+		TransferMode: BlockTransfer,
+		Logger:       mccdaq.NopLogger,
+	}
+	for _, ch := range channels {
+		ai.EnableChannel(ch)
+	}
+	return &ai
+}
+
+func TestGroup(t *testing.T) {
+	var mu sync.Mutex
+	var starts []string
+	master := newGroupTestInput("master", []int{0, 1}, 64, &mu, &starts)
+	slave1 := newGroupTestInput("slave1", []int{0, 1, 2}, 64, &mu, &starts)
+	slave2 := newGroupTestInput("slave2", []int{7}, 64, &mu, &starts)
+	g, err := NewGroup(master, slave1, slave2)
+	if err != nil {
+		t.Fatalf("NewGroup: %v", err)
+	}
+	if !master.OutputPacerOnSync || master.UseExternalPacer {
+		t.Errorf("Expected master to output its pacer on SYNC")
+	}
+	if !slave1.UseExternalPacer || !slave2.UseExternalPacer {
+		t.Errorf("Expected slaves to use an external pacer")
+	}
+	if err := g.Start(context.Background(), WithScansPerFrame(20)); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	wantStarts := []string{"slave2", "slave1", "master"}
+	if !reflect.DeepEqual(starts, wantStarts) {
+		t.Errorf("Expected scans started in order %v, got %v", wantStarts, starts)
+	}
+	wantChannels := []int{0, 1, 8, 9, 10, 23}
+	for i := 0; i < 2; i++ {
+		frame := <-g.Frames()
+		if frame.Index != uint64(32*i) {
+			t.Errorf("Expected frame index %d, got %d", 32*i, frame.Index)
+		}
+		if !reflect.DeepEqual(frame.Channels, wantChannels) {
+			t.Errorf("Expected channels %v, got %v", wantChannels, frame.Channels)
+		}
+		if frame.NumScans() != 32 {
+			t.Fatalf("Expected 32 scans, got %d", frame.NumScans())
+		}
+		for j, ch := range frame.Channels {
+			want := uint16(32*i + 5 + 1000*(ch%numChannels))
+			if got := frame.Raw[j][5]; got != want {
+				t.Errorf("Expected channel %d raw %d, got %d", ch, want, got)
+			}
+		}
+	}
+	if err := g.Stop(); err != nil {
+		t.Errorf("Expected no error from Stop, got %v", err)
+	}
+	if _, ok := <-g.Frames(); ok {
+		t.Errorf("Expected frames channel to be closed")
+	}
+}
+
+func TestGroupStopWithoutStart(t *testing.T) {
+	var mu sync.Mutex
+	var starts []string
+	master := newGroupTestInput("master", []int{0}, 0, &mu, &starts)
+	slave := newGroupTestInput("slave", []int{0}, 0, &mu, &starts)
+	g, err := NewGroup(master, slave)
+	if err != nil {
+		t.Fatalf("NewGroup: %v", err)
+	}
+	if err := g.Stop(); err != nil {
+		t.Errorf("Expected no error from Stop before Start, got %v", err)
+	}
+	if err := g.Start(context.Background(), WithBackpressure(Backpressure(9))); !errors.Is(err, mccdaq.ErrNotSupported) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrNotSupported, err)
+	}
+	if err := g.Stop(); err != nil {
+		t.Errorf("Expected no error from Stop after a failed Start, got %v", err)
+	}
+}
+
+func TestNewGroupErrors(t *testing.T) {
+	var mu sync.Mutex
+	var starts []string
+	master := newGroupTestInput("master", []int{0}, 0, &mu, &starts)
+	slave := newGroupTestInput("slave", []int{0}, 0, &mu, &starts)
+	if _, err := NewGroup(master); err == nil {
+		t.Error("Expected error for a group without slaves")
+	}
+	master.UseExternalPacer = true
+	if _, err := NewGroup(master, slave); !errors.Is(err, mccdaq.ErrNotSupported) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrNotSupported, err)
+	}
+	master.UseExternalPacer = false
+	slave.OverrunPolicy = OverrunRestart
+	if _, err := NewGroup(master, slave); !errors.Is(err, mccdaq.ErrNotSupported) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrNotSupported, err)
+	}
+	slave.OverrunPolicy = OverrunFail
+	slave.DisableChannel(0)
+	if _, err := NewGroup(master, slave); !errors.Is(err, mccdaq.ErrInvalidChannel) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrInvalidChannel, err)
+	}
+}
+
+func TestMergeFramesMisaligned(t *testing.T) {
+	parts := []mccdaq.Frame{
+		{Index: 0, Channels: []int{0}, Raw: [][]uint16{make([]uint16, 4)}},
+		{Index: 4, Channels: []int{0}, Raw: [][]uint16{make([]uint16, 4)}},
+	}
+	if _, err := mergeFrames(parts); err == nil {
+		t.Error("Expected error for misaligned frames")
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package usb1608fsplus
+
+import "github.com/gotmc/libusb"
+
+// usbHandle is the subset of the libusb device handle used to talk to the DAQ.
+// It exists so that the tests can substitute a fake device for the hardware.
+type usbHandle interface {
+	// controlTransfer performs a vendor control transfer to the device, which
+	// is device-to-host if in is true and host-to-device otherwise.
+	controlTransfer(
+		in bool, request byte, value, index uint16, data []byte, timeout int,
+	) (int, error)
+	// bulkTransfer reads from the DAQ's bulk endpoint.
+	bulkTransfer(data []byte, timeout int) (int, error)
+	// releaseInterface releases the DAQ's interface.
+	releaseInterface() error
+	// close closes the device handle.
+	close() error
+	// clearHalt clears the halt (stall) condition on the DAQ's bulk endpoint.
+	clearHalt(timeout int) error
+}
+
+// Standard USB request and feature selector used to clear an endpoint halt.
+const (
+	requestClearFeature = 0x01
+	featureEndpointHalt = 0x00
+)
+
+// libusbHandle implements usbHandle using a libusb device handle and the DAQ's
+// bulk endpoint.
+type libusbHandle struct {
+	dh       *libusb.DeviceHandle
+	endpoint *libusb.EndpointDescriptor
+}
+
+func (h libusbHandle) controlTransfer(
+	in bool, request byte, value, index uint16, data []byte, timeout int,
+) (int, error) {
+	direction := libusb.HostToDevice
+	if in {
+		direction = libusb.DeviceToHost
+	}
+	requestType := libusb.BitmapRequestType(
+		direction, libusb.Vendor, libusb.DeviceRecipient)
+	return h.dh.ControlTransfer(
+		requestType, request, value, index, data, len(data), timeout)
+}
+
+func (h libusbHandle) bulkTransfer(data []byte, timeout int) (int, error) {
+	return h.dh.BulkTransfer(h.endpoint.EndpointAddress, data, len(data), timeout)
+}
+
+func (h libusbHandle) releaseInterface() error {
+	return h.dh.ReleaseInterface(0)
+}
+
+func (h libusbHandle) close() error {
+	return h.dh.Close()
+}
+
+// clearHalt sends a standard CLEAR_FEATURE(ENDPOINT_HALT) request for the bulk
+// endpoint, since the libusb package doesn't wrap libusb_clear_halt. The data
+// buffer is never sent, but ControlTransfer requires a nonempty slice.
+func (h libusbHandle) clearHalt(timeout int) error {
+	requestType := libusb.BitmapRequestType(
+		libusb.HostToDevice, libusb.Standard, libusb.EndpointRecipient)
+	var data [1]byte
+	_, err := h.dh.ControlTransfer(requestType, requestClearFeature,
+		featureEndpointHalt, uint16(h.endpoint.EndpointAddress), data[:], 0, timeout)
+	return err
+}
@@ -9,23 +9,18 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
-
-	"github.com/gotmc/libusb"
 )
 
 // BlinkLED blinks the LED the given number of times. Note, the LED starts
 // being unlit, but will end being lit.
 func (daq *USB1608fsplus) BlinkLED(blinks int) (int, error) {
-	requestType := libusb.BitmapRequestType(
-		libusb.HostToDevice, libusb.Vendor, libusb.DeviceRecipient)
 	// data := byteSlice(blinks)
 	data := make([]byte, 1)
 	data[0] = byte(blinks)
 
-	ret, err := daq.DeviceHandle.ControlTransfer(
-		requestType, byte(commandBlinkLED), 0x0, 0x0, data, len(data), daq.Timeout)
+	ret, err := daq.controlTransferOut(commandBlinkLED, 0x0, 0x0, data)
 	if err != nil {
-		return ret, fmt.Errorf("Error blinking LED %s", err)
+		return ret, fmt.Errorf("Error blinking LED %w", err)
 	}
 	return ret, nil
 }
@@ -33,11 +28,11 @@ func (daq *USB1608fsplus) BlinkLED(blinks int) (int, error) {
 // Status retrieves the status of the device and clears the error
 // indicators.
 func (daq *USB1608fsplus) Status() (byte, error) {
-	requestType := libusb.BitmapRequestType(
-		libusb.DeviceToHost, libusb.Vendor, libusb.DeviceRecipient)
 	data := make([]byte, 2)
-	daq.DeviceHandle.ControlTransfer(
-		requestType, byte(commandGetStatus), 0x0, 0x0, data, len(data), daq.Timeout)
+	_, err := daq.controlTransferIn(commandGetStatus, 0x0, 0x0, data)
+	if err != nil {
+		return 0, fmt.Errorf("Error reading status %w", err)
+	}
 	status := DecodeWord(data)
 	return byte(status), nil
 }
@@ -45,11 +40,11 @@ func (daq *USB1608fsplus) Status() (byte, error) {
 // SerialNumber retrieves the serial number via a control transfer using the
 // serial command (0x48) as opposed to using the libusb serial number.
 func (daq *USB1608fsplus) SerialNumber() (string, error) {
-	requestType := libusb.BitmapRequestType(
-		libusb.DeviceToHost, libusb.Vendor, libusb.DeviceRecipient)
 	data := make([]byte, 8)
-	daq.DeviceHandle.ControlTransfer(
-		requestType, byte(commandSerialNum), 0x0, 0x0, data, len(data), daq.Timeout)
+	_, err := daq.controlTransferIn(commandSerialNum, 0x0, 0x0, data)
+	if err != nil {
+		return "", fmt.Errorf("Error reading serial number %w", err)
+	}
 	return string(data), nil
 }
 
@@ -58,13 +53,10 @@ func (daq *USB1608fsplus) SerialNumber() (string, error) {
 // enumerate in the bootloader and is unusable as a DAQ device until new
 // firmware is loaded.
 func (daq *USB1608fsplus) UpgradeFirmware() error {
-	requestType := libusb.BitmapRequestType(
-		libusb.HostToDevice, libusb.Vendor, libusb.DeviceRecipient)
 	key := uint16(0xadad)
-	_, err := daq.DeviceHandle.ControlTransfer(
-		requestType, byte(commandUpgradeFirmware), key, 0x0, []byte{}, 0, daq.Timeout)
+	_, err := daq.controlTransferOut(commandUpgradeFirmware, key, 0x0, []byte{})
 	if err != nil {
-		return fmt.Errorf("Error enabling upgrade firmware mode %s", err)
+		return fmt.Errorf("Error enabling upgrade firmware mode %w", err)
 	}
 	return nil
 }
@@ -24,6 +24,7 @@ func TestConvertBinaryValueToVoltage(t *testing.T) {
 		{4.999847412109375, Range5V, []byte{0xff, 0xff}},
 	}
 	for _, tc := range testCases {
+		tc := tc
 		t.Run(fmt.Sprintf("Convert binary value %#x", tc.given), func(t *testing.T) {
 			t.Parallel()
 			computed, _ := RawVoltsFromWord(tc.given, tc.vr)
@@ -69,6 +70,7 @@ func TestVoltsFromWord(t *testing.T) {
 		{[]byte{0xFF, 0x7F}, Range10V, 1.0, 1.0, 0.00},
 	}
 	for _, tc := range testCases {
+		tc := tc
 		t.Run(fmt.Sprintf("Volts from %#x", tc.given), func(t *testing.T) {
 			t.Parallel()
 			computed, _ := VoltsFromWord(tc.given, tc.vr, tc.slope, tc.offset)
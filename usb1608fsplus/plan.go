@@ -0,0 +1,135 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package usb1608fsplus
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// Limits of the USB-1608FS-Plus analog input scan.
+const (
+	// maxScanRate is the fastest internal pacer rate, which is also the
+	// fastest rate at which each channel can be sampled.
+	maxScanRate = 100000
+	// maxAggregateRate is the fastest total sample rate, summed over the
+	// enabled channels, that the DAQ can sustain over USB.
+	maxAggregateRate = 500000
+	// fifoSamples is the size of the DAQ's scan FIFO buffer in samples.
+	fifoSamples = 32768
+	// maxImmediateRate is the fastest scan rate recommended for immediate
+	// transfer mode, which sends a packet after every scan.
+	maxImmediateRate = 100
+	// readsPerSecond sets the recommended read size, limited to a fraction
+	// of the FIFO buffer given by fifoReadFraction.
+	readsPerSecond   = 10
+	fifoReadFraction = 4
+)
+
+// minScanRate is the slowest internal pacer rate, set by the 32-bit pacer
+// period.
+const minScanRate = 40e6 / (1 << 32)
+
+// ScanPlan describes how the DAQ will carry out an analog input scan, as
+// worked out by AnalogInput.Plan.
+type ScanPlan struct {
+	// Requested is the requested scan rate in Hz, and Rate is the scan rate in
+	// Hz that the pacer actually achieves once its period is rounded to a
+	// whole number of 40 MHz clock ticks. When using an external pacer, Rate
+	// is the given Frequency, which may be zero if unknown.
+	Requested   float64
+	Rate        float64
+	PacerPeriod int
+	NumChannels int
+	NumScans    int
+	// SampleRate is the total number of samples per second over all the
+	// enabled channels.
+	SampleRate float64
+	// FIFOFillTime is how long the DAQ can keep acquiring into its FIFO
+	// buffer without the host reading before the scan overruns, and
+	// FitsInFIFO reports whether the whole of a scan with a fixed number of
+	// scans fits in the FIFO buffer.
+	FIFOFillTime time.Duration
+	FitsInFIFO   bool
+	// TransferMode is the recommended transfer mode, ScansPerRead the
+	// recommended number of scans to read at a time, ReadSize the
+	// corresponding buffer size in bytes, and StreamBufferDepth the
+	// recommended number of frames of that size to buffer in a Stream to
+	// cover a second of data.
+	TransferMode      TransferMode
+	ScansPerRead      int
+	ReadSize          int
+	StreamBufferDepth int
+}
+
+// Plan validates the analog input configuration for a scan with the given
+// number of scans, or a continuous scan if numScans is zero, and returns how
+// the DAQ will carry it out. The internal pacer can't scan faster than 100
+// kHz, and a continuous scan can't exceed 500 kS/s over all the enabled
+// channels, although a scan that fits in the 32,768 sample FIFO buffer can
+// reach 100 kHz on all eight channels. Plan fails with
+// mccdaq.ErrInvalidChannel if no channels are enabled and with
+// mccdaq.ErrInvalidFrequency if the scan rate is out of range.
+func (ai *AnalogInput) Plan(numScans int) (ScanPlan, error) {
+	plan := ScanPlan{
+		Requested:   ai.Frequency,
+		Rate:        ai.Frequency,
+		NumChannels: ai.NumEnabledChannels(),
+		NumScans:    numScans,
+	}
+	if plan.NumChannels == 0 {
+		return plan, fmt.Errorf("no channels enabled for scan: %w", mccdaq.ErrInvalidChannel)
+	}
+	if numScans < 0 {
+		return plan, fmt.Errorf("number of scans %d is negative", numScans)
+	}
+	plan.FitsInFIFO = numScans > 0 && numScans*plan.NumChannels <= fifoSamples
+	if ai.UseExternalPacer {
+		if ai.Frequency < 0 || ai.Frequency > maxScanRate {
+			return plan, fmt.Errorf("external pacer rate %g Hz outside 0 to %d Hz: %w",
+				ai.Frequency, maxScanRate, mccdaq.ErrInvalidFrequency)
+		}
+	} else {
+		if ai.Frequency < minScanRate || ai.Frequency > maxScanRate {
+			return plan, fmt.Errorf("scan rate %g Hz outside %.4g to %d Hz: %w",
+				ai.Frequency, minScanRate, maxScanRate, mccdaq.ErrInvalidFrequency)
+		}
+		plan.PacerPeriod = calculatePacerPeriod(ai.Frequency)
+		plan.Rate = 40e6 / float64(plan.PacerPeriod+1)
+	}
+	plan.SampleRate = plan.Rate * float64(plan.NumChannels)
+	if plan.SampleRate > maxAggregateRate && !plan.FitsInFIFO {
+		return plan, fmt.Errorf(
+			"%d channels at %g Hz exceeds %d S/s for a scan that doesn't fit in the FIFO: %w",
+			plan.NumChannels, plan.Rate, maxAggregateRate, mccdaq.ErrInvalidFrequency)
+	}
+	plan.TransferMode = BlockTransfer
+	if plan.Rate > 0 && plan.Rate <= maxImmediateRate {
+		plan.TransferMode = ImmediateTransfer
+	}
+	plan.ScansPerRead = packetAlignedScans(0, plan.NumChannels)
+	if plan.Rate > 0 {
+		plan.FIFOFillTime = time.Duration(fifoSamples / plan.SampleRate * float64(time.Second))
+		scans := int(math.Min(plan.Rate/readsPerSecond,
+			float64(fifoSamples/fifoReadFraction/plan.NumChannels)))
+		plan.ScansPerRead = packetAlignedScans(scans, plan.NumChannels)
+	}
+	if numScans > 0 && plan.ScansPerRead > numScans {
+		plan.ScansPerRead = numScans
+	}
+	plan.ReadSize = plan.ScansPerRead * plan.NumChannels * bytesPerWord
+	plan.StreamBufferDepth = defaultStreamBufferDepth
+	if plan.Rate > 0 {
+		depth := int(math.Ceil(plan.Rate / float64(plan.ScansPerRead)))
+		if depth > plan.StreamBufferDepth {
+			plan.StreamBufferDepth = depth
+		}
+	}
+	return plan, nil
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package usb1608fsplus
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+)
+
+func TestPlan(t *testing.T) {
+	testCases := []struct {
+		frequency    float64
+		numChannels  int
+		numScans     int
+		rate         float64
+		pacerPeriod  int
+		mode         TransferMode
+		scansPerRead int
+		fifoFillTime time.Duration
+	}{
+		{100000, 1, 0, 100000, 399, BlockTransfer, 8192, 327680 * time.Microsecond},
+		{3000, 2, 0, 40e6 / 13333, 13332, BlockTransfer, 304, 5461197 * time.Microsecond},
+		{62500, 8, 0, 62500, 639, BlockTransfer, 1024, 65536 * time.Microsecond},
+		{100000, 8, 4096, 100000, 399, BlockTransfer, 1024, 40960 * time.Microsecond},
+		{10, 3, 0, 10, 3999999, ImmediateTransfer, 32, 1092266666 * time.Microsecond},
+		{10000, 1, 100, 10000, 3999, BlockTransfer, 100, 3276800 * time.Microsecond},
+	}
+	for _, tc := range testCases {
+		ai := AnalogInput{Frequency: tc.frequency}
+		for ch := 0; ch < tc.numChannels; ch++ {
+			ai.EnableChannel(ch)
+		}
+		plan, err := ai.Plan(tc.numScans)
+		if err != nil {
+			t.Errorf("%g Hz on %d channels: unexpected error %v", tc.frequency, tc.numChannels, err)
+			continue
+		}
+		if math.Abs(plan.Rate-tc.rate) > 1e-9 || plan.PacerPeriod != tc.pacerPeriod {
+			t.Errorf("%g Hz: expected %v Hz with pacer period %d, got %v Hz with %d",
+				tc.frequency, tc.rate, tc.pacerPeriod, plan.Rate, plan.PacerPeriod)
+		}
+		if plan.TransferMode != tc.mode || plan.ScansPerRead != tc.scansPerRead {
+			t.Errorf("%g Hz on %d channels: expected %v mode reading %d scans, got %v mode reading %d",
+				tc.frequency, tc.numChannels, tc.mode, tc.scansPerRead, plan.TransferMode, plan.ScansPerRead)
+		}
+		if plan.ReadSize != tc.scansPerRead*tc.numChannels*bytesPerWord {
+			t.Errorf("%g Hz on %d channels: expected read size for %d scans, got %d bytes",
+				tc.frequency, tc.numChannels, tc.scansPerRead, plan.ReadSize)
+		}
+		if diff := plan.FIFOFillTime - tc.fifoFillTime; diff < -time.Microsecond || diff > time.Microsecond {
+			t.Errorf("%g Hz on %d channels: expected FIFO fill time %v, got %v",
+				tc.frequency, tc.numChannels, tc.fifoFillTime, plan.FIFOFillTime)
+		}
+	}
+}
+
+func TestPlanErrors(t *testing.T) {
+	testCases := []struct {
+		frequency   float64
+		external    bool
+		numChannels int
+		numScans    int
+		expected    error
+	}{
+		{1000, false, 0, 0, mccdaq.ErrInvalidChannel},
+		{0, false, 1, 0, mccdaq.ErrInvalidFrequency},
+		{0.001, false, 1, 0, mccdaq.ErrInvalidFrequency},
+		{100001, false, 1, 0, mccdaq.ErrInvalidFrequency},
+		{100000, false, 8, 0, mccdaq.ErrInvalidFrequency},
+		{100000, false, 8, 4097, mccdaq.ErrInvalidFrequency},
+		{200000, true, 1, 0, mccdaq.ErrInvalidFrequency},
+		{0, true, 1, 0, nil},
+		{100000, false, 5, 0, nil},
+	}
+	for _, tc := range testCases {
+		ai := AnalogInput{Frequency: tc.frequency, UseExternalPacer: tc.external}
+		for ch := 0; ch < tc.numChannels; ch++ {
+			ai.EnableChannel(ch)
+		}
+		if _, err := ai.Plan(tc.numScans); !errors.Is(err, tc.expected) {
+			t.Errorf("%g Hz on %d channels for %d scans: expected %v, got %v",
+				tc.frequency, tc.numChannels, tc.numScans, tc.expected, err)
+		}
+	}
+}
+
+func TestStartScanRefusesInvalidPlan(t *testing.T) {
+	f := FakeDAQer{}
+	ai := AnalogInput{DAQ: &f, Frequency: 100000}
+	for ch := 0; ch < 8; ch++ {
+		ai.EnableChannel(ch)
+	}
+	if err := ai.StartScan(0); !errors.Is(err, mccdaq.ErrInvalidFrequency) {
+		t.Errorf("Expected ErrInvalidFrequency, got %v", err)
+	}
+	if len(f.Commands) != 0 {
+		t.Errorf("Expected no commands sent to the DAQ, got %v", f.Commands)
+	}
+}
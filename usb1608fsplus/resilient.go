@@ -0,0 +1,309 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package usb1608fsplus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gotmc/libusb"
+	"github.com/gotmc/mccdaq"
+	"github.com/gotmc/mccdaq/internal/usberr"
+)
+
+const defaultRetryInterval = time.Second
+
+// Gap describes an interruption in an analog input scan that a Resilient
+// recovered from by reconnecting to the DAQ and restarting the scan. Any scans
+// the DAQ would have acquired between Start and End are missing from the data.
+type Gap struct {
+	Start     time.Time // When the disconnect was detected
+	End       time.Time // When the scan was restarted
+	Scans     uint64    // Number of complete scans read before the gap
+	LostScans uint64    // Estimated number of scans missing from the data
+	Attempts  int       // Number of attempts needed to reopen the DAQ
+	Err       error     // Error that revealed the disconnect
+}
+
+// Resilient wraps a USB1608fsplus and its AnalogInput so that an analog input
+// scan survives the DAQ resetting or being briefly unplugged. When a read
+// fails because the DAQ disconnected, Resilient finds the DAQ again by serial
+// number, reclaims its interface, writes the scan ranges, restarts the scan
+// with the same settings, and reports the gap in the data via OnGap. Reads
+// only return whole scans, so a gap always falls on a scan boundary. The
+// scans lost during the gap are counted like those lost to an overrun, so the
+// scan's timebase carries on from the first scan after the gap.
+//
+// StopScan and Close may be called from another goroutine while a read is
+// reconnecting, but the AnalogInput itself must only be used by the reading
+// goroutine while a scan is running.
+type Resilient struct {
+	// RetryInterval is how long to wait before each attempt to reopen the DAQ.
+	RetryInterval time.Duration
+	// MaxAttempts limits the number of attempts to reopen the DAQ after it
+	// disconnects. Zero means keep trying until the read's context is done.
+	MaxAttempts int
+	// OnGap, if not nil, is called after each successful reconnect.
+	OnGap func(Gap)
+	// Disconnected reports whether a read error means that the DAQ
+	// disconnected and should be reopened. It defaults to IsDisconnect.
+	Disconnected func(error) bool
+
+	sn   string
+	open func(ctx context.Context) (*USB1608fsplus, error)
+	// mu guards daq, the DAQ of ai, scanning, and closed, so that StopScan and
+	// Close never use a DAQ as it's being swapped by a reconnect, and guards
+	// bytesRead, which StartScanContext resets.
+	mu        sync.Mutex
+	daq       *USB1608fsplus
+	ai        *AnalogInput
+	scanning  bool
+	closed    bool
+	bytesRead uint64
+}
+
+// IsDisconnect reports whether the error means that the DAQ disconnected. It
+// matches mccdaq.ErrDisconnected, as well as the libusb I/O and not found
+// errors, which are often the first errors seen when a DAQ resets.
+func IsDisconnect(err error) bool {
+	var code libusb.ErrorCode
+	if errors.As(err, &code) && (code == usberr.IO || code == usberr.NotFound) {
+		return true
+	}
+	return errors.Is(err, mccdaq.ErrDisconnected)
+}
+
+// NewResilient opens the DAQ with the given serial number and creates its
+// AnalogInput, returning a Resilient that reconnects automatically if the DAQ
+// disconnects. The options are applied to the DAQ each time it's opened.
+func NewResilient(usbCtx *libusb.Context, sn string, opts ...Option) (*Resilient, error) {
+	return NewResilientContext(context.Background(), usbCtx, sn, opts...)
+}
+
+// NewResilientContext creates a new Resilient like NewResilient, but gives up
+// opening the DAQ once the context is done.
+func NewResilientContext(
+	ctx context.Context, usbCtx *libusb.Context, sn string, opts ...Option,
+) (*Resilient, error) {
+	open := func(ctx context.Context) (*USB1608fsplus, error) {
+		return NewViaSNContext(ctx, usbCtx, sn, opts...)
+	}
+	daq, err := open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ai, err := daq.NewAnalogInputContext(ctx)
+	if err != nil {
+		daq.Close()
+		return nil, err
+	}
+	return newResilient(sn, daq, ai, open), nil
+}
+
+func newResilient(
+	sn string,
+	daq *USB1608fsplus,
+	ai *AnalogInput,
+	open func(ctx context.Context) (*USB1608fsplus, error),
+) *Resilient {
+	return &Resilient{
+		RetryInterval: defaultRetryInterval,
+		Disconnected:  IsDisconnect,
+		sn:            sn,
+		open:          open,
+		daq:           daq,
+		ai:            ai,
+	}
+}
+
+// DAQ returns the DAQ currently in use, which changes after a reconnect.
+func (r *Resilient) DAQ() *USB1608fsplus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.daq
+}
+
+// AnalogInput returns the analog input, whose settings are reapplied to the
+// DAQ after a reconnect. Configure the analog input before starting a scan.
+func (r *Resilient) AnalogInput() *AnalogInput {
+	return r.ai
+}
+
+// StartScan writes the scan ranges and starts an analog input scan, which is
+// restarted after a reconnect. If numScans is nonzero, the restarted scan only
+// acquires the scans remaining.
+func (r *Resilient) StartScan(numScans int) error {
+	return r.StartScanContext(context.Background(), numScans)
+}
+
+// StartScanContext starts an analog input scan like StartScan, but doesn't
+// send any further commands to the DAQ once the context is done.
+func (r *Resilient) StartScanContext(ctx context.Context, numScans int) error {
+	if err := r.ai.SetScanRanges(); err != nil {
+		return err
+	}
+	if err := r.ai.StartScanContext(ctx, numScans); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scanning = true
+	r.bytesRead = 0
+	return nil
+}
+
+// StartStream starts a continuous analog input scan and streams it like
+// AnalogInput.StartStream, but reads the scan through the Resilient, so that
+// the stream carries on after a reconnect. The frame after a reconnect reports
+// the scans estimated to be lost during the gap in its LostScans field.
+func (r *Resilient) StartStream(ctx context.Context, opts ...StreamOption) (*Stream, error) {
+	s, err := r.ai.newStream(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return s.start(ctx, r)
+}
+
+// StopScan stops the analog input scan, so that it isn't restarted after a
+// reconnect.
+func (r *Resilient) StopScan() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scanning = false
+	return r.ai.StopScan()
+}
+
+// Close stops the analog input scan and closes the DAQ, even if stopping the
+// scan fails. A read that's reconnecting gives up once the DAQ is closed.
+func (r *Resilient) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return fmt.Errorf("can't close resilient daq %s twice: %w", r.sn, mccdaq.ErrClosed)
+	}
+	r.scanning = false
+	r.closed = true
+	stopErr := r.ai.StopScan()
+	// A DAQ that disconnected was already closed by the reconnecting read.
+	if err := r.daq.Close(); err != nil && !errors.Is(err, mccdaq.ErrClosed) {
+		return err
+	}
+	if errors.Is(stopErr, mccdaq.ErrClosed) {
+		return nil
+	}
+	return stopErr
+}
+
+// Read reads the analog input data like AnalogInput.Read. If the DAQ
+// disconnects, Read returns the whole scans read before the disconnect, and
+// the scan resumes after reconnecting, blocking until the DAQ is reopened or
+// MaxAttempts is reached.
+func (r *Resilient) Read(p []byte) (n int, err error) {
+	return r.ReadContext(context.Background(), p)
+}
+
+// ReadContext reads the analog input data like Read, but gives up reading or
+// reconnecting once the context is done.
+func (r *Resilient) ReadContext(ctx context.Context, p []byte) (n int, err error) {
+	n, err = r.ai.ReadContext(ctx, p)
+	r.mu.Lock()
+	r.bytesRead += uint64(n)
+	r.mu.Unlock()
+	disconnected := r.Disconnected
+	if disconnected == nil {
+		disconnected = IsDisconnect
+	}
+	if err != nil && disconnected(err) {
+		if err := r.reconnect(ctx, err); err != nil {
+			return n, err
+		}
+		return n, nil
+	}
+	return n, err
+}
+
+// reconnect reopens the DAQ after it disconnected and resumes the scan.
+func (r *Resilient) reconnect(ctx context.Context, cause error) error {
+	start := time.Now()
+	old := r.DAQ()
+	old.logger().Warn("DAQ disconnected; reconnecting",
+		"serial_number", r.sn, "error", cause)
+	old.abandon()
+	for attempt := 1; r.MaxAttempts == 0 || attempt <= r.MaxAttempts; attempt++ {
+		if err := sleep(ctx, r.RetryInterval); err != nil {
+			return err
+		}
+		daq, err := r.open(ctx)
+		if err != nil {
+			old.logger().Debug("Couldn't reopen DAQ",
+				"serial_number", r.sn, "attempt", attempt, "error", err)
+			continue
+		}
+		lost, err := r.resume(ctx, daq)
+		if errors.Is(err, mccdaq.ErrClosed) {
+			daq.Close()
+			return err
+		}
+		if err != nil {
+			daq.logger().Debug("Couldn't resume scan",
+				"serial_number", r.sn, "attempt", attempt, "error", err)
+			daq.abandon()
+			continue
+		}
+		gap := Gap{
+			Start:     start,
+			End:       time.Now(),
+			Scans:     r.scansRead(),
+			LostScans: lost,
+			Attempts:  attempt,
+			Err:       cause,
+		}
+		daq.logger().Info("Reconnected DAQ",
+			"serial_number", r.sn, "attempts", attempt, "gap", gap.End.Sub(gap.Start))
+		if r.OnGap != nil {
+			r.OnGap(gap)
+		}
+		return nil
+	}
+	return fmt.Errorf("couldn't reconnect to daq %s after %d attempts: %w",
+		r.sn, r.MaxAttempts, cause)
+}
+
+// resume switches the analog input to the reopened DAQ, writes the scan
+// ranges, and restarts the scan if one was running, returning the estimated
+// number of scans lost. The lock is held throughout, so that a concurrent
+// StopScan either keeps the scan from restarting or stops the restarted scan.
+// It fails with mccdaq.ErrClosed if the Resilient was closed while
+// reconnecting.
+func (r *Resilient) resume(ctx context.Context, daq *USB1608fsplus) (uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return 0, fmt.Errorf("resilient daq %s closed while reconnecting: %w", r.sn, mccdaq.ErrClosed)
+	}
+	r.daq = daq
+	r.ai.DAQ = daq
+	r.ai.serialNumber = daq.serialNumber
+	if !r.scanning {
+		return 0, r.ai.SetScanRanges()
+	}
+	lost, _, err := r.ai.restartScan(ctx, 0)
+	return lost, err
+}
+
+// scansRead returns the number of complete scans read since the scan started.
+func (r *Resilient) scansRead() uint64 {
+	scanBytes := uint64(r.ai.NumEnabledChannels() * bytesPerWord)
+	if scanBytes == 0 {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.bytesRead / scanBytes
+}
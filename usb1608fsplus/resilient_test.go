@@ -0,0 +1,223 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package usb1608fsplus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+	"github.com/gotmc/mccdaq/internal/usberr"
+)
+
+func newTestResilient(
+	open func(ctx context.Context) (*USB1608fsplus, error),
+) (*Resilient, *fakeHandle) {
+	h := fakeHandle{}
+	daq := USB1608fsplus{handle: &h, Logger: mccdaq.NopLogger}
+	ai := AnalogInput{
+		DAQ:          &daq,
+		Frequency:    1000,
+		TransferMode: BlockTransfer,
+		Logger:       mccdaq.NopLogger,
+	}
+	ai.EnableChannel(0)
+	ai.EnableChannel(1)
+	r := newResilient("01AF3FAE", &daq, &ai, open)
+	r.RetryInterval = time.Millisecond
+	return r, &h
+}
+
+func TestResilientReconnect(t *testing.T) {
+	newHandle := fakeHandle{}
+	newDAQ := USB1608fsplus{handle: &newHandle, Logger: mccdaq.NopLogger}
+	attempts := 0
+	open := func(ctx context.Context) (*USB1608fsplus, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, mccdaq.ErrDeviceNotFound
+		}
+		return &newDAQ, nil
+	}
+	r, oldHandle := newTestResilient(open)
+	var gaps []Gap
+	r.OnGap = func(gap Gap) { gaps = append(gaps, gap) }
+	if err := r.StartScan(100); err != nil {
+		t.Fatalf("StartScan: %v", err)
+	}
+	p := make([]byte, 2*maxBulkTransferPacketSize)
+	if _, err := r.Read(p); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	oldHandle.bulkErr = usberr.Wrap(usberr.NoDevice)
+	n, err := r.Read(p)
+	if err != nil || n != 0 {
+		t.Fatalf("Expected 0 bytes and no error after reconnect, got %d and %v", n, err)
+	}
+	if len(gaps) != 1 {
+		t.Fatalf("Expected one gap, got %d", len(gaps))
+	}
+	// 128 bytes with two channels enabled is 32 complete scans.
+	if gaps[0].Scans != 32 || gaps[0].Attempts != 3 {
+		t.Errorf("Expected gap after 32 scans and 3 attempts, got %+v", gaps[0])
+	}
+	if !errors.Is(gaps[0].Err, mccdaq.ErrDisconnected) {
+		t.Errorf("Expected gap caused by ErrDisconnected, got %v", gaps[0].Err)
+	}
+	if r.DAQ() != &newDAQ {
+		t.Errorf("Expected the reopened DAQ to be in use")
+	}
+	want := []command{
+		commandAnalogConfig,
+		commandAnalogStopScan,
+		commandAnalogClearBuffer,
+		commandAnalogStartScan,
+	}
+	if !reflect.DeepEqual(newHandle.commands, want) {
+		t.Errorf("Expected commands %v, got %v", want, newHandle.commands)
+	}
+	if n, err := r.Read(p); err != nil || n != len(p) {
+		t.Errorf("Expected to read %d bytes after reconnect, got %d and %v", len(p), n, err)
+	}
+}
+
+func TestResilientMaxAttempts(t *testing.T) {
+	open := func(ctx context.Context) (*USB1608fsplus, error) {
+		return nil, mccdaq.ErrDeviceNotFound
+	}
+	r, h := newTestResilient(open)
+	r.MaxAttempts = 2
+	r.OnGap = func(gap Gap) { t.Errorf("Unexpected gap %+v", gap) }
+	if err := r.StartScan(0); err != nil {
+		t.Fatalf("StartScan: %v", err)
+	}
+	h.bulkErr = usberr.Wrap(usberr.NoDevice)
+	_, err := r.Read(make([]byte, maxBulkTransferPacketSize))
+	if !errors.Is(err, mccdaq.ErrDisconnected) {
+		t.Errorf("Expected ErrDisconnected, got %v", err)
+	}
+}
+
+func TestResilientReadOtherErrors(t *testing.T) {
+	r, h := newTestResilient(func(ctx context.Context) (*USB1608fsplus, error) {
+		t.Fatalf("Unexpected reconnect")
+		return nil, nil
+	})
+	h.bulkErr = usberr.Wrap(usberr.Pipe)
+	_, err := r.Read(make([]byte, maxBulkTransferPacketSize))
+	if !errors.Is(err, mccdaq.ErrStall) {
+		t.Errorf("Expected ErrStall, got %v", err)
+	}
+}
+
+func TestIsDisconnect(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected bool
+	}{
+		{usberr.Wrap(usberr.NoDevice), true},
+		{usberr.Wrap(usberr.IO), true},
+		{usberr.Wrap(usberr.NotFound), true},
+		{fmt.Errorf("error reading: %w", usberr.Wrap(usberr.IO)), true},
+		{usberr.Wrap(usberr.Pipe), false},
+		{usberr.Wrap(usberr.Timeout), false},
+		{mccdaq.ErrClosed, false},
+		{nil, false},
+	}
+	for _, testCase := range testCases {
+		if got := IsDisconnect(testCase.err); got != testCase.expected {
+			t.Errorf("Expected %t for %v, got %t", testCase.expected, testCase.err, got)
+		}
+	}
+}
+
+func TestResilientStream(t *testing.T) {
+	newHandle := fakeHandle{}
+	newDAQ := USB1608fsplus{handle: &newHandle, Logger: mccdaq.NopLogger}
+	open := func(ctx context.Context) (*USB1608fsplus, error) {
+		return &newDAQ, nil
+	}
+	r, oldHandle := newTestResilient(open)
+	// A DAQ resetting often fails with an I/O error before it disappears.
+	oldHandle.bulkErr = usberr.Wrap(usberr.IO)
+	gaps := make(chan Gap, 1)
+	r.OnGap = func(gap Gap) { gaps <- gap }
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s, err := r.StartStream(ctx, WithScansPerFrame(32))
+	if err != nil {
+		t.Fatalf("StartStream: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		frame, ok := <-s.Frames()
+		if !ok {
+			t.Fatalf("Expected the stream to survive the reconnect, got %v", s.Err())
+		}
+		if frame.NumScans() != 32 {
+			t.Errorf("Expected 32 scans in frame %d, got %d", i, frame.NumScans())
+		}
+	}
+	if err := s.Stop(); err != nil {
+		t.Errorf("Expected no error stopping the stream, got %v", err)
+	}
+	select {
+	case gap := <-gaps:
+		if gap.Scans != 0 || gap.Attempts != 1 {
+			t.Errorf("Expected gap before any scans after 1 attempt, got %+v", gap)
+		}
+	default:
+		t.Errorf("Expected a gap")
+	}
+	if r.DAQ() != &newDAQ {
+		t.Errorf("Expected the reopened DAQ to be in use")
+	}
+	if atomic.LoadInt32(&oldHandle.closes) != 1 {
+		t.Errorf("Expected the disconnected DAQ to be closed once, got %d", oldHandle.closes)
+	}
+	want := commandAnalogStopScan
+	if got := newHandle.commands[len(newHandle.commands)-1]; got != want {
+		t.Errorf("Expected the stream to end with %v, got %v", want, got)
+	}
+}
+
+func TestResilientCloseWhileReconnecting(t *testing.T) {
+	newHandle := fakeHandle{}
+	newDAQ := USB1608fsplus{handle: &newHandle, Logger: mccdaq.NopLogger}
+	opening := make(chan struct{})
+	closed := make(chan struct{})
+	open := func(ctx context.Context) (*USB1608fsplus, error) {
+		close(opening)
+		<-closed
+		return &newDAQ, nil
+	}
+	r, oldHandle := newTestResilient(open)
+	if err := r.StartScan(0); err != nil {
+		t.Fatalf("StartScan: %v", err)
+	}
+	oldHandle.bulkErr = usberr.Wrap(usberr.NoDevice)
+	go func() {
+		<-opening
+		if err := r.Close(); err != nil {
+			t.Errorf("Expected no error closing, got %v", err)
+		}
+		close(closed)
+	}()
+	_, err := r.Read(make([]byte, maxBulkTransferPacketSize))
+	if !errors.Is(err, mccdaq.ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+	if r.DAQ() == &newDAQ {
+		t.Errorf("Expected the reopened DAQ not to be used after Close")
+	}
+	if newHandle.closes != 1 {
+		t.Errorf("Expected the reopened DAQ to be closed, got %d closes", newHandle.closes)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package usb1608fsplus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// Record is one triggered record of a segmented acquisition.
+type Record struct {
+	Number int          // Number of the record, counting from zero
+	Time   time.Time    // Time of the trigger, which starts the first scan
+	Frame  mccdaq.Frame // Scans of the record, indexed from the trigger
+}
+
+// AcquireSegments captures the given number of records of scansPerRecord
+// scans each using the external trigger set by Trigger. The scan ranges are
+// written once, and then for each record the DAQ is armed with a scan of
+// scansPerRecord scans, which starts when the trigger condition is met. Once
+// the record has been read, it's passed to handle, and the DAQ is rearmed for
+// the next record. Any trigger that arrives while the DAQ is being rearmed is
+// missed.
+//
+// Each record is stamped with the time of its trigger from the scan's
+// timebase, which is anchored when the record's first data arrives. The wait
+// for each trigger isn't limited by the DAQ's transfer timeout: if
+// triggerTimeout is zero, AcquireSegments waits until the context is done, and
+// otherwise, if a record's first data doesn't arrive within triggerTimeout of
+// arming the DAQ, AcquireSegments fails with mccdaq.ErrTimeout. AcquireSegments
+// also stops if handle returns an error, which it returns, or once the context
+// is done. Whatever the error, the scan is stopped before returning.
+func (ai *AnalogInput) AcquireSegments(
+	ctx context.Context,
+	records, scansPerRecord int,
+	triggerTimeout time.Duration,
+	handle func(Record) error,
+) error {
+	if ai.Trigger == NoExternalTrigger {
+		return fmt.Errorf("segmented acquisition needs an external trigger: %w", mccdaq.ErrNotSupported)
+	}
+	if records < 1 || scansPerRecord < 1 {
+		return fmt.Errorf("bad segmented acquisition of %d records of %d scans", records, scansPerRecord)
+	}
+	if _, err := ai.Plan(scansPerRecord); err != nil {
+		return err
+	}
+	if err := ai.SetScanRanges(); err != nil {
+		return err
+	}
+	scanBytes := ai.NumEnabledChannels() * bytesPerWord
+	data := make([]byte, scansPerRecord*scanBytes)
+	for number := 0; number < records; number++ {
+		if err := ai.StartScanContext(ctx, scansPerRecord); err != nil {
+			ai.StopScan()
+			return fmt.Errorf("error arming record %d: %w", number, err)
+		}
+		n, err := ai.readRecord(ctx, data, scanBytes, triggerTimeout)
+		if err != nil {
+			ai.StopScan()
+			if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+				return fmt.Errorf("no trigger for record %d within %s: %w",
+					number, triggerTimeout, mccdaq.ErrTimeout)
+			}
+			return fmt.Errorf("error reading record %d: %w", number, err)
+		}
+		tb, _ := ai.Timebase()
+		frame := ai.decodeFrame(data[:n], 0)
+		frame.Timebase = tb
+		record := Record{Number: number, Time: tb.Time(0), Frame: frame}
+		ai.logger().Debug("Acquired record",
+			"serial_number", ai.serialNumber, "record", number, "scans", frame.NumScans())
+		if err := handle(record); err != nil {
+			ai.StopScan()
+			return err
+		}
+	}
+	return nil
+}
+
+// readRecord reads one record of a segmented acquisition into data, waiting
+// at most triggerTimeout, if nonzero, for the first scan to arrive, and
+// returns the number of bytes read. The first scan is read with a cancelable
+// context, so that the DAQ is polled for the first packet, and any transfer
+// timeouts while waiting are retried, so that only triggerTimeout and the
+// context limit the wait.
+func (ai *AnalogInput) readRecord(
+	ctx context.Context, data []byte, scanBytes int, triggerTimeout time.Duration,
+) (int, error) {
+	var triggerCtx context.Context
+	var cancel context.CancelFunc
+	if triggerTimeout > 0 {
+		triggerCtx, cancel = context.WithTimeout(ctx, triggerTimeout)
+	} else {
+		triggerCtx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+	var n int
+	var err error
+	for {
+		n, err = ai.ReadContext(triggerCtx, data[:scanBytes])
+		if n > 0 || !errors.Is(err, mccdaq.ErrTimeout) {
+			break
+		}
+		if err := triggerCtx.Err(); err != nil {
+			return 0, err
+		}
+	}
+	if err != nil {
+		return n, err
+	}
+	for n < len(data) {
+		got, err := ai.ReadContext(ctx, data[n:])
+		n += got
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
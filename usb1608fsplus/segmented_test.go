@@ -0,0 +1,206 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package usb1608fsplus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+)
+
+func newSegmentedInput(f *FakeDAQer) *AnalogInput {
+	ai := AnalogInput{
+		DAQ:          f,
+		Frequency:    1000,
+		TransferMode: BlockTransfer,
+		Trigger:      RisingEdgeTrigger,
+	}
+	ai.EnableChannel(0)
+	ai.EnableChannel(1)
+	return &ai
+}
+
+func TestAcquireSegments(t *testing.T) {
+	defer func() { timeNow = time.Now }()
+	timeNow = fakeClock(time.Second)
+	// Three records of four scans of two channels.
+	f := FakeDAQer{StatusByte: byte(scanRunning)}
+	for record := 0; record < 3; record++ {
+		f.Records = append(f.Records, newScanData(8))
+	}
+	ai := newSegmentedInput(&f)
+	var records []Record
+	err := ai.AcquireSegments(context.Background(), 3, 4, time.Second, func(r Record) error {
+		records = append(records, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+	starts := 0
+	for _, cmd := range f.Commands {
+		if cmd == commandAnalogStartScan {
+			starts++
+		}
+	}
+	if starts != 3 {
+		t.Errorf("Expected 3 scans started, got %d", starts)
+	}
+	for i, r := range records {
+		if r.Number != i {
+			t.Errorf("Expected record number %d, got %d", i, r.Number)
+		}
+		if r.Frame.NumScans() != 4 {
+			t.Errorf("Expected 4 scans in record %d, got %d", i, r.Frame.NumScans())
+		}
+		if raw := r.Frame.Raw[1][3]; raw != 7 {
+			t.Errorf("Expected last raw value 7 in record %d, got %d", i, raw)
+		}
+		// Each record's timebase is anchored at its trigger.
+		want := r.Frame.Timebase.Anchor
+		if !r.Time.Equal(want) {
+			t.Errorf("Expected record %d time %v, got %v", i, want, r.Time)
+		}
+		if i > 0 && !r.Time.After(records[i-1].Time) {
+			t.Errorf("Expected record %d after record %d", i, i-1)
+		}
+	}
+}
+
+func TestAcquireSegmentsTriggerTimeout(t *testing.T) {
+	f := FakeDAQer{StatusByte: byte(scanRunning)}
+	f.Records = [][]byte{newScanData(8), {}}
+	ai := newSegmentedInput(&f)
+	records := 0
+	err := ai.AcquireSegments(context.Background(), 3, 4, 10*time.Millisecond, func(r Record) error {
+		records++
+		return nil
+	})
+	if !errors.Is(err, mccdaq.ErrTimeout) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrTimeout, err)
+	}
+	if records != 1 {
+		t.Errorf("Expected 1 record before the timeout, got %d", records)
+	}
+	if !stoppedAfterStart(f.Commands) {
+		t.Errorf("Expected scan stopped after timeout, got commands %v", f.Commands)
+	}
+}
+
+// stoppedAfterStart reports whether the last scan started was then stopped.
+func stoppedAfterStart(commands []command) bool {
+	stopped := false
+	for _, cmd := range commands {
+		switch cmd {
+		case commandAnalogStartScan:
+			stopped = false
+		case commandAnalogStopScan:
+			stopped = true
+		}
+	}
+	return stopped
+}
+
+// timeoutDAQer is a FakeDAQer whose reads, until the trigger arrives after the
+// given number of waits, each fail with mccdaq.ErrTimeout after timeout, like
+// a DAQ whose bulk transfers time out while waiting for a trigger.
+type timeoutDAQer struct {
+	FakeDAQer
+	timeout time.Duration
+	waits   int
+}
+
+func (f *timeoutDAQer) ReadContext(ctx context.Context, p []byte) (int, error) {
+	if f.waits == 0 {
+		return f.Read(p)
+	}
+	select {
+	case <-time.After(f.timeout):
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+	f.waits--
+	return 0, fmt.Errorf("bulk transfer timed out: %w", mccdaq.ErrTimeout)
+}
+
+func TestAcquireSegmentsSlowTrigger(t *testing.T) {
+	testCases := []struct {
+		name           string
+		timeout        time.Duration
+		waits          int
+		triggerTimeout time.Duration
+		expected       error
+	}{
+		{"trigger after the transfer timeout", 2 * time.Second, 1, 0, nil},
+		{"trigger within the trigger timeout", 10 * time.Millisecond, 5, time.Second, nil},
+		{"no trigger", 10 * time.Millisecond, 1000, 50 * time.Millisecond, mccdaq.ErrTimeout},
+	}
+	for _, tc := range testCases {
+		f := timeoutDAQer{timeout: tc.timeout, waits: tc.waits}
+		f.StatusByte = byte(scanRunning)
+		f.Records = [][]byte{newScanData(8)}
+		ai := AnalogInput{
+			DAQ:          &f,
+			Frequency:    1000,
+			TransferMode: BlockTransfer,
+			Trigger:      RisingEdgeTrigger,
+		}
+		ai.EnableChannel(0)
+		ai.EnableChannel(1)
+		records := 0
+		err := ai.AcquireSegments(context.Background(), 1, 4, tc.triggerTimeout, func(Record) error {
+			records++
+			return nil
+		})
+		if !errors.Is(err, tc.expected) {
+			t.Errorf("%s: expected error %v, got %v", tc.name, tc.expected, err)
+		}
+		if tc.expected == nil && records != 1 {
+			t.Errorf("%s: expected 1 record, got %d", tc.name, records)
+		}
+		if tc.expected != nil && !stoppedAfterStart(f.Commands) {
+			t.Errorf("%s: expected scan stopped, got commands %v", tc.name, f.Commands)
+		}
+	}
+}
+
+func TestAcquireSegmentsErrors(t *testing.T) {
+	handle := func(Record) error { return nil }
+	f := FakeDAQer{}
+	ai := newSegmentedInput(&f)
+	ai.Trigger = NoExternalTrigger
+	err := ai.AcquireSegments(context.Background(), 1, 4, 0, handle)
+	if !errors.Is(err, mccdaq.ErrNotSupported) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrNotSupported, err)
+	}
+	ai.Trigger = RisingEdgeTrigger
+	if err := ai.AcquireSegments(context.Background(), 0, 4, 0, handle); err == nil {
+		t.Error("Expected error for no records")
+	}
+	stop := errors.New("stop")
+	f.Records = [][]byte{newScanData(8), newScanData(8)}
+	records := 0
+	err = ai.AcquireSegments(context.Background(), 2, 4, 0, func(Record) error {
+		records++
+		return stop
+	})
+	if err != stop {
+		t.Errorf("Expected error %v, got %v", stop, err)
+	}
+	if records != 1 {
+		t.Errorf("Expected 1 record, got %d", records)
+	}
+	if !stoppedAfterStart(f.Commands) {
+		t.Errorf("Expected scan stopped after the handler's error, got commands %v", f.Commands)
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package usb1608fsplus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gotmc/mccdaq"
+)
+
+const (
+	defaultStreamBufferDepth = 16
+	// defaultFramesPerSecond sets the default number of scans per frame.
+	defaultFramesPerSecond = 10
+)
+
+// Backpressure determines what a Stream does with a new frame when the
+// consumer hasn't kept up and the frame buffer is full.
+type Backpressure int
+
+// Available backpressure policies.
+const (
+	// Block waits for the consumer to receive a frame. While waiting, the DAQ
+	// keeps acquiring into its FIFO, which overruns if the consumer falls too
+	// far behind.
+	Block Backpressure = iota
+	// DropOldest discards the oldest buffered frame to make room for the new
+	// frame.
+	DropOldest
+	// DropNewest discards the new frame.
+	DropNewest
+)
+
+var backpressures = map[Backpressure]string{
+	Block:      "block",
+	DropOldest: "drop oldest",
+	DropNewest: "drop newest",
+}
+
+// String implements the Stringer interface for Backpressure.
+func (b Backpressure) String() string {
+	return backpressures[b]
+}
+
+// scanner starts, reads, and stops the analog input scan of a Stream. It's
+// implemented by AnalogInput and by Resilient, which reconnects the DAQ
+// without ending the stream.
+type scanner interface {
+	StartScanContext(ctx context.Context, numScans int) error
+	ReadContext(ctx context.Context, p []byte) (int, error)
+	StopScan() error
+}
+
+// Stream continuously reads an analog input scan in a goroutine and delivers
+// the decoded scans as frames on a channel. Create a Stream using
+// AnalogInput.StartStream or Resilient.StartStream.
+type Stream struct {
+	dropped       uint64 // Accessed atomically, so keep 64-bit aligned
+	ai            *AnalogInput
+	scan          scanner
+	frames        chan mccdaq.Frame
+	scansPerFrame int
+	bufferDepth   int
+	backpressure  Backpressure
+	cancel        context.CancelFunc
+	done          chan struct{}
+	stopOnce      sync.Once
+	err           error
+}
+
+// StreamOption configures a Stream.
+type StreamOption func(*Stream)
+
+// WithScansPerFrame sets the number of scans in each frame, which is rounded
+// up so that each frame is a whole number of 64-byte bulk packets. By default,
+// a frame holds a tenth of a second of scans.
+func WithScansPerFrame(n int) StreamOption {
+	return func(s *Stream) {
+		s.scansPerFrame = n
+	}
+}
+
+// WithBufferDepth sets the number of frames buffered for the consumer. The
+// default is 16 frames.
+func WithBufferDepth(n int) StreamOption {
+	return func(s *Stream) {
+		s.bufferDepth = n
+	}
+}
+
+// WithBackpressure sets what the Stream does when the frame buffer is full.
+// The default is Block.
+func WithBackpressure(b Backpressure) StreamOption {
+	return func(s *Stream) {
+		s.backpressure = b
+	}
+}
+
+// StartStream writes the scan ranges, starts a continuous analog input scan,
+// and reads the scan in a goroutine until the Stream is stopped, the context
+// is done, or the read fails. Call Stop to end the stream and stop the scan.
+//
+// Each frame carries the scan's timebase as of when the frame was read, so
+// that frame.Time gives the time of each scan in the frame.
+//
+// With the OverrunRestartWithGaps policy, the stream carries on after an
+// overrun: the frame before the gap may hold fewer scans than the others, and
+// the frame after the gap reports the scans lost in its LostScans field. The
+// frame indices skip the lost scans, so they stay in step with the timebase.
+func (ai *AnalogInput) StartStream(ctx context.Context, opts ...StreamOption) (*Stream, error) {
+	s, err := ai.newStream(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := ai.SetScanRanges(); err != nil {
+		return nil, err
+	}
+	return s.start(ctx, ai)
+}
+
+// newStream checks the analog input and the stream options, and returns a
+// Stream that's ready to start.
+func (ai *AnalogInput) newStream(opts ...StreamOption) (*Stream, error) {
+	numChannels := ai.NumEnabledChannels()
+	if numChannels == 0 {
+		return nil, fmt.Errorf("no channels enabled for stream: %w", mccdaq.ErrInvalidChannel)
+	}
+	s := Stream{
+		ai:           ai,
+		bufferDepth:  defaultStreamBufferDepth,
+		backpressure: Block,
+		done:         make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	if s.bufferDepth < 0 || (s.backpressure != Block && s.bufferDepth == 0) {
+		return nil, fmt.Errorf("bad stream buffer depth %d for backpressure policy %s",
+			s.bufferDepth, s.backpressure)
+	}
+	if _, ok := backpressures[s.backpressure]; !ok {
+		return nil, fmt.Errorf("bad backpressure policy %d: %w", s.backpressure, mccdaq.ErrNotSupported)
+	}
+	if s.scansPerFrame <= 0 {
+		s.scansPerFrame = int(ai.Frequency / defaultFramesPerSecond)
+	}
+	s.scansPerFrame = packetAlignedScans(s.scansPerFrame, numChannels)
+	return &s, nil
+}
+
+// start starts a continuous scan using the scanner and reads it in a
+// goroutine.
+func (s *Stream) start(ctx context.Context, scan scanner) (*Stream, error) {
+	if err := scan.StartScanContext(ctx, 0); err != nil {
+		return nil, err
+	}
+	s.scan = scan
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.frames = make(chan mccdaq.Frame, s.bufferDepth)
+	go s.run(ctx)
+	return s, nil
+}
+
+// packetAlignedScans rounds the number of scans up to the smallest nonzero
+// number of scans that fills a whole number of bulk packets.
+func packetAlignedScans(scans, numChannels int) int {
+	scanBytes := numChannels * bytesPerWord
+	step := maxBulkTransferPacketSize / gcd(maxBulkTransferPacketSize, scanBytes)
+	if scans < step {
+		return step
+	}
+	return (scans + step - 1) / step * step
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// Frames returns the channel on which the decoded frames are delivered. The
+// channel is closed once the stream ends, after which Err reports why.
+func (s *Stream) Frames() <-chan mccdaq.Frame {
+	return s.frames
+}
+
+// ScansPerFrame returns the number of scans in each frame.
+func (s *Stream) ScansPerFrame() int {
+	return s.scansPerFrame
+}
+
+// Dropped returns the number of frames discarded by the backpressure policy.
+func (s *Stream) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Err returns the error that ended the stream, such as a failed read or the
+// context's deadline. Err returns nil while the stream is running, and once
+// the frames channel is closed if the stream was ended by Stop or by canceling
+// the context, such as on an interrupt.
+func (s *Stream) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// Stop ends the stream, stops the scan, and waits for the reading goroutine to
+// exit, after which the frames channel is closed. Any frames still buffered
+// can be received after Stop returns. Stop returns the error that ended the
+// stream, if any, and may be called more than once.
+func (s *Stream) Stop() error {
+	s.stopOnce.Do(func() {
+		s.cancel()
+	})
+	<-s.done
+	return s.err
+}
+
+// run reads frames until the context is done or a read fails.
+func (s *Stream) run(ctx context.Context) {
+	defer close(s.done)
+	defer close(s.frames)
+	numChannels := s.ai.NumEnabledChannels()
+	data := make([]byte, s.scansPerFrame*numChannels*bytesPerWord)
+	var index, lost uint64
+	for {
+		lostBefore := s.ai.scansLost
+		n, err := s.scan.ReadContext(ctx, data)
+		var gap *mccdaq.OverrunError
+		if err != nil && !(errors.As(err, &gap) && gap.Restarted) {
+			s.end(err)
+			return
+		}
+		if n > 0 {
+			frame := s.ai.decodeFrame(data[:n], index)
+			frame.LostScans = lost
+			frame.Timebase, _ = s.ai.Timebase()
+			index += uint64(frame.NumScans())
+			lost = 0
+			if !s.send(ctx, frame) {
+				s.end(ctx.Err())
+				return
+			}
+		}
+		// The scans lost to an overrun or a reconnect follow the data read.
+		missing := s.ai.scansLost - lostBefore
+		index += missing
+		lost += missing
+	}
+}
+
+// end stops the scan and records the error that ended the stream, unless the
+// context was canceled, either by Stop or by the caller, which is a clean end.
+func (s *Stream) end(err error) {
+	s.scan.StopScan()
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	s.err = err
+}
+
+// send delivers the frame according to the backpressure policy. It returns
+// false if the context is done while blocked.
+func (s *Stream) send(ctx context.Context, frame mccdaq.Frame) bool {
+	switch s.backpressure {
+	case DropNewest:
+		select {
+		case s.frames <- frame:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	case DropOldest:
+		for {
+			select {
+			case s.frames <- frame:
+				return true
+			default:
+			}
+			select {
+			case <-s.frames:
+				atomic.AddUint64(&s.dropped, 1)
+			default:
+			}
+		}
+	default:
+		select {
+		case s.frames <- frame:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// decodeFrame decodes the binary scan data for the enabled channels into a
+// frame whose first scan has the given index.
+func (ai *AnalogInput) decodeFrame(data []byte, index uint64) mccdaq.Frame {
+	channels := ai.enabledChannelNumbers()
+	scans := len(data) / (len(channels) * bytesPerWord)
+	frame := mccdaq.Frame{
+		Index:    index,
+		Channels: channels,
+		Raw:      make([][]uint16, len(channels)),
+		Volts:    make([][]float64, len(channels)),
+	}
+	for i, ch := range channels {
+		table := ai.voltageTable64(ch, true)
+		raw := make([]uint16, scans)
+		volts := make([]float64, scans)
+		for scan := 0; scan < scans; scan++ {
+			firstByte := (scan*len(channels) + i) * bytesPerWord
+			raw[scan] = DecodeWord(data[firstByte : firstByte+bytesPerWord])
+			volts[scan] = table[raw[scan]]
+		}
+		frame.Raw[i] = raw
+		frame.Volts[i] = volts
+	}
+	return frame
+}
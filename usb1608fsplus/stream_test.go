@@ -0,0 +1,240 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package usb1608fsplus
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// newStreamTestInput returns an analog input with channels 0 and 2 enabled,
+// unity gain, and the given number of scans of data, where channel 0 reads the
+// scan number and channel 2 reads midscale (0 V).
+func newStreamTestInput(scans int) (*AnalogInput, *FakeDAQer) {
+	f := FakeDAQer{StatusByte: byte(scanRunning)}
+	for scan := 0; scan < scans; scan++ {
+		f.Data = append(f.Data, EncodeWord(uint16(scan))...)
+		f.Data = append(f.Data, EncodeWord(0x8000)...)
+	}
+	ai := AnalogInput{
+		DAQ:          &f,
+		Frequency:    1000,
+		TransferMode: BlockTransfer,
+		Logger:       mccdaq.NopLogger,
+	}
+	for _, ch := range []int{0, 2} {
+		ai.Channels[ch].Enabled = true
+		ai.Channels[ch].Range = Range10V
+		ai.Channels[ch].Slopes = Slopes{Range10V: 1.0}
+		ai.Channels[ch].Intercepts = Intercepts{Range10V: 0.0}
+	}
+	return &ai, &f
+}
+
+func TestStream(t *testing.T) {
+	ai, f := newStreamTestInput(64)
+	s, err := ai.StartStream(context.Background(), WithScansPerFrame(32))
+	if err != nil {
+		t.Fatalf("StartStream: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		frame := <-s.Frames()
+		if frame.Index != uint64(32*i) {
+			t.Errorf("Expected frame index %d, got %d", 32*i, frame.Index)
+		}
+		if !reflect.DeepEqual(frame.Channels, []int{0, 2}) {
+			t.Errorf("Expected channels [0 2], got %v", frame.Channels)
+		}
+		if frame.NumScans() != 32 {
+			t.Fatalf("Expected 32 scans, got %d", frame.NumScans())
+		}
+		if frame.Timebase.Period != time.Millisecond {
+			t.Errorf("Expected 1 ms scan period, got %v", frame.Timebase.Period)
+		}
+		for scan := 0; scan < 32; scan++ {
+			if frame.Raw[0][scan] != uint16(32*i+scan) {
+				t.Errorf("Expected raw %d, got %d", 32*i+scan, frame.Raw[0][scan])
+			}
+			if frame.Raw[1][scan] != 0x8000 || frame.Volts[1][scan] != 0.0 {
+				t.Errorf("Expected midscale 0 V, got %#x and %v V",
+					frame.Raw[1][scan], frame.Volts[1][scan])
+			}
+		}
+	}
+	if err := s.Stop(); err != nil {
+		t.Errorf("Expected no error from Stop, got %v", err)
+	}
+	if _, ok := <-s.Frames(); ok {
+		t.Errorf("Expected frames channel to be closed")
+	}
+	if f.Commands[0] != commandAnalogConfig || f.Commands[3] != commandAnalogStartScan {
+		t.Errorf("Expected scan ranges written and scan started, got %v", f.Commands)
+	}
+	if f.Commands[len(f.Commands)-1] != commandAnalogStopScan {
+		t.Errorf("Expected scan stopped, got %v", f.Commands)
+	}
+}
+
+func TestStreamBackpressure(t *testing.T) {
+	testCases := []struct {
+		backpressure Backpressure
+		firstIndex   uint64
+	}{
+		{DropNewest, 0},
+		{DropOldest, 64},
+	}
+	for _, tc := range testCases {
+		ai, _ := newStreamTestInput(96)
+		s, err := ai.StartStream(context.Background(),
+			WithScansPerFrame(32), WithBufferDepth(1), WithBackpressure(tc.backpressure))
+		if err != nil {
+			t.Fatalf("StartStream: %v", err)
+		}
+		deadline := time.Now().Add(time.Second)
+		for s.Dropped() < 2 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if s.Dropped() != 2 {
+			t.Errorf("%s: expected 2 dropped frames, got %d", tc.backpressure, s.Dropped())
+		}
+		frame := <-s.Frames()
+		if frame.Index != tc.firstIndex {
+			t.Errorf("%s: expected frame index %d, got %d",
+				tc.backpressure, tc.firstIndex, frame.Index)
+		}
+		s.Stop()
+	}
+}
+
+func TestStreamReadError(t *testing.T) {
+	ai, f := newStreamTestInput(32)
+	f.StatusByte = byte(scanRunning | scanOverrun)
+	s, err := ai.StartStream(context.Background(), WithScansPerFrame(32))
+	if err != nil {
+		t.Fatalf("StartStream: %v", err)
+	}
+	for range s.Frames() {
+	}
+	if !errors.Is(s.Err(), mccdaq.ErrOverrun) {
+		t.Errorf("Expected ErrOverrun, got %v", s.Err())
+	}
+	if !errors.Is(s.Stop(), mccdaq.ErrOverrun) {
+		t.Errorf("Expected Stop to return ErrOverrun")
+	}
+}
+
+func TestStreamOverrunGap(t *testing.T) {
+	defer func() { timeNow = time.Now }()
+	timeNow = fakeClock(50 * time.Millisecond)
+	ai, f := newStreamTestInput(64)
+	f.Overruns = 1
+	ai.OverrunPolicy = OverrunRestartWithGaps
+	s, err := ai.StartStream(context.Background(), WithScansPerFrame(32))
+	if err != nil {
+		t.Fatalf("StartStream: %v", err)
+	}
+	// The clock is read at the start, when the data arrives, and at the
+	// restart, so 100 scans are expected in the 100 ms before the restart, and
+	// 32 were read.
+	testCases := []struct {
+		index uint64
+		lost  uint64
+		first uint16
+	}{
+		{0, 0, 0},
+		{100, 68, 32},
+	}
+	for _, tc := range testCases {
+		frame := <-s.Frames()
+		if frame.Index != tc.index || frame.LostScans != tc.lost {
+			t.Errorf("Expected frame index %d with %d lost scans, got %d with %d",
+				tc.index, tc.lost, frame.Index, frame.LostScans)
+		}
+		if frame.NumScans() != 32 || frame.Raw[0][0] != tc.first {
+			t.Errorf("Expected 32 scans starting at %d, got %d", tc.first, frame.NumScans())
+		}
+	}
+	if err := s.Stop(); err != nil {
+		t.Errorf("Expected no error from Stop, got %v", err)
+	}
+}
+
+func TestStreamContextDone(t *testing.T) {
+	ai, _ := newStreamTestInput(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	s, err := ai.StartStream(ctx)
+	if err != nil {
+		t.Fatalf("StartStream: %v", err)
+	}
+	cancel()
+	for range s.Frames() {
+	}
+	// Canceling the context, such as on an interrupt, ends the stream cleanly.
+	if err := s.Err(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := s.Stop(); err != nil {
+		t.Errorf("Expected no error from Stop, got %v", err)
+	}
+}
+
+func TestStreamDeadline(t *testing.T) {
+	ai, _ := newStreamTestInput(0)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	s, err := ai.StartStream(ctx)
+	if err != nil {
+		t.Fatalf("StartStream: %v", err)
+	}
+	for range s.Frames() {
+	}
+	if s.Err() != context.DeadlineExceeded {
+		t.Errorf("Expected %v, got %v", context.DeadlineExceeded, s.Err())
+	}
+}
+
+func TestStreamBadOptions(t *testing.T) {
+	ai, _ := newStreamTestInput(0)
+	if _, err := ai.StartStream(context.Background(), WithBackpressure(Backpressure(9))); !errors.Is(err, mccdaq.ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+	if _, err := ai.StartStream(context.Background(), WithBackpressure(DropOldest), WithBufferDepth(0)); err == nil {
+		t.Errorf("Expected error for unbuffered stream that drops frames")
+	}
+	if _, err := (&AnalogInput{DAQ: &FakeDAQer{}}).StartStream(context.Background()); !errors.Is(err, mccdaq.ErrInvalidChannel) {
+		t.Errorf("Expected ErrInvalidChannel, got %v", err)
+	}
+}
+
+func TestPacketAlignedScans(t *testing.T) {
+	testCases := []struct {
+		scans       int
+		numChannels int
+		expected    int
+	}{
+		{0, 1, 32},
+		{1, 8, 4},
+		{100, 1, 128},
+		{100, 3, 128},
+		{100, 8, 100},
+		{1000, 6, 1008},
+	}
+	for _, tc := range testCases {
+		computed := packetAlignedScans(tc.scans, tc.numChannels)
+		if computed != tc.expected {
+			t.Errorf("%d scans of %d channels: expected %d, got %d",
+				tc.scans, tc.numChannels, tc.expected, computed)
+		}
+		if (computed*tc.numChannels*bytesPerWord)%maxBulkTransferPacketSize != 0 {
+			t.Errorf("%d scans of %d channels isn't packet aligned", computed, tc.numChannels)
+		}
+	}
+}
@@ -6,13 +6,16 @@
 package usb20x
 
 import (
+	"context"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"math"
+	"sync/atomic"
+	"time"
 
-	"github.com/gotmc/libusb"
+	"github.com/gotmc/mccdaq"
 )
 
 const (
@@ -31,14 +34,18 @@ var maxFrequency = map[int]float64{
 // AnalogInput models the analog inputs for the DAQ.
 type AnalogInput struct {
 	DAQer             `json:"-"`
-	Frequency         float64      `json:"freq"`
-	TransferMode      TransferMode `json:"block_transfer"`
-	Trigger           TriggerType  `json:"trigger"`
-	UseExternalPacer  bool         `json:"ext_pacer"`
-	OutputPacerOnSync bool         `json:"output_sync"`
-	DebugMode         bool         `json:"debug_mode"`
-	Stall             Stall        `json:"stall_overrun"`
-	Channels          Channels     `json:"channels"`
+	Frequency         float64       `json:"freq"`
+	TransferMode      TransferMode  `json:"block_transfer"`
+	Trigger           TriggerType   `json:"trigger"`
+	UseExternalPacer  bool          `json:"ext_pacer"`
+	OutputPacerOnSync bool          `json:"output_sync"`
+	DebugMode         bool          `json:"debug_mode"`
+	Stall             Stall         `json:"stall_overrun"`
+	Channels          Channels      `json:"channels"`
+	Logger            mccdaq.Logger `json:"-"`
+	serialNumber      string
+	// reading is nonzero while a goroutine is reading the scan data.
+	reading int32
 }
 
 // Channel models an analog input for the DAQ.
@@ -224,7 +231,7 @@ func (vr *VoltageRange) Set(s string) error {
 	// Ensure the provided string matches one of the keys in the map
 	got, ok := InputRanges[s]
 	if !ok {
-		return fmt.Errorf("Invalid VoltageRange %q", s)
+		return fmt.Errorf("Invalid VoltageRange %q: %w", s, mccdaq.ErrInvalidRange)
 	}
 	// Set the voltage range to the value found in the map per the key
 	*vr = got
@@ -238,9 +245,15 @@ func (vr *VoltageRange) MarshalJSON() ([]byte, error) {
 
 // NewAnalogInput is used to create a new AnalogInput for the given DAQer.
 func (daq *usb20x) NewAnalogInput() (*AnalogInput, error) {
-	gainTable, err := daq.BuildGainTable()
+	return daq.NewAnalogInputContext(context.Background())
+}
+
+// NewAnalogInputContext creates a new AnalogInput like NewAnalogInput, but
+// stops reading the gain table from the DAQ once the context is done.
+func (daq *usb20x) NewAnalogInputContext(ctx context.Context) (*AnalogInput, error) {
+	gainTable, err := daq.BuildGainTableContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("Error reading gain table from DAQ: %s", err)
+		return nil, fmt.Errorf("Error reading gain table from DAQ: %w", err)
 	}
 	var channels [numChannels]Channel
 	for i := 0; i < len(channels); i++ {
@@ -258,6 +271,8 @@ func (daq *usb20x) NewAnalogInput() (*AnalogInput, error) {
 		DebugMode:         false,
 		Stall:             StallOnOverrun,
 		Channels:          channels,
+		Logger:            daq.Logger,
+		serialNumber:      daq.serialNumber,
 	}
 	return &analogInput, nil
 }
@@ -343,6 +358,12 @@ func (ai *AnalogInput) Options() byte {
    before further scan can be performed.
 */
 func (ai *AnalogInput) StartScan(numScans int) error {
+	return ai.StartScanContext(context.Background(), numScans)
+}
+
+// StartScanContext starts an analog input scan like StartScan, but doesn't
+// send any further commands to the DAQ once the context is done.
+func (ai *AnalogInput) StartScanContext(ctx context.Context, numScans int) error {
 	freq := ai.Frequency
 	if ai.UseExternalPacer {
 		freq = 0
@@ -353,17 +374,26 @@ func (ai *AnalogInput) StartScan(numScans int) error {
 		return fmt.Errorf("analog scan data is %d bytes long; should be 12 bytes long",
 			len(data))
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	err := ai.StopScan()
 	if err != nil {
-		return fmt.Errorf("Error stopping analog scan prior to starting a new scan %s", err)
+		return fmt.Errorf("Error stopping analog scan prior to starting a new scan %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 	err = ai.ClearScanBuffer()
 	if err != nil {
-		return fmt.Errorf("Error clearing buffer prior to starting a new scan %s", err)
+		return fmt.Errorf("Error clearing buffer prior to starting a new scan %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 	_, err = ai.SendCommandToDevice(commandAnalogStartScan, data)
 	if err != nil {
-		return fmt.Errorf("Error starting analog input scan %s", err)
+		return fmt.Errorf("Error starting analog input scan %w", err)
 	}
 	return nil
 }
@@ -381,6 +411,20 @@ func (ai *AnalogInput) NumEnabledChannels() int {
 
 // ReadScan reads the analog input data for the given number of scans
 func (ai *AnalogInput) ReadScan(numScans int) ([]byte, error) {
+	return ai.ReadScanContext(context.Background(), numScans)
+}
+
+// ReadScanContext reads the analog input data like ReadScan, but gives up once
+// the context is done. If the context is done before all the scans have been
+// read, the scan is stopped and the scan FIFO buffer cleared, so that the DAQ
+// isn't left acquiring data that nobody is going to read, and the context's
+// error is returned. Only one goroutine at a time may read the scan data; any
+// other concurrent read fails with mccdaq.ErrBusy.
+func (ai *AnalogInput) ReadScanContext(ctx context.Context, numScans int) ([]byte, error) {
+	if !atomic.CompareAndSwapInt32(&ai.reading, 0, 1) {
+		return nil, mccdaq.ErrBusy
+	}
+	defer atomic.StoreInt32(&ai.reading, 0)
 	bytesInWord := 2
 	wordsToRead := numScans * ai.NumEnabledChannels()
 	bytesToRead := wordsToRead * bytesInWord
@@ -390,31 +434,45 @@ func (ai *AnalogInput) ReadScan(numScans int) ([]byte, error) {
 	var data = make([]byte, bytesToRead)
 	if ai.TransferMode == ImmediateTransfer {
 		for i := 0; i < wordsToRead; i++ {
-			var word = make([]byte, bytesInWord)
-			bytesReceived, err := ai.Read(word)
+			word := data[i*bytesInWord : (i+1)*bytesInWord]
+			bytesReceived, err := ai.readData(ctx, word, i > 0)
 			if err != nil {
-				return data, fmt.Errorf("Problem with immediate scan %s", err)
+				if ctx.Err() != nil {
+					return data, ai.abortScan(ctx)
+				}
+				return data, fmt.Errorf("Problem with immediate scan %w", err)
 			}
 			if bytesReceived != bytesInWord {
-				return data, fmt.Errorf("Didn't transfer 2 bytes %s", err)
+				return data, fmt.Errorf("Didn't transfer 2 bytes")
 			}
-			data[i] = word[0]
-			data[i+1] = word[1]
 		}
 	} else if ai.TransferMode == BlockTransfer {
-		bytesReceived, err := ai.Read(data)
-		if err != nil {
-			return data, fmt.Errorf("Problem with bulk scan %s", err)
-		}
-		if bytesReceived != bytesToRead {
-			return data, fmt.Errorf("Didn't transfer %d bytes: %s", bytesToRead, err)
+		// Without a cancelable context, read everything in one bulk transfer.
+		// Otherwise, read in transfers that can be abandoned without losing
+		// data when the context is done.
+		for n := 0; n < bytesToRead; {
+			bytesInChunk := bytesToRead - n
+			if chunkSize := ai.transferSize(n > 0); ctx.Done() != nil && bytesInChunk > chunkSize {
+				bytesInChunk = chunkSize
+			}
+			bytesReceived, err := ai.readData(ctx, data[n:n+bytesInChunk], n > 0)
+			if err != nil {
+				if ctx.Err() != nil {
+					return data, ai.abortScan(ctx)
+				}
+				return data, fmt.Errorf("Problem with bulk scan %w", err)
+			}
+			if bytesReceived != bytesInChunk {
+				return data, fmt.Errorf("Didn't transfer %d bytes", bytesToRead)
+			}
+			n += bytesReceived
 		}
 	} else {
-		return data, fmt.Errorf("Bad transfer mode")
+		return data, fmt.Errorf("Bad transfer mode %d: %w", ai.TransferMode, mccdaq.ErrNotSupported)
 	}
 	status, err := ai.Status()
 	if err != nil {
-		return data, fmt.Errorf("Error getting status during analog bulk read %s", err)
+		return data, fmt.Errorf("Error getting status during analog bulk read %w", err)
 	}
 	// If bytesToRead is a multiple of wMaxPacketSize the device will send a zero
 	// byte packet.
@@ -423,12 +481,81 @@ func (ai *AnalogInput) ReadScan(numScans int) ([]byte, error) {
 		_, _ = ai.Read(data)
 	}
 	if status&byte(scanOverrun) != 0 {
-		log.Printf("Analog AIn scan overrun.\n")
+		ai.logger().Warn("Analog input scan overrun",
+			"serial_number", ai.serialNumber, "bytes", bytesToRead)
 		ai.StopScan()
 		ai.ClearScanBuffer()
+		return data, &mccdaq.OverrunError{BytesRead: bytesToRead}
 	}
 
-	return data, err
+	return data, nil
+}
+
+// logger returns the Logger for the analog input or the default logger if
+// none is set.
+func (ai *AnalogInput) logger() mccdaq.Logger {
+	if ai.Logger == nil {
+		return defaultLogger
+	}
+	return ai.Logger
+}
+
+// transferSize returns the number of bytes, a multiple of
+// maxBulkTransferPacketSize, to request in each bulk transfer of a read with a
+// cancelable context, given whether scan data has already arrived in the
+// read. Only a single packet can be polled for without losing data, so that's
+// all that's requested unless the DAQ's own pacer is sure to produce more in
+// half of contextPollTimeout. With an external pacer, or a trigger before the
+// first data arrives, the data may start partway through any transfer.
+func (ai *AnalogInput) transferSize(flowing bool) int {
+	if !ai.pacedInternally(flowing) {
+		return maxBulkTransferPacketSize
+	}
+	bytesPerSecond := ai.Frequency * float64(ai.NumEnabledChannels()*bytesPerWord)
+	packets := int(bytesPerSecond * contextPollTimeout / 2000 / maxBulkTransferPacketSize)
+	if packets < 1 {
+		packets = 1
+	}
+	return packets * maxBulkTransferPacketSize
+}
+
+// pacedInternally reports whether the scan data is known to be arriving at the
+// rate of the DAQ's own pacer, given whether scan data has already arrived in
+// the current read.
+func (ai *AnalogInput) pacedInternally(flowing bool) bool {
+	if ai.UseExternalPacer || ai.Frequency <= 0 {
+		return false
+	}
+	return flowing || ai.Trigger == NoExternalTrigger
+}
+
+// readData reads p from the DAQ with the context. When the scan is paced
+// internally, the read fails with mccdaq.ErrTimeout once the pacer should
+// have produced the data with defaultTimeout to spare, so that a slow scan
+// isn't cut short but a stalled one doesn't wait forever. Otherwise, the
+// read waits for as long as the context allows.
+func (ai *AnalogInput) readData(ctx context.Context, p []byte, flowing bool) (int, error) {
+	if ctx.Done() == nil || !ai.pacedInternally(flowing) {
+		return ai.ReadContext(ctx, p)
+	}
+	bytesPerSecond := ai.Frequency * float64(ai.NumEnabledChannels()*bytesPerWord)
+	wait := time.Duration(float64(len(p))/bytesPerSecond*float64(time.Second)) +
+		defaultTimeout*time.Millisecond
+	readCtx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	n, err := ai.ReadContext(readCtx, p)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return n, fmt.Errorf("no scan data for %s: %w", wait, mccdaq.ErrTimeout)
+	}
+	return n, err
+}
+
+// abortScan stops the scan and clears the scan FIFO buffer after the context
+// is done partway through a read, and then returns the context's error.
+func (ai *AnalogInput) abortScan(ctx context.Context) error {
+	ai.StopScan()
+	ai.ClearScanBuffer()
+	return ctx.Err()
 }
 
 // Close stops the analog input scan if running.
@@ -440,7 +567,7 @@ func (ai *AnalogInput) Close() error {
 func (ai *AnalogInput) StopScan() error {
 	_, err := ai.SendCommandToDevice(commandAnalogStopScan, nil)
 	if err != nil {
-		return fmt.Errorf("Error stopping analog input scan %s", err)
+		return fmt.Errorf("Error stopping analog input scan %w", err)
 	}
 	return nil
 }
@@ -449,12 +576,14 @@ func (ai *AnalogInput) StopScan() error {
 func (ai *AnalogInput) ClearScanBuffer() error {
 	_, err := ai.SendCommandToDevice(commandAnalogClearBuffer, nil)
 	if err != nil {
-		return fmt.Errorf("Error clearing analog input scan FIFO buffer %s", err)
+		return fmt.Errorf("Error clearing analog input scan FIFO buffer %w", err)
 	}
 	return nil
 }
 
-// SetScanRanges writes the scan ranges to the USB DAQ.
+// SetScanRanges writes the scan ranges to the USB DAQ. Writing the ranges
+// while a scan is running would stall the bus, so it fails with
+// mccdaq.ErrScanRunning instead.
 func (ai *AnalogInput) SetScanRanges() error {
 	ranges := make([]byte, 8)
 	for i, channel := range ai.Channels {
@@ -465,7 +594,7 @@ func (ai *AnalogInput) SetScanRanges() error {
 	}
 	_, err := ai.SendCommandToDevice(commandAnalogConfig, ranges)
 	if err != nil {
-		return fmt.Errorf("Error writing Ain config %s", err)
+		return fmt.Errorf("Error writing Ain config %w", err)
 	}
 	return nil
 }
@@ -477,10 +606,10 @@ func (ai *AnalogInput) ScanRanges() ([]byte, error) {
 	var ranges = make([]byte, bytesInRange)
 	bytesRead, err := ai.ReadCommandFromDevice(commandAnalogConfig, ranges)
 	if err != nil {
-		return ranges, fmt.Errorf("Error reading Ain config: %s", err)
+		return ranges, fmt.Errorf("Error reading Ain config: %w", err)
 	}
 	if bytesRead != bytesInRange {
-		return ranges, fmt.Errorf("Wrong number of ranges: %s", err)
+		return ranges, fmt.Errorf("Wrong number of ranges: %d", bytesRead)
 	}
 	return ranges, nil
 }
@@ -545,15 +674,13 @@ func round(f float64) int {
 }
 
 // ReadAnalogInput reads the value of an analog input channel. This command
-// will result in a bus stall if an AInScan is currenty running.
+// would result in a bus stall if an AInScan is currenty running, so it fails
+// with mccdaq.ErrScanRunning instead.
 func (daq *usb20x) ReadAnalogInput(channel int, rng VoltageRange) (uint, error) {
-	requestType := libusb.BitmapRequestType(
-		libusb.DeviceToHost, libusb.Vendor, libusb.DeviceRecipient)
 	data := make([]byte, 2)
-	_, err := daq.DeviceHandle.ControlTransfer(
-		requestType, byte(commandAnalogInput), uint16(channel), uint16(rng), data, len(data), daq.Timeout)
+	_, err := daq.controlTransferIn(commandAnalogInput, uint16(channel), uint16(rng), data)
 	if err != nil {
-		return 0, fmt.Errorf("Error reading analog input %s", err)
+		return 0, fmt.Errorf("Error reading analog input %w", err)
 	}
 	value := binary.LittleEndian.Uint16(data)
 	return uint(value), nil
@@ -591,7 +718,7 @@ func (ai *AnalogInput) ConfigureChannel(
 	// Return error if the voltage range is invalid
 	inputRange, ok := InputRanges[voltage]
 	if !ok {
-		return fmt.Errorf("voltage input range `%s` is invalid", inputRange)
+		return fmt.Errorf("voltage input range `%s` is invalid: %w", voltage, mccdaq.ErrInvalidRange)
 	}
 	return ai.configureChannel(ch, enabled, inputRange, description)
 }
@@ -604,7 +731,7 @@ func (ai *AnalogInput) configureChannel(
 
 	// Return error if the channel is invalid
 	if ch < 0 || ch >= len(ai.Channels) {
-		return fmt.Errorf("Channel %d outside valid range", ch)
+		return fmt.Errorf("Channel %d outside valid range: %w", ch, mccdaq.ErrInvalidChannel)
 	}
 
 	// Configure the channel
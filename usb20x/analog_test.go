@@ -6,6 +6,7 @@
 package usb20x
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -48,6 +49,13 @@ func (f *FakeDAQer) Read(p []byte) (n int, err error) {
 	return 0, nil
 }
 
+func (f *FakeDAQer) ReadContext(ctx context.Context, p []byte) (n int, err error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return f.Read(p)
+}
+
 func (f *FakeDAQer) Status() (byte, error) {
 	return 0x0, nil
 }
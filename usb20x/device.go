@@ -6,17 +6,26 @@
 package usb20x
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gotmc/libusb"
+	"github.com/gotmc/mccdaq"
+	"github.com/gotmc/mccdaq/internal/usberr"
 )
 
 const (
 	vendorID       = 0x09db
 	defaultTimeout = 2000
 	msSleepTime    = 500
+	// contextPollTimeout is the longest, in milliseconds, that a bulk transfer
+	// polling for a single packet blocks when reading with a cancelable
+	// context.
+	contextPollTimeout = 100
 )
 
 // FIXME(mdr): I feel like these should be their own type.
@@ -32,9 +41,14 @@ type DAQer interface {
 	SendCommandToDevice(cmd command, data []byte) (int, error)
 	ReadCommandFromDevice(cmd command, data []byte) (int, error)
 	Read(p []byte) (n int, err error)
+	ReadContext(ctx context.Context, p []byte) (n int, err error)
 	Status() (byte, error)
 }
 
+// usb20x models a USB-200 series DAQ. Its methods are safe for concurrent
+// use: control transfers are serialized, and only one goroutine at a time may
+// read from the bulk endpoint. While an analog input scan is running, commands
+// that would stall the bus fail with mccdaq.ErrScanRunning.
 type usb20x struct {
 	Timeout          int
 	Device           *libusb.Device
@@ -42,21 +56,82 @@ type usb20x struct {
 	DeviceHandle     *libusb.DeviceHandle
 	ConfigDescriptor *libusb.ConfigDescriptor
 	BulkEndpoint     *libusb.EndpointDescriptor
+	Logger           mccdaq.Logger
+	serialNumber     string
+	// handle, if set, is used instead of DeviceHandle to talk to the DAQ.
+	handle usbHandle
+	// controlMu serializes control transfers and guards scanning.
+	controlMu sync.Mutex
+	// scanning is set while the DAQ is believed to be running an analog input
+	// scan, based on the commands sent and the last status read.
+	scanning bool
+	// bulkOwned is nonzero while a goroutine is reading the bulk endpoint.
+	bulkOwned int32
+	// bulkMu is held throughout a read of the bulk endpoint, so that closing
+	// the DAQ can wait for the read to finish.
+	bulkMu sync.Mutex
+	// closed is nonzero once Close has been called, after which commands and
+	// reads fail with mccdaq.ErrClosed.
+	closed int32
+}
+
+// Option configures a DAQ when it is created.
+type Option func(*usb20x)
+
+// WithLogger sets the Logger used by the DAQ and any AnalogInput created from
+// it. By default, events at mccdaq.LevelInfo and above are logged using the
+// log package's standard logger. Use mccdaq.NopLogger to disable logging.
+func WithLogger(logger mccdaq.Logger) Option {
+	return func(daq *usb20x) {
+		daq.Logger = logger
+	}
+}
+
+// defaultLogger is used when a DAQ or AnalogInput doesn't have a Logger.
+var defaultLogger mccdaq.Logger = &mccdaq.StdLogger{}
+
+// logger returns the Logger for the DAQ or the default logger if none is set.
+func (daq *usb20x) logger() mccdaq.Logger {
+	if daq.Logger == nil {
+		return defaultLogger
+	}
+	return daq.Logger
+}
+
+// newDevice returns a DAQ with the default settings and the given options
+// applied.
+func newDevice(opts []Option) *usb20x {
+	daq := usb20x{Timeout: defaultTimeout}
+	for _, opt := range opts {
+		opt(&daq)
+	}
+	return &daq
 }
 
 // NewViaSN creates a new daq instance by searching through the list of USB
 // devices for the given serial number.
-func NewViaSN(ctx *libusb.Context, sn string) (*usb20x, error) {
-	var daq usb20x
-	usbDevices, err := ctx.GetDeviceList()
+func NewViaSN(ctx *libusb.Context, sn string, opts ...Option) (*usb20x, error) {
+	return NewViaSNContext(context.Background(), ctx, sn, opts...)
+}
+
+// NewViaSNContext creates a new daq instance like NewViaSN, but stops
+// searching through the list of USB devices once the context is done.
+func NewViaSNContext(
+	ctx context.Context, usbCtx *libusb.Context, sn string, opts ...Option,
+) (*usb20x, error) {
+	daq := newDevice(opts)
+	usbDevices, err := usbCtx.GetDeviceList()
 	if err != nil {
-		return &daq, fmt.Errorf("Error getting USB device list: %s", err)
+		return daq, fmt.Errorf("Error getting USB device list: %w", err)
 	}
 	// Search through the USB devices looking for serial number
 	for _, usbDevice := range usbDevices {
+		if err := ctx.Err(); err != nil {
+			return daq, err
+		}
 		usbDeviceDescriptor, err := usbDevice.GetDeviceDescriptor()
 		if err != nil {
-			return &daq, fmt.Errorf("Error getting device descriptor: %s", err)
+			return daq, fmt.Errorf("Error getting device descriptor: %w", err)
 		}
 		// Check the VendorID and Product ID. If those don't equate to MCC and one
 		// of the USB20X Product IDs, then there's no reason to open the device and
@@ -69,105 +144,149 @@ func NewViaSN(ctx *libusb.Context, sn string) (*usb20x, error) {
 			// Found a USB-1608FS-Plus
 			usbDeviceHandle, err := usbDevice.Open()
 			if err != nil {
-				return &daq, fmt.Errorf("Error getting device handle: %s", err)
+				return daq, fmt.Errorf("Error getting device handle: %w", usberr.Wrap(err))
 			}
 			serialNum, err := usbDeviceHandle.GetStringDescriptorASCII(
 				usbDeviceDescriptor.SerialNumberIndex)
 			if err != nil {
-				return &daq, fmt.Errorf("Error reading S/N: %s", err)
+				return daq, fmt.Errorf("Error reading S/N: %w", usberr.Wrap(err))
 			}
 			if serialNum == sn {
-				log.Printf("Found S/N %s. Creating device", sn)
-				return create(usbDevice, usbDeviceHandle)
+				daq.logger().Info("Found S/N. Creating device", "serial_number", sn)
+				return create(daq, usbDevice, usbDeviceHandle)
 			}
 			usbDeviceHandle.Close()
 		}
 	}
 	// Close the list of devices
-	return &daq, fmt.Errorf("couldn't find device s/n %s", sn)
+	return daq, fmt.Errorf("couldn't find device s/n %s: %w", sn, mccdaq.ErrDeviceNotFound)
 }
 
 // GetFirstUSB201 creates a new instance of a daq using the first
 // USB-201 found in the USB context.
-func getFirstUSB201(ctx *libusb.Context) (*usb20x, error) {
-	return getFirstDevice(ctx, usb201PID)
+func getFirstUSB201(ctx *libusb.Context, opts ...Option) (*usb20x, error) {
+	return getFirstDevice(ctx, usb201PID, opts)
 }
 
 // GetFirstUSB202 creates a new instance of a daq using the first
 // USB-202 found in the USB context.
-func getFirstUSB202(ctx *libusb.Context) (*usb20x, error) {
-	return getFirstDevice(ctx, usb202PID)
+func getFirstUSB202(ctx *libusb.Context, opts ...Option) (*usb20x, error) {
+	return getFirstDevice(ctx, usb202PID, opts)
 }
 
 // GetFirstUSB204 creates a new instance of a daq using the first
 // USB-204 found in the USB context.
-func getFirstUSB204(ctx *libusb.Context) (*usb20x, error) {
-	return getFirstDevice(ctx, usb204PID)
+func getFirstUSB204(ctx *libusb.Context, opts ...Option) (*usb20x, error) {
+	return getFirstDevice(ctx, usb204PID, opts)
 }
 
 // GetFirstUSB205 creates a new instance of a daq using the first
 // USB-205 found in the USB context.
-func getFirstUSB205(ctx *libusb.Context) (*usb20x, error) {
-	return getFirstDevice(ctx, usb205PID)
+func getFirstUSB205(ctx *libusb.Context, opts ...Option) (*usb20x, error) {
+	return getFirstDevice(ctx, usb205PID, opts)
 }
 
-func getFirstDevice(ctx *libusb.Context, productID uint) (*usb20x, error) {
-	var daq usb20x
-	dev, dh, err := ctx.OpenDeviceWithVendorProduct(vendorID, uint16(productID))
+func getFirstDevice(ctx *libusb.Context, productID uint, opts []Option) (*usb20x, error) {
+	daq := newDevice(opts)
+	usbDevices, err := ctx.GetDeviceList()
 	if err != nil {
-		return &daq, fmt.Errorf("Error opening the USB-1608FS-Plus using the VendorID and ProductID, %s", err)
+		return daq, fmt.Errorf("Error getting USB device list: %w", usberr.Wrap(err))
 	}
-	return create(dev, dh)
+	for _, usbDevice := range usbDevices {
+		usbDeviceDescriptor, err := usbDevice.GetDeviceDescriptor()
+		if err != nil {
+			return daq, fmt.Errorf("Error getting device descriptor: %w", usberr.Wrap(err))
+		}
+		if usbDeviceDescriptor.VendorID != vendorID ||
+			usbDeviceDescriptor.ProductID != uint16(productID) {
+			continue
+		}
+		// Failing to open the first matching DAQ, such as for lack of
+		// permission, isn't the same as not finding one.
+		usbDeviceHandle, err := usbDevice.Open()
+		if err != nil {
+			return daq, fmt.Errorf("error opening the daq with product ID 0x%x: %w",
+				productID, usberr.Wrap(err))
+		}
+		return create(daq, usbDevice, usbDeviceHandle)
+	}
+	return daq, fmt.Errorf("couldn't find a daq with product ID 0x%x: %w",
+		productID, mccdaq.ErrDeviceNotFound)
 }
 
-func create(dev *libusb.Device, dh *libusb.DeviceHandle) (*usb20x, error) {
-	var daq usb20x
+func create(daq *usb20x, dev *libusb.Device, dh *libusb.DeviceHandle) (*usb20x, error) {
 	err := dh.ClaimInterface(0)
 	if err != nil {
-		return &daq, fmt.Errorf("Error claiming the bulk interface %s", err)
+		return daq, fmt.Errorf("Error claiming the bulk interface %w", usberr.Wrap(err))
 	}
-	daq.Timeout = defaultTimeout
 	daq.Device = dev
 	daq.DeviceHandle = dh
 	deviceDescriptor, err := daq.Device.GetDeviceDescriptor()
 	if err != nil {
-		return &daq, fmt.Errorf("Error getting device descriptor %s", err)
+		return daq, fmt.Errorf("Error getting device descriptor %w", err)
 	}
 	daq.DeviceDescriptor = deviceDescriptor
+	// The serial number is only used to identify the DAQ in log events, so
+	// failing to read it isn't fatal.
+	if sn, err := dh.GetStringDescriptorASCII(deviceDescriptor.SerialNumberIndex); err == nil {
+		daq.serialNumber = sn
+	}
 	configDescriptor, err := daq.Device.GetActiveConfigDescriptor()
 	if err != nil {
-		return &daq, fmt.Errorf("Error getting active config descriptor. %s", err)
+		return daq, fmt.Errorf("Error getting active config descriptor. %w", err)
 	}
 	daq.ConfigDescriptor = configDescriptor
 	firstDescriptor := configDescriptor.SupportedInterfaces[0].InterfaceDescriptors[0]
 	daq.BulkEndpoint = firstDescriptor.EndpointDescriptors[0]
-	return &daq, nil
+	daq.logger().Debug("Claimed interface", "serial_number", daq.serialNumber)
+	return daq, nil
 }
 
 func (daq *usb20x) Close() error {
+	return daq.CloseContext(context.Background())
+}
+
+// CloseContext closes the DAQ like Close, but stops waiting for the
+// device to settle once the context is done. Commands and reads fail with
+// mccdaq.ErrClosed once Close has been called, and Close waits for a read in
+// progress to return, which takes at most daq.Timeout, and for any command in
+// progress before releasing the interface and closing the device handle. The
+// device handle is always closed, even if the context is done before the
+// device is reset.
+func (daq *usb20x) CloseContext(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&daq.closed, 0, 1) {
+		return fmt.Errorf("can't close the daq twice: %w", mccdaq.ErrClosed)
+	}
+	// Wait for any read in progress to finish, so no transfer is left running
+	// on the closed handle. A read that starts later sees that the DAQ is
+	// closed once it holds bulkMu, before it submits a transfer.
+	daq.bulkMu.Lock()
+	daq.bulkMu.Unlock()
+	daq.controlMu.Lock()
+	defer daq.controlMu.Unlock()
+	defer daq.usb().close()
+	daq.logger().Debug("Closing device", "serial_number", daq.serialNumber)
 	// Release the interface and close up shop
-	err := daq.DeviceHandle.ReleaseInterface(0)
+	err := daq.usb().releaseInterface()
 	if err != nil {
-		return fmt.Errorf("Error releasing interface %s", err)
+		return fmt.Errorf("Error releasing interface %w", usberr.Wrap(err))
 	}
-	time.Sleep(msSleepTime * time.Millisecond)
-	_, err = daq.Reset()
+	if err := sleep(ctx, msSleepTime*time.Millisecond); err != nil {
+		return err
+	}
+	_, err = daq.usb().controlTransfer(false, byte(commandReset), 0x0, 0x0, []byte{0x00}, daq.Timeout)
 	if err != nil {
-		return fmt.Errorf("Error reseting USB-1608FS-Plus %s", err)
+		return fmt.Errorf("Error reseting USB-20x %w", usberr.Wrap(err))
 	}
-	time.Sleep(msSleepTime * time.Millisecond)
-	daq.DeviceHandle.Close()
-	return nil
+	daq.scanning = false
+	return sleep(ctx, msSleepTime*time.Millisecond)
 }
 
 // Reset resets the device.
 func (daq *usb20x) Reset() (int, error) {
-	requestType := libusb.BitmapRequestType(
-		libusb.HostToDevice, libusb.Vendor, libusb.DeviceRecipient)
-	ret, err := daq.DeviceHandle.ControlTransfer(
-		requestType, byte(commandReset), 0x0, 0x0, []byte{0x00}, 1, daq.Timeout)
+	ret, err := daq.controlTransferOut(commandReset, 0x0, 0x0, []byte{0x00})
 	if err != nil {
-		return ret, fmt.Errorf("Error resetting devices %s", err)
+		return ret, fmt.Errorf("Error resetting devices %w", err)
 	}
 	return ret, nil
 }
@@ -179,13 +298,12 @@ func (daq *usb20x) SendCommandToDevice(cmd command, data []byte) (int, error) {
 	if data == nil {
 		data = []byte{0}
 	}
-	requestType := libusb.BitmapRequestType(
-		libusb.HostToDevice, libusb.Vendor, libusb.DeviceRecipient)
-	bytesReceived, err := daq.DeviceHandle.ControlTransfer(
-		requestType, byte(cmd), 0x0, 0x0, data, len(data), daq.Timeout)
+	bytesReceived, err := daq.controlTransferOut(cmd, 0x0, 0x0, data)
 	if err != nil {
-		return bytesReceived, fmt.Errorf("Error sending command '%s' to device: %s", cmd, err)
+		return bytesReceived, fmt.Errorf("Error sending command '%s' to device: %w", cmd, err)
 	}
+	daq.logger().Debug("Sent command",
+		"serial_number", daq.serialNumber, "command", cmd, "bytes", bytesReceived)
 	return bytesReceived, nil
 }
 
@@ -193,21 +311,160 @@ func (daq *usb20x) ReadCommandFromDevice(cmd command, data []byte) (int, error)
 	if data == nil {
 		data = []byte{0}
 	}
-	requestType := libusb.BitmapRequestType(
-		libusb.DeviceToHost, libusb.Vendor, libusb.DeviceRecipient)
-	bytesReceived, err := daq.DeviceHandle.ControlTransfer(
-		requestType, byte(cmd), 0x0, 0x0, data, len(data), daq.Timeout)
+	bytesReceived, err := daq.controlTransferIn(cmd, 0x0, 0x0, data)
 	if err != nil {
-		return bytesReceived, fmt.Errorf("Error reading command '%s' from device: %s", cmd, err)
+		return bytesReceived, fmt.Errorf("Error reading command '%s' from device: %w", cmd, err)
 	}
+	daq.logger().Debug("Read command",
+		"serial_number", daq.serialNumber, "command", cmd, "bytes", bytesReceived)
 	return bytesReceived, nil
 }
 
+// controlTransferOut performs a host-to-device vendor control transfer of the
+// given command using the length of data as the transfer length. Any libusb
+// error code is wrapped so that it matches the mccdaq sentinel errors.
+func (daq *usb20x) controlTransferOut(
+	cmd command, value, index uint16, data []byte,
+) (int, error) {
+	daq.controlMu.Lock()
+	defer daq.controlMu.Unlock()
+	if atomic.LoadInt32(&daq.closed) != 0 {
+		return 0, fmt.Errorf("can't send command '%s': %w", cmd, mccdaq.ErrClosed)
+	}
+	if err := daq.checkScanIdle(cmd); err != nil {
+		return 0, err
+	}
+	ret, err := daq.usb().controlTransfer(false, byte(cmd), value, index, data, daq.Timeout)
+	if err != nil {
+		return ret, usberr.Wrap(err)
+	}
+	switch cmd {
+	case commandAnalogStartScan:
+		daq.scanning = true
+	case commandAnalogStopScan, commandReset:
+		daq.scanning = false
+	}
+	return ret, nil
+}
+
+// controlTransferIn performs a device-to-host vendor control transfer of the
+// given command, reading up to len(data) bytes into data. Any libusb error
+// code is wrapped so that it matches the mccdaq sentinel errors.
+func (daq *usb20x) controlTransferIn(
+	cmd command, value, index uint16, data []byte,
+) (int, error) {
+	daq.controlMu.Lock()
+	defer daq.controlMu.Unlock()
+	if atomic.LoadInt32(&daq.closed) != 0 {
+		return 0, fmt.Errorf("can't send command '%s': %w", cmd, mccdaq.ErrClosed)
+	}
+	if err := daq.checkScanIdle(cmd); err != nil {
+		return 0, err
+	}
+	ret, err := daq.usb().controlTransfer(true, byte(cmd), value, index, data, daq.Timeout)
+	if err != nil {
+		return ret, usberr.Wrap(err)
+	}
+	if cmd == commandGetStatus && ret > 0 {
+		daq.scanning = data[0]&byte(scanRunning) != 0
+	}
+	return ret, nil
+}
+
+// checkScanIdle returns mccdaq.ErrScanRunning if the command would stall the
+// bus because an analog input scan is running. A scan with a fixed number of
+// scans stops on its own, so the status is read to confirm that the scan is
+// still running before failing. The caller must hold controlMu.
+func (daq *usb20x) checkScanIdle(cmd command) error {
+	if !daq.scanning || (cmd != commandAnalogInput && cmd != commandAnalogConfig) {
+		return nil
+	}
+	data := make([]byte, 2)
+	_, err := daq.usb().controlTransfer(true, byte(commandGetStatus), 0x0, 0x0, data, daq.Timeout)
+	if err != nil {
+		return fmt.Errorf("error reading status before '%s': %w", cmd, usberr.Wrap(err))
+	}
+	daq.scanning = data[0]&byte(scanRunning) != 0
+	if daq.scanning {
+		return fmt.Errorf("can't send command '%s': %w", cmd, mccdaq.ErrScanRunning)
+	}
+	return nil
+}
+
+// Read reads the data using a bulk USB transfer. Only one goroutine at a time
+// may read from the bulk endpoint; any other concurrent read fails with
+// mccdaq.ErrBusy.
 func (daq *usb20x) Read(p []byte) (n int, err error) {
-	return daq.DeviceHandle.BulkTransfer(
-		daq.BulkEndpoint.EndpointAddress,
-		p,
-		len(p),
-		daq.Timeout,
-	)
+	return daq.ReadContext(context.Background(), p)
+}
+
+// ReadContext reads the data using a bulk USB transfer like Read, but gives up
+// once the context is done. A libusb bulk transfer can't be interrupted once
+// submitted, and one that times out discards any packets it had received, so
+// only a read of at most one packet is polled: it's retried with a timeout of
+// contextPollTimeout until a packet arrives or the context is done, however
+// long that takes. A longer read is a single transfer with a timeout of
+// daq.Timeout, so it should only be used for data that's sure to arrive well
+// within daq.Timeout, and the context is only checked before it's submitted.
+func (daq *usb20x) ReadContext(ctx context.Context, p []byte) (n int, err error) {
+	if atomic.LoadInt32(&daq.closed) != 0 {
+		return 0, fmt.Errorf("can't read: %w", mccdaq.ErrClosed)
+	}
+	if !atomic.CompareAndSwapInt32(&daq.bulkOwned, 0, 1) {
+		return 0, mccdaq.ErrBusy
+	}
+	defer atomic.StoreInt32(&daq.bulkOwned, 0)
+	daq.bulkMu.Lock()
+	defer daq.bulkMu.Unlock()
+	if atomic.LoadInt32(&daq.closed) != 0 {
+		return 0, fmt.Errorf("can't read: %w", mccdaq.ErrClosed)
+	}
+	if ctx.Done() == nil {
+		return daq.bulkTransfer(p, daq.Timeout)
+	}
+	if len(p) > maxBulkTransferPacketSize {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		return daq.bulkTransfer(p, daq.Timeout)
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if atomic.LoadInt32(&daq.closed) != 0 {
+			return 0, fmt.Errorf("can't read: %w", mccdaq.ErrClosed)
+		}
+		n, err = daq.bulkTransfer(p, contextPollTimeout)
+		if !errors.Is(err, mccdaq.ErrTimeout) {
+			return n, err
+		}
+	}
+}
+
+func (daq *usb20x) bulkTransfer(p []byte, timeout int) (int, error) {
+	n, err := daq.usb().bulkTransfer(p, timeout)
+	return n, usberr.Wrap(err)
+}
+
+// usb returns the handle used to talk to the DAQ.
+func (daq *usb20x) usb() usbHandle {
+	if daq.handle != nil {
+		return daq.handle
+	}
+	return libusbHandle{dh: daq.DeviceHandle, endpoint: daq.BulkEndpoint}
+}
+
+// sleep pauses the current goroutine for the duration d or until the context
+// is done, whichever happens first. The context's error is returned if the
+// context is done before d has elapsed.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
@@ -0,0 +1,215 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package usb20x
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// fakeHandle is a usbHandle that records overlapping control transfers and
+// can block bulk transfers until released.
+type fakeHandle struct {
+	inFlight int32
+	overlaps int32
+	status   int32
+	// bulkStarted, if not nil, receives a value when a bulk transfer starts.
+	bulkStarted chan struct{}
+	// bulkRelease, if not nil, blocks bulk transfers until it's closed.
+	bulkRelease chan struct{}
+	// inBulk is nonzero during a bulk transfer, and closes and
+	// closesInBulk count the times the handle is closed, and closed during
+	// a bulk transfer.
+	inBulk       int32
+	closes       int32
+	closesInBulk int32
+}
+
+func (h *fakeHandle) controlTransfer(
+	in bool, request byte, value, index uint16, data []byte, timeout int,
+) (int, error) {
+	if atomic.AddInt32(&h.inFlight, 1) > 1 {
+		atomic.AddInt32(&h.overlaps, 1)
+	}
+	defer atomic.AddInt32(&h.inFlight, -1)
+	runtime.Gosched()
+	if in && command(request) == commandGetStatus {
+		data[0] = byte(atomic.LoadInt32(&h.status))
+	}
+	return len(data), nil
+}
+
+func (h *fakeHandle) bulkTransfer(data []byte, timeout int) (int, error) {
+	atomic.StoreInt32(&h.inBulk, 1)
+	defer atomic.StoreInt32(&h.inBulk, 0)
+	if h.bulkStarted != nil {
+		h.bulkStarted <- struct{}{}
+	}
+	if h.bulkRelease != nil {
+		<-h.bulkRelease
+	}
+	return len(data), nil
+}
+
+func (h *fakeHandle) releaseInterface() error {
+	return nil
+}
+
+func (h *fakeHandle) close() error {
+	atomic.AddInt32(&h.closes, 1)
+	if atomic.LoadInt32(&h.inBulk) != 0 {
+		atomic.AddInt32(&h.closesInBulk, 1)
+	}
+	return nil
+}
+
+func TestControlTransfersSerialized(t *testing.T) {
+	h := fakeHandle{}
+	daq := usb20x{handle: &h, Logger: mccdaq.NopLogger}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if _, err := daq.BlinkLED(1); err != nil {
+					t.Errorf("BlinkLED: %v", err)
+				}
+				if _, err := daq.Status(); err != nil {
+					t.Errorf("Status: %v", err)
+				}
+				if _, err := daq.SerialNumber(); err != nil {
+					t.Errorf("SerialNumber: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if h.overlaps != 0 {
+		t.Errorf("Expected no overlapping control transfers, got %d", h.overlaps)
+	}
+}
+
+func TestBulkEndpointSingleOwner(t *testing.T) {
+	h := fakeHandle{
+		bulkStarted: make(chan struct{}, 1),
+		bulkRelease: make(chan struct{}),
+	}
+	daq := usb20x{handle: &h, Logger: mccdaq.NopLogger}
+	done := make(chan error)
+	go func() {
+		_, err := daq.Read(make([]byte, maxBulkTransferPacketSize))
+		done <- err
+	}()
+	<-h.bulkStarted
+	if _, err := daq.Read(make([]byte, maxBulkTransferPacketSize)); !errors.Is(err, mccdaq.ErrBusy) {
+		t.Errorf("Expected ErrBusy, got %v", err)
+	}
+	close(h.bulkRelease)
+	if err := <-done; err != nil {
+		t.Errorf("Expected first read to succeed, got %v", err)
+	}
+	h.bulkStarted = nil
+	if _, err := daq.Read(make([]byte, maxBulkTransferPacketSize)); err != nil {
+		t.Errorf("Expected read after release to succeed, got %v", err)
+	}
+}
+
+func TestAnalogInputReadSingleOwner(t *testing.T) {
+	ai := AnalogInput{DAQer: &FakeDAQer{}, reading: 1}
+	if _, err := ai.ReadScan(32); !errors.Is(err, mccdaq.ErrBusy) {
+		t.Errorf("Expected ErrBusy, got %v", err)
+	}
+}
+
+func TestStallingCommandsDuringScan(t *testing.T) {
+	h := fakeHandle{}
+	daq := usb20x{handle: &h, Logger: mccdaq.NopLogger}
+	ai := AnalogInput{DAQer: &daq, Frequency: 1000}
+	if err := ai.StartScan(0); err != nil {
+		t.Fatalf("StartScan: %v", err)
+	}
+	atomic.StoreInt32(&h.status, int32(scanRunning))
+	if _, err := daq.ReadAnalogInput(0, Range10V); !errors.Is(err, mccdaq.ErrScanRunning) {
+		t.Errorf("Expected ErrScanRunning from ReadAnalogInput, got %v", err)
+	}
+	if err := ai.SetScanRanges(); !errors.Is(err, mccdaq.ErrScanRunning) {
+		t.Errorf("Expected ErrScanRunning from SetScanRanges, got %v", err)
+	}
+	if _, err := daq.BlinkLED(1); err != nil {
+		t.Errorf("Expected BlinkLED to succeed during scan, got %v", err)
+	}
+	// A finite scan stops on its own, which shows up in the status.
+	atomic.StoreInt32(&h.status, 0)
+	if _, err := daq.ReadAnalogInput(0, Range10V); err != nil {
+		t.Errorf("Expected ReadAnalogInput to succeed after scan finished, got %v", err)
+	}
+	atomic.StoreInt32(&h.status, int32(scanRunning))
+	if err := ai.StartScan(0); err != nil {
+		t.Fatalf("StartScan: %v", err)
+	}
+	if err := ai.StopScan(); err != nil {
+		t.Fatalf("StopScan: %v", err)
+	}
+	if _, err := daq.ReadAnalogInput(0, Range10V); err != nil {
+		t.Errorf("Expected ReadAnalogInput to succeed after StopScan, got %v", err)
+	}
+}
+
+func TestCloseDuringRead(t *testing.T) {
+	h := fakeHandle{
+		bulkStarted: make(chan struct{}, 1),
+		bulkRelease: make(chan struct{}),
+	}
+	daq := usb20x{handle: &h, Logger: mccdaq.NopLogger, Timeout: defaultTimeout}
+	read := make(chan error)
+	go func() {
+		_, err := daq.Read(make([]byte, maxBulkTransferPacketSize))
+		read <- err
+	}()
+	<-h.bulkStarted
+	closed := make(chan error)
+	go func() {
+		closed <- daq.Close()
+	}()
+	for atomic.LoadInt32(&daq.closed) == 0 {
+		runtime.Gosched()
+	}
+	select {
+	case err := <-closed:
+		t.Fatalf("Expected Close to wait for the read, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, err := daq.Read(make([]byte, maxBulkTransferPacketSize)); !errors.Is(err, mccdaq.ErrClosed) {
+		t.Errorf("Expected ErrClosed from a read while closing, got %v", err)
+	}
+	close(h.bulkRelease)
+	if err := <-read; err != nil {
+		t.Errorf("Expected the read in progress to succeed, got %v", err)
+	}
+	if err := <-closed; err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	if h.closes != 1 || h.closesInBulk != 0 {
+		t.Errorf("Expected the handle closed once outside a transfer, got %d and %d",
+			h.closes, h.closesInBulk)
+	}
+	if _, err := daq.Status(); !errors.Is(err, mccdaq.ErrClosed) {
+		t.Errorf("Expected ErrClosed from a command, got %v", err)
+	}
+	if _, err := daq.Read(make([]byte, maxBulkTransferPacketSize)); !errors.Is(err, mccdaq.ErrClosed) {
+		t.Errorf("Expected ErrClosed from a read, got %v", err)
+	}
+	if err := daq.Close(); !errors.Is(err, mccdaq.ErrClosed) {
+		t.Errorf("Expected ErrClosed closing twice, got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package usb20x
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+)
+
+// Framer decodes the scan data of a continuous analog input scan, read in
+// blocks by ReadScan, into timestamped frames, so that USB-20x scans can be
+// used wherever a mccdaq.Frame is expected, such as by the align package.
+// Create a Framer using AnalogInput.NewFramer once the scan is started, and
+// pass it each block of scan data in the order read.
+//
+// The scan period is derived from the analog input's Frequency. The first
+// scan is anchored to the host clock when the first block arrives, by
+// counting back from its arrival the scans it holds, and the drift estimate
+// is updated with each block after that. Create a new Framer when the scan is
+// restarted, such as after an overrun.
+type Framer struct {
+	// DriftInterval is how often the drift estimate is updated. If zero,
+	// mccdaq.DefaultDriftInterval is used.
+	DriftInterval time.Duration
+
+	ai       *AnalogInput
+	channels []int
+	timebase mccdaq.Timebase
+	drift    mccdaq.DriftEstimator
+	anchored bool
+	next     uint64
+}
+
+// NewFramer creates a Framer for the channels currently enabled in the analog
+// input.
+func (ai *AnalogInput) NewFramer() (*Framer, error) {
+	if ai.Frequency <= 0 {
+		return nil, fmt.Errorf("framer needs a positive frequency, got %g", ai.Frequency)
+	}
+	var channels []int
+	for ch, channel := range ai.Channels {
+		if channel.Enabled {
+			channels = append(channels, ch)
+		}
+	}
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("framer needs an enabled channel: %w", mccdaq.ErrInvalidChannel)
+	}
+	f := Framer{
+		ai:       ai,
+		channels: channels,
+		timebase: mccdaq.Timebase{Period: time.Duration(float64(time.Second) / ai.Frequency)},
+	}
+	return &f, nil
+}
+
+// Frame decodes the next block of scan data, which arrived from the DAQ at
+// the given time, into a frame that follows on from the previous one. The
+// data must hold whole scans of the enabled channels.
+func (f *Framer) Frame(data []byte, arrived time.Time) (mccdaq.Frame, error) {
+	scanBytes := len(f.channels) * bytesPerWord
+	if len(data)%scanBytes != 0 {
+		return mccdaq.Frame{}, fmt.Errorf("scan data length %d isn't a multiple of %d bytes", len(data), scanBytes)
+	}
+	scans := len(data) / scanBytes
+	frame := mccdaq.Frame{
+		Index:    f.next,
+		Channels: f.channels,
+		Raw:      make([][]uint16, len(f.channels)),
+		Volts:    make([][]float64, len(f.channels)),
+	}
+	for i, ch := range f.channels {
+		channel := f.ai.Channels[ch]
+		raw := make([]uint16, scans)
+		volts := make([]float64, scans)
+		for scan := 0; scan < scans; scan++ {
+			firstByte := (scan*len(f.channels) + i) * bytesPerWord
+			word := data[firstByte : firstByte+bytesPerWord]
+			raw[scan] = binary.LittleEndian.Uint16(word)
+			volts[scan], _ = channel.Volts(word)
+		}
+		frame.Raw[i] = raw
+		frame.Volts[i] = volts
+	}
+	f.next += uint64(scans)
+	f.observeArrival(arrived)
+	frame.Timebase = f.timebase
+	return frame, nil
+}
+
+// observeArrival anchors the timebase when the first block arrives at the
+// given time, and otherwise updates the drift estimate with how late the scans
+// acquired so far arrived compared to their pacer time.
+func (f *Framer) observeArrival(arrived time.Time) {
+	elapsed := time.Duration(f.next) * f.timebase.Period
+	if !f.anchored {
+		f.timebase.Anchor = arrived.Add(-elapsed)
+		f.drift = mccdaq.DriftEstimator{Interval: f.DriftInterval}
+		f.anchored = true
+		return
+	}
+	f.timebase.Drift = f.drift.Observe(elapsed, arrived.Sub(f.timebase.Time(f.next)))
+}
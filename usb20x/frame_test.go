@@ -0,0 +1,141 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package usb20x
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gotmc/mccdaq"
+	"github.com/gotmc/mccdaq/align"
+)
+
+// newFramerTestInput returns an analog input scanning channels 0 and 2 at
+// 1 kHz with unity calibration.
+func newFramerTestInput() *AnalogInput {
+	ai := AnalogInput{Frequency: 1000}
+	for _, ch := range []int{0, 2} {
+		ai.Channels[ch] = Channel{Enabled: true, Range: Range10V, Slope: 1}
+	}
+	return &ai
+}
+
+// encodeScans returns the scan data of the given number of scans of two
+// channels, in which channel 0 reads the scan number and channel 2 reads
+// 0x8000, which is 0 V.
+func encodeScans(first, scans int) []byte {
+	data := make([]byte, 0, scans*2*bytesPerWord)
+	word := make([]byte, bytesPerWord)
+	for scan := first; scan < first+scans; scan++ {
+		binary.LittleEndian.PutUint16(word, uint16(scan))
+		data = append(data, word...)
+		binary.LittleEndian.PutUint16(word, 0x8000)
+		data = append(data, word...)
+	}
+	return data
+}
+
+func TestFramer(t *testing.T) {
+	f, err := newFramerTestInput().NewFramer()
+	if err != nil {
+		t.Fatalf("NewFramer: %v", err)
+	}
+	start := time.Unix(1000, 0)
+	// The first block of 32 scans arrives as its last scan is acquired, and
+	// the second arrives 100 µs late.
+	arrivals := []time.Time{
+		start.Add(32 * time.Millisecond),
+		start.Add(64*time.Millisecond + 100*time.Microsecond),
+	}
+	for i, arrived := range arrivals {
+		frame, err := f.Frame(encodeScans(32*i, 32), arrived)
+		if err != nil {
+			t.Fatalf("Frame: %v", err)
+		}
+		if frame.Index != uint64(32*i) {
+			t.Errorf("Expected index %d, got %d", 32*i, frame.Index)
+		}
+		if !reflect.DeepEqual(frame.Channels, []int{0, 2}) {
+			t.Errorf("Expected channels [0 2], got %v", frame.Channels)
+		}
+		if frame.NumScans() != 32 || frame.Raw[0][0] != uint16(32*i) {
+			t.Errorf("Expected 32 scans starting at %d, got %d starting at %d",
+				32*i, frame.NumScans(), frame.Raw[0][0])
+		}
+		if frame.Volts[1][0] != 0 {
+			t.Errorf("Expected 0 V on channel 2, got %v", frame.Volts[1][0])
+		}
+		if frame.Timebase.Period != time.Millisecond {
+			t.Errorf("Expected 1 ms period, got %v", frame.Timebase.Period)
+		}
+		want := start.Add(time.Duration(32*i) * time.Millisecond)
+		if got := frame.Time(0); !got.Equal(want) {
+			t.Errorf("Expected frame %d to start at %v, got %v", i, want, got)
+		}
+	}
+}
+
+func TestFramerErrors(t *testing.T) {
+	if _, err := (&AnalogInput{}).NewFramer(); err == nil {
+		t.Errorf("Expected error without a frequency")
+	}
+	if _, err := (&AnalogInput{Frequency: 1000}).NewFramer(); !errors.Is(err, mccdaq.ErrInvalidChannel) {
+		t.Errorf("Expected error %v, got %v", mccdaq.ErrInvalidChannel, err)
+	}
+	f, err := newFramerTestInput().NewFramer()
+	if err != nil {
+		t.Fatalf("NewFramer: %v", err)
+	}
+	if _, err := f.Frame(make([]byte, 6), time.Now()); err == nil {
+		t.Errorf("Expected error for a partial scan")
+	}
+}
+
+func TestFramerAlign(t *testing.T) {
+	a, err := align.New(500, 2)
+	if err != nil {
+		t.Fatalf("align.New: %v", err)
+	}
+	start := time.Unix(1000, 0)
+	var framers []*Framer
+	for k := 0; k < 2; k++ {
+		f, err := newFramerTestInput().NewFramer()
+		if err != nil {
+			t.Fatalf("NewFramer: %v", err)
+		}
+		framers = append(framers, f)
+	}
+	for i := 0; i < 4; i++ {
+		for k, f := range framers {
+			arrived := start.Add(time.Duration(32*(i+1)) * time.Millisecond)
+			frame, err := f.Frame(encodeScans(32*i, 32), arrived)
+			if err != nil {
+				t.Fatalf("Frame: %v", err)
+			}
+			if err := a.Push(k, frame); err != nil {
+				t.Fatalf("Push: %v", err)
+			}
+		}
+	}
+	frame, ok := a.Next(16)
+	if !ok {
+		t.Fatalf("Expected an aligned frame")
+	}
+	if !reflect.DeepEqual(frame.Channels, []int{0, 2, 8, 10}) {
+		t.Errorf("Expected channels [0 2 8 10], got %v", frame.Channels)
+	}
+	// Both DAQs read the same scans at the same times, so each aligned scan
+	// matches across the DAQs.
+	for scan := 0; scan < frame.NumScans(); scan++ {
+		if frame.Volts[0][scan] != frame.Volts[2][scan] {
+			t.Errorf("Expected scan %d to match, got %v and %v",
+				scan, frame.Volts[0][scan], frame.Volts[2][scan])
+		}
+	}
+}
@@ -6,11 +6,10 @@
 package usb20x
 
 import (
+	"context"
 	"encoding/binary"
 	"fmt"
 	"math"
-
-	"github.com/gotmc/libusb"
 )
 
 // Gain contains the slope and intercept/offset for a single channel and a
@@ -36,19 +35,33 @@ type GainTable struct {
 // are stored in onboard FLASH memory on the device in IEEE-754 4-byte floating
 // point values.
 func (daq *usb20x) BuildGainTable() (GainTable, error) {
+	return daq.BuildGainTableContext(context.Background())
+}
+
+// BuildGainTableContext builds the gain table like BuildGainTable, but stops
+// reading the calibration memory once the context is done.
+func (daq *usb20x) BuildGainTableContext(ctx context.Context) (GainTable, error) {
 	// TODO(mdr): Why are we reading only 4 bytes at a time in a loop? Why not
 	// read all calibration memory at once and then decode the data as needed to
 	// create the calibraiton gain table.
-	var data []byte
 	address := 0
 	bytesPerValue := 4
 	slope := make([]float64, maxNumADChannels)
 	intercept := make([]float64, maxNumADChannels)
 	for i := 0; i < maxNumADChannels; i++ {
-		data, _ = daq.ReadCalMemory(address, bytesPerValue)
+		if err := ctx.Err(); err != nil {
+			return GainTable{}, err
+		}
+		data, err := daq.ReadCalMemory(address, bytesPerValue)
+		if err != nil {
+			return GainTable{}, fmt.Errorf("Error reading slope: %w", err)
+		}
 		slope[i] = float64(convertBytesToFloat32(data))
 		address += bytesPerValue
-		data, _ = daq.ReadCalMemory(address, bytesPerValue)
+		data, err = daq.ReadCalMemory(address, bytesPerValue)
+		if err != nil {
+			return GainTable{}, fmt.Errorf("Error reading intercept: %w", err)
+		}
 		intercept[i] = float64(convertBytesToFloat32(data))
 		address += bytesPerValue
 	}
@@ -75,17 +88,16 @@ func (daq *usb20x) BuildGainTable() (GainTable, error) {
 */
 func (daq *usb20x) ReadCalMemory(address int, count int) ([]byte, error) {
 	data := make([]byte, count)
-	requestType := libusb.BitmapRequestType(
-		libusb.DeviceToHost, libusb.Vendor, libusb.DeviceRecipient,
-	)
 
 	if !validCalMemoryRange(address, count) {
 		return nil, fmt.Errorf(
 			"Tyring to access outside calibration memory range 0x0000 to 0x02FF")
 	}
 
-	daq.DeviceHandle.ControlTransfer(
-		requestType, byte(commandCalibrationMemory), uint16(address), 0x0, data, count, daq.Timeout)
+	_, err := daq.controlTransferIn(commandCalibrationMemory, uint16(address), 0x0, data)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading calibration memory %w", err)
+	}
 	return data, nil
 }
 
@@ -9,8 +9,6 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
-
-	"github.com/gotmc/libusb"
 )
 
 func byteSlice(i int) []byte {
@@ -22,16 +20,13 @@ func byteSlice(i int) []byte {
 // BlinkLED blinks the LED the given number of times. Note, the LED starts
 // being unlit, but will end being lit.
 func (daq *usb20x) BlinkLED(blinks int) (int, error) {
-	requestType := libusb.BitmapRequestType(
-		libusb.HostToDevice, libusb.Vendor, libusb.DeviceRecipient)
 	// data := byteSlice(blinks)
 	data := make([]byte, 1)
 	data[0] = byte(blinks)
 
-	ret, err := daq.DeviceHandle.ControlTransfer(
-		requestType, byte(commandBlinkLED), 0x0, 0x0, data, len(data), daq.Timeout)
+	ret, err := daq.controlTransferOut(commandBlinkLED, 0x0, 0x0, data)
 	if err != nil {
-		return ret, fmt.Errorf("Error blinking LED %s", err)
+		return ret, fmt.Errorf("Error blinking LED %w", err)
 	}
 	return ret, nil
 }
@@ -39,11 +34,11 @@ func (daq *usb20x) BlinkLED(blinks int) (int, error) {
 // Status retrieves the status of the device and clears the error
 // indicators.
 func (daq *usb20x) Status() (byte, error) {
-	requestType := libusb.BitmapRequestType(
-		libusb.DeviceToHost, libusb.Vendor, libusb.DeviceRecipient)
 	data := make([]byte, 2)
-	daq.DeviceHandle.ControlTransfer(
-		requestType, byte(commandGetStatus), 0x0, 0x0, data, len(data), daq.Timeout)
+	_, err := daq.controlTransferIn(commandGetStatus, 0x0, 0x0, data)
+	if err != nil {
+		return 0, fmt.Errorf("Error reading status %w", err)
+	}
 	status := binary.LittleEndian.Uint16(data)
 	return byte(status), nil
 }
@@ -51,11 +46,11 @@ func (daq *usb20x) Status() (byte, error) {
 // SerialNumber retrieves the serial number via a control transfer using the
 // serial command (0x48) as opposed to using the libusb serial number.
 func (daq *usb20x) SerialNumber() (string, error) {
-	requestType := libusb.BitmapRequestType(
-		libusb.DeviceToHost, libusb.Vendor, libusb.DeviceRecipient)
 	data := make([]byte, 8)
-	daq.DeviceHandle.ControlTransfer(
-		requestType, byte(commandSerialNum), 0x0, 0x0, data, len(data), daq.Timeout)
+	_, err := daq.controlTransferIn(commandSerialNum, 0x0, 0x0, data)
+	if err != nil {
+		return "", fmt.Errorf("Error reading serial number %w", err)
+	}
 	return string(data), nil
 }
 
@@ -64,13 +59,10 @@ func (daq *usb20x) SerialNumber() (string, error) {
 // enumerate in the bootloader and is unusable as a DAQ device until new
 // firmware is loaded.
 func (daq *usb20x) UpgradeFirmware() error {
-	requestType := libusb.BitmapRequestType(
-		libusb.HostToDevice, libusb.Vendor, libusb.DeviceRecipient)
 	key := uint16(0xadad)
-	_, err := daq.DeviceHandle.ControlTransfer(
-		requestType, byte(commandUpgradeFirmware), key, 0x0, []byte{}, 0, daq.Timeout)
+	_, err := daq.controlTransferOut(commandUpgradeFirmware, key, 0x0, []byte{})
 	if err != nil {
-		return fmt.Errorf("Error enabling upgrade firmware mode %s", err)
+		return fmt.Errorf("Error enabling upgrade firmware mode %w", err)
 	}
 	return nil
 }
@@ -0,0 +1,105 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package mccdaq
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Watcher runs a stage of a pipeline of frames, such as a converter's Watch,
+// in a goroutine and records the error that stopped it. The zero value is
+// ready to use. A stage stops once its frames are closed, it fails, or its
+// context is done, so canceling the context releases a stage whose consumer
+// has stopped receiving.
+type Watcher struct {
+	mu  sync.Mutex
+	err error
+}
+
+// Go runs fn in a new goroutine, records the error it returns, and then calls
+// done, such as to close the stage's output channel. An error due to the
+// context being canceled isn't recorded, since canceling is how the caller
+// ends the stage. Use Go for a stage that doesn't read a single channel of
+// frames; otherwise use Watch.
+func (w *Watcher) Go(fn func() error, done func()) {
+	w.mu.Lock()
+	w.err = nil
+	w.mu.Unlock()
+	go func() {
+		defer done()
+		err := fn()
+		if errors.Is(err, context.Canceled) {
+			err = nil
+		}
+		w.mu.Lock()
+		w.err = err
+		w.mu.Unlock()
+	}()
+}
+
+// Watch calls process with each frame received from frames in a new
+// goroutine, until frames is closed, process fails, or the context is done,
+// and then calls done. The process function should give up sending its
+// output once the context is done, as Send does.
+func (w *Watcher) Watch(
+	ctx context.Context, frames <-chan Frame, process func(Frame) error, done func(),
+) {
+	w.Go(func() error {
+		for {
+			select {
+			case frame, ok := <-frames:
+				if !ok {
+					return nil
+				}
+				if err := process(frame); err != nil {
+					return err
+				}
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}, done)
+}
+
+// WatchFrames calls process with each frame received from frames like Watch,
+// and sends the frames that process returns on the returned channel, which is
+// closed once the stage stops.
+func (w *Watcher) WatchFrames(
+	ctx context.Context, frames <-chan Frame, process func(Frame) (Frame, error),
+) <-chan Frame {
+	out := make(chan Frame)
+	w.Watch(ctx, frames, func(frame Frame) error {
+		processed, err := process(frame)
+		if err != nil {
+			return err
+		}
+		return Send(ctx, out, processed)
+	}, func() { close(out) })
+	return out
+}
+
+// Err returns the error that stopped the stage, such as a failed conversion or
+// the context's deadline passing. Err returns nil while the stage is running,
+// and once its output is closed if it stopped because its frames were closed
+// or its context was canceled.
+func (w *Watcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Send sends the frame on out, unless the context is done first, in which
+// case it returns the context's error.
+func Send(ctx context.Context, out chan<- Frame, frame Frame) error {
+	select {
+	case out <- frame:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
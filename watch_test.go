@@ -0,0 +1,104 @@
+// Copyright (c) 2026 The mccdaq developers. All rights reserved.
+// Project site: https://github.com/gotmc/mccdaq
+// Use of this source code is governed by a MIT-style license that
+// can be found in the LICENSE.txt file for the project.
+
+package mccdaq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWatchFrames(t *testing.T) {
+	frames := make(chan Frame)
+	go func() {
+		defer close(frames)
+		for i := uint64(0); i < 3; i++ {
+			frames <- Frame{Index: i}
+		}
+	}()
+	var w Watcher
+	out := w.WatchFrames(context.Background(), frames, func(frame Frame) (Frame, error) {
+		frame.Index *= 10
+		return frame, nil
+	})
+	var got []uint64
+	for frame := range out {
+		got = append(got, frame.Index)
+	}
+	if len(got) != 3 || got[2] != 20 {
+		t.Errorf("Expected frames 0, 10, and 20, got %v", got)
+	}
+	if err := w.Err(); err != nil {
+		t.Errorf("Expected no error once the frames are closed, got %v", err)
+	}
+}
+
+func TestWatchFramesError(t *testing.T) {
+	frames := make(chan Frame, 2)
+	frames <- Frame{Index: 0}
+	frames <- Frame{Index: 1}
+	bad := errors.New("bad frame")
+	var w Watcher
+	out := w.WatchFrames(context.Background(), frames, func(frame Frame) (Frame, error) {
+		if frame.Index == 1 {
+			return frame, bad
+		}
+		return frame, nil
+	})
+	count := 0
+	for range out {
+		count++
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 frame before the error, got %d", count)
+	}
+	if err := w.Err(); err != bad {
+		t.Errorf("Expected error %v, got %v", bad, err)
+	}
+}
+
+func TestWatchCanceled(t *testing.T) {
+	// The consumer stops receiving, and the frames are never closed.
+	frames := make(chan Frame, 1)
+	frames <- Frame{}
+	ctx, cancel := context.WithCancel(context.Background())
+	var w Watcher
+	out := w.WatchFrames(ctx, frames, func(frame Frame) (Frame, error) {
+		return frame, nil
+	})
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case _, ok := <-out:
+		if ok {
+			// The frame may have been sent just before the cancel.
+			_, ok = <-out
+		}
+		if ok {
+			t.Fatalf("Expected the output to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the stage to stop once the context is done")
+	}
+	if err := w.Err(); err != nil {
+		t.Errorf("Expected no error once canceled, got %v", err)
+	}
+}
+
+func TestWatchDeadline(t *testing.T) {
+	frames := make(chan Frame)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var w Watcher
+	for range w.WatchFrames(ctx, frames, func(frame Frame) (Frame, error) {
+		return frame, nil
+	}) {
+	}
+	if err := w.Err(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}